@@ -7,16 +7,16 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/unfunco/t/internal/calendar"
 	"github.com/unfunco/t/internal/list"
 	"github.com/unfunco/t/internal/model"
 	"github.com/unfunco/t/internal/storage"
 )
 
-const day = 24 * time.Hour
-
 // Sync loads all default lists, applies scheduled automations, persists any
-// changes, and returns the resulting lists keyed by their ID.
-func Sync(store storage.Storage, now time.Time) (map[list.ID]*model.TodoList, error) {
+// changes, and returns the resulting lists keyed by their ID. The calendar
+// decides which day now belongs to.
+func Sync(store storage.Storage, cal calendar.Calendar, now time.Time) (map[list.ID]*model.TodoList, error) {
 	defs := list.Default()
 	lists := make(map[list.ID]*model.TodoList, len(defs))
 
@@ -29,18 +29,7 @@ func Sync(store storage.Storage, now time.Time) (map[list.ID]*model.TodoList, er
 		lists[def.ID] = l
 	}
 
-	todayStart := startOfDay(now)
-	changed := ensureDueDates(lists[list.TodayID], list.TodayID)
-
-	if ensureDueDates(lists[list.TomorrowID], list.TomorrowID) {
-		changed = true
-	}
-
-	if moveTomorrowTodos(lists[list.TomorrowID], lists[list.TodayID], todayStart) {
-		changed = true
-	}
-
-	if changed {
+	if Apply(lists, cal, now) {
 		for _, def := range defs {
 			l := lists[def.ID]
 			if l == nil {
@@ -55,7 +44,41 @@ func Sync(store storage.Storage, now time.Time) (map[list.ID]*model.TodoList, er
 	return lists, nil
 }
 
-func ensureDueDates(todoList *model.TodoList, id list.ID) bool {
+// Apply applies scheduled automations to lists in place, such as moving todos
+// due today from the Tomorrow list to the Today list, and reports whether any
+// todos changed. The calendar decides which day now belongs to.
+func Apply(lists map[list.ID]*model.TodoList, cal calendar.Calendar, now time.Time) bool {
+	todayStart := cal.Today(now)
+	changed := ensureUniqueIDs(lists, list.Default())
+
+	if ensureDueDates(cal, lists[list.TodayID], list.TodayID) {
+		changed = true
+	}
+
+	if ensureDueDates(cal, lists[list.TomorrowID], list.TomorrowID) {
+		changed = true
+	}
+
+	if moveTomorrowTodos(cal, lists[list.TomorrowID], lists[list.TodayID], todayStart) {
+		changed = true
+	}
+
+	return changed
+}
+
+// ensureUniqueIDs repairs IDs that are duplicated within or across lists, such
+// as those generated twice by older versions of t or a todo copied between
+// list files by hand, and reports whether any were changed.
+func ensureUniqueIDs(lists map[list.ID]*model.TodoList, defs []list.Definition) bool {
+	ordered := make([]*model.TodoList, 0, len(defs))
+	for _, def := range defs {
+		ordered = append(ordered, lists[def.ID])
+	}
+
+	return storage.EnsureUniqueIDs(ordered...)
+}
+
+func ensureDueDates(cal calendar.Calendar, todoList *model.TodoList, id list.ID) bool {
 	if todoList == nil {
 		return false
 	}
@@ -66,16 +89,16 @@ func ensureDueDates(todoList *model.TodoList, id list.ID) bool {
 
 		switch id {
 		case list.TodayID:
-			if applyDueDate(todo, list.DefaultDueDate(list.TodayID, todo.CreatedAt)) {
+			if applyDueDate(cal, todo, list.DefaultDueDate(cal, list.TodayID, todo.CreatedAt)) {
 				changed = true
 			}
 		case list.TomorrowID:
-			if applyDueDate(todo, list.DefaultDueDate(list.TomorrowID, todo.CreatedAt)) {
+			if applyDueDate(cal, todo, list.DefaultDueDate(cal, list.TomorrowID, todo.CreatedAt)) {
 				changed = true
 			}
 		default:
 			if todo.DueDate != nil {
-				normalized := startOfDay(*todo.DueDate)
+				normalized := cal.Date(*todo.DueDate)
 				if !todo.DueDate.Equal(normalized) {
 					todo.SetDueDate(&normalized)
 					changed = true
@@ -87,7 +110,7 @@ func ensureDueDates(todoList *model.TodoList, id list.ID) bool {
 	return changed
 }
 
-func moveTomorrowTodos(tomorrowList, todayList *model.TodoList, todayStart time.Time) bool {
+func moveTomorrowTodos(cal calendar.Calendar, tomorrowList, todayList *model.TodoList, todayStart time.Time) bool {
 	if tomorrowList == nil || todayList == nil {
 		return false
 	}
@@ -106,7 +129,7 @@ func moveTomorrowTodos(tomorrowList, todayList *model.TodoList, todayStart time.
 			continue
 		}
 
-		due := startOfDay(*todo.DueDate)
+		due := cal.Date(*todo.DueDate)
 		if due.After(todayStart) {
 			remaining = append(remaining, todo)
 			continue
@@ -123,7 +146,7 @@ func moveTomorrowTodos(tomorrowList, todayList *model.TodoList, todayStart time.
 	return changed
 }
 
-func applyDueDate(todo *model.Todo, due *time.Time) bool {
+func applyDueDate(cal calendar.Calendar, todo *model.Todo, due *time.Time) bool {
 	if due == nil {
 		if todo.DueDate == nil {
 			return false
@@ -132,14 +155,14 @@ func applyDueDate(todo *model.Todo, due *time.Time) bool {
 		return true
 	}
 
-	normalizedTarget := startOfDay(*due)
+	normalizedTarget := cal.Date(*due)
 
 	if todo.DueDate == nil {
 		todo.SetDueDate(&normalizedTarget)
 		return true
 	}
 
-	current := startOfDay(*todo.DueDate)
+	current := cal.Date(*todo.DueDate)
 	if current.Equal(normalizedTarget) {
 		return false
 	}
@@ -147,7 +170,3 @@ func applyDueDate(todo *model.Todo, due *time.Time) bool {
 	todo.SetDueDate(&normalizedTarget)
 	return true
 }
-
-func startOfDay(t time.Time) time.Time {
-	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
-}
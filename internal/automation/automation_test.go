@@ -4,18 +4,26 @@
 package automation
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/unfunco/t/internal/calendar"
 	"github.com/unfunco/t/internal/list"
 	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/storage"
 )
 
+const day = 24 * time.Hour
+
+var utc = mustCalendar(0, time.UTC)
+
 func TestSyncMovesDueTomorrowTodos(t *testing.T) {
 	now := time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC)
 	yesterday := now.Add(-day)
 
-	due := list.DefaultDueDate(list.TomorrowID, yesterday)
+	due := list.DefaultDueDate(utc, list.TomorrowID, yesterday)
 	if due == nil {
 		t.Fatalf("expected tomorrow list to have a due date")
 	}
@@ -37,7 +45,7 @@ func TestSyncMovesDueTomorrowTodos(t *testing.T) {
 		},
 	})
 
-	lists, err := Sync(store, now)
+	lists, err := Sync(store, utc, now)
 	if err != nil {
 		t.Fatalf("Sync returned error: %v", err)
 	}
@@ -51,7 +59,7 @@ func TestSyncMovesDueTomorrowTodos(t *testing.T) {
 	}
 
 	todo := lists[list.TodayID].Todos[0]
-	wantDue := list.DefaultDueDate(list.TodayID, now)
+	wantDue := list.DefaultDueDate(utc, list.TodayID, now)
 	if todo.DueDate == nil || wantDue == nil || !todo.DueDate.Equal(*wantDue) {
 		t.Fatalf("expected due date %v, got %v", wantDue, todo.DueDate)
 	}
@@ -60,7 +68,7 @@ func TestSyncMovesDueTomorrowTodos(t *testing.T) {
 func TestSyncLeavesFutureTomorrowTodos(t *testing.T) {
 	now := time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
 
-	due := list.DefaultDueDate(list.TomorrowID, now)
+	due := list.DefaultDueDate(utc, list.TomorrowID, now)
 	store := newMemoryStorage(map[list.ID]*model.TodoList{
 		list.TomorrowID: {
 			Name: list.Tomorrow().Name,
@@ -75,7 +83,7 @@ func TestSyncLeavesFutureTomorrowTodos(t *testing.T) {
 		},
 	})
 
-	lists, err := Sync(store, now)
+	lists, err := Sync(store, utc, now)
 	if err != nil {
 		t.Fatalf("Sync returned error: %v", err)
 	}
@@ -102,7 +110,7 @@ func TestSyncBackfillsMissingDueDates(t *testing.T) {
 		},
 	})
 
-	lists, err := Sync(store, now)
+	lists, err := Sync(store, utc, now)
 	if err != nil {
 		t.Fatalf("Sync returned error: %v", err)
 	}
@@ -112,7 +120,7 @@ func TestSyncBackfillsMissingDueDates(t *testing.T) {
 		t.Fatalf("expected 1 todo after sync, got %d", len(todos))
 	}
 
-	want := list.DefaultDueDate(list.TodayID, created)
+	want := list.DefaultDueDate(utc, list.TodayID, created)
 	if want == nil {
 		t.Fatalf("expected today list to produce a due date")
 	}
@@ -122,6 +130,15 @@ func TestSyncBackfillsMissingDueDates(t *testing.T) {
 	}
 }
 
+func mustCalendar(dayStartsAt time.Duration, location *time.Location) calendar.Calendar {
+	cal, err := calendar.New(dayStartsAt, location)
+	if err != nil {
+		panic(err)
+	}
+
+	return cal
+}
+
 type memoryStorage struct {
 	lists map[list.ID]*model.TodoList
 }
@@ -150,3 +167,122 @@ func (m *memoryStorage) SaveList(def list.Definition, todoList *model.TodoList)
 	m.lists[def.ID] = todoList
 	return nil
 }
+
+func TestSyncHonoursDayStart(t *testing.T) {
+	cal := mustCalendar(4*time.Hour, time.UTC)
+	created := time.Date(2025, time.January, 1, 23, 0, 0, 0, time.UTC)
+
+	newStore := func() *memoryStorage {
+		return newMemoryStorage(map[list.ID]*model.TodoList{
+			list.TomorrowID: {
+				Name: list.Tomorrow().Name,
+				Todos: []model.Todo{
+					{
+						ID:        "1",
+						Title:     "Late night plan",
+						CreatedAt: created,
+						DueDate:   list.DefaultDueDate(cal, list.TomorrowID, created),
+					},
+				},
+			},
+		})
+	}
+
+	beforeStart := time.Date(2025, time.January, 2, 2, 0, 0, 0, time.UTC)
+	lists, err := Sync(newStore(), cal, beforeStart)
+	if err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+
+	if got := len(lists[list.TomorrowID].Todos); got != 1 {
+		t.Fatalf("expected todo to stay in tomorrow before the day starts, got %d", got)
+	}
+
+	afterStart := time.Date(2025, time.January, 2, 5, 0, 0, 0, time.UTC)
+	lists, err = Sync(newStore(), cal, afterStart)
+	if err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+
+	if got := len(lists[list.TodayID].Todos); got != 1 {
+		t.Fatalf("expected todo to move to today once the day starts, got %d", got)
+	}
+}
+
+func TestSyncRepairsIDsDuplicatedAcrossLists(t *testing.T) {
+	now := time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC)
+
+	store := newMemoryStorage(map[list.ID]*model.TodoList{
+		list.TodayID: {
+			Name:  list.Today().Name,
+			Todos: []model.Todo{{ID: "dup", Title: "Original", CreatedAt: now}},
+		},
+		list.TodosID: {
+			Name:  list.Todos().Name,
+			Todos: []model.Todo{{ID: "dup", Title: "Copy", CreatedAt: now}},
+		},
+	})
+
+	lists, err := Sync(store, utc, now)
+	if err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+
+	if got := lists[list.TodayID].Todos[0].ID; got != "dup" {
+		t.Fatalf("expected first occurrence to keep its ID, got %q", got)
+	}
+
+	if got := lists[list.TodosID].Todos[0].ID; got == "dup" || got == "" {
+		t.Fatalf("expected duplicate to be reassigned, got %q", got)
+	}
+}
+
+func TestSyncRepairsDuplicateIDsInListFiles(t *testing.T) {
+	now := time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC)
+	dataDir := t.TempDir()
+
+	content := `[
+  {"id": "20250102090000.000000", "title": "first"},
+  {"id": "20250102090000.000000", "title": "second"},
+  {"id": "", "title": "third"}
+]`
+	if err := os.WriteFile(filepath.Join(dataDir, list.Todos().Filename), []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write list file: %v", err)
+	}
+
+	store, err := storage.NewFileStorageWithDir(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create file storage: %v", err)
+	}
+
+	lists, err := Sync(store, utc, now)
+	if err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+
+	repaired := lists[list.TodosID].Todos
+	if got := repaired[0].ID; got != "20250102090000.000000" {
+		t.Fatalf("expected first todo to keep its legacy ID, got %q", got)
+	}
+
+	ids := map[string]struct{}{}
+	for _, todo := range repaired {
+		if todo.ID == "" {
+			t.Fatalf("expected todo %q to be assigned an ID", todo.Title)
+		}
+		ids[todo.ID] = struct{}{}
+	}
+	if len(ids) != len(repaired) {
+		t.Fatalf("expected unique IDs, got %+v", repaired)
+	}
+
+	reloaded, err := store.LoadList(list.Todos())
+	if err != nil {
+		t.Fatalf("LoadList returned error: %v", err)
+	}
+	for i := range repaired {
+		if reloaded.Todos[i].ID != repaired[i].ID {
+			t.Fatalf("expected repaired IDs to be saved, got %q want %q", reloaded.Todos[i].ID, repaired[i].ID)
+		}
+	}
+}
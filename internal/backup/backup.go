@@ -0,0 +1,311 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+// Package backup keeps rolling snapshots of the list files in the data
+// directory so that a bad save can be rolled back.
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Dir is the name of the subdirectory of the data directory holding snapshots.
+const Dir = "backups"
+
+// nameLayout formats snapshot names so that they sort chronologically.
+const nameLayout = "20060102T150405Z"
+
+// ErrSnapshotNotFound is returned when restoring a snapshot that does not
+// exist.
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// Mode determines how often snapshots are taken.
+type Mode string
+
+const (
+	// ModeOff disables automatic snapshots.
+	ModeOff Mode = "off"
+	// ModeSave takes a snapshot before every operation that saves changes.
+	ModeSave Mode = "save"
+	// ModeDaily takes at most one snapshot per calendar day.
+	ModeDaily Mode = "daily"
+)
+
+// DefaultRetain is the number of snapshots kept when none is configured.
+const DefaultRetain = 7
+
+// Config represents the raw backup configuration values.
+type Config struct {
+	Mode   Mode `json:"mode"`
+	Retain int  `json:"retain"`
+}
+
+// DefaultConfig returns the built-in backup configuration.
+func DefaultConfig() Config {
+	return Config{
+		Mode:   ModeDaily,
+		Retain: DefaultRetain,
+	}
+}
+
+// Validate reports whether the configuration can be used.
+func (cfg Config) Validate() error {
+	switch cfg.Mode {
+	case "", ModeOff, ModeSave, ModeDaily:
+	default:
+		return fmt.Errorf("unknown backup mode %q", cfg.Mode)
+	}
+
+	if cfg.Retain < 0 {
+		return fmt.Errorf("backup retain must not be negative, got %d", cfg.Retain)
+	}
+
+	return nil
+}
+
+func (cfg Config) withDefaults() Config {
+	def := DefaultConfig()
+
+	if cfg.Mode == "" {
+		cfg.Mode = def.Mode
+	}
+	if cfg.Retain == 0 {
+		cfg.Retain = def.Retain
+	}
+
+	return cfg
+}
+
+// Snapshot describes a single backup of the data directory.
+type Snapshot struct {
+	Name      string
+	CreatedAt time.Time
+	Files     []string
+}
+
+// Create copies every list file in dataDir into a new snapshot.
+func Create(dataDir string, now time.Time) (Snapshot, error) {
+	files, err := listFiles(dataDir)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	root := filepath.Join(dataDir, Dir)
+	if err := os.MkdirAll(root, 0o700); err != nil {
+		return Snapshot{}, fmt.Errorf("create backup directory: %w", err)
+	}
+
+	base := now.UTC().Format(nameLayout)
+	name := base
+	for i := 2; ; i++ {
+		err := os.Mkdir(filepath.Join(root, name), 0o700)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return Snapshot{}, fmt.Errorf("create snapshot %s: %w", name, err)
+		}
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	dir := filepath.Join(root, name)
+	for _, file := range files {
+		if err := copyFile(filepath.Join(dataDir, file), filepath.Join(dir, file)); err != nil {
+			_ = os.RemoveAll(dir)
+			return Snapshot{}, fmt.Errorf("snapshot %s: %w", file, err)
+		}
+	}
+
+	return Snapshot{
+		Name:      name,
+		CreatedAt: now.UTC().Truncate(time.Second),
+		Files:     files,
+	}, nil
+}
+
+// List returns the snapshots in dataDir, oldest first.
+func List(dataDir string) ([]Snapshot, error) {
+	root := filepath.Join(dataDir, Dir)
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read backup directory: %w", err)
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		createdAt, ok := parseName(entry.Name())
+		if !ok {
+			continue
+		}
+
+		files, err := listFiles(filepath.Join(root, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		snapshots = append(snapshots, Snapshot{
+			Name:      entry.Name(),
+			CreatedAt: createdAt,
+			Files:     files,
+		})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Name < snapshots[j].Name
+	})
+
+	return snapshots, nil
+}
+
+// Restore replaces the list files in dataDir with those in the named snapshot.
+// The current files are snapshotted first so that the restore can be undone.
+func Restore(dataDir, name string, now time.Time) (Snapshot, error) {
+	src := filepath.Join(dataDir, Dir, name)
+	if _, ok := parseName(name); !ok || name != filepath.Base(name) {
+		return Snapshot{}, fmt.Errorf("%w: %s", ErrSnapshotNotFound, name)
+	}
+
+	if info, err := os.Stat(src); err != nil || !info.IsDir() {
+		return Snapshot{}, fmt.Errorf("%w: %s", ErrSnapshotNotFound, name)
+	}
+
+	files, err := listFiles(src)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	safety, err := Create(dataDir, now)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("snapshot current data: %w", err)
+	}
+
+	current, err := listFiles(dataDir)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	for _, file := range current {
+		if err := os.Remove(filepath.Join(dataDir, file)); err != nil {
+			return Snapshot{}, fmt.Errorf("remove %s: %w", file, err)
+		}
+	}
+
+	for _, file := range files {
+		if err := copyFile(filepath.Join(src, file), filepath.Join(dataDir, file)); err != nil {
+			return Snapshot{}, fmt.Errorf("restore %s: %w", file, err)
+		}
+	}
+
+	return safety, nil
+}
+
+// Prune removes the oldest snapshots so that at most retain remain.
+func Prune(dataDir string, retain int) error {
+	if retain <= 0 {
+		return nil
+	}
+
+	snapshots, err := List(dataDir)
+	if err != nil {
+		return err
+	}
+
+	for len(snapshots) > retain {
+		if err := os.RemoveAll(filepath.Join(dataDir, Dir, snapshots[0].Name)); err != nil {
+			return fmt.Errorf("remove snapshot %s: %w", snapshots[0].Name, err)
+		}
+		snapshots = snapshots[1:]
+	}
+
+	return nil
+}
+
+// listFiles returns the names of the list files directly inside dir.
+func listFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", dir, err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		if strings.Contains(entry.Name(), ".tmp-") {
+			continue
+		}
+		files = append(files, entry.Name())
+	}
+
+	return files, nil
+}
+
+func parseName(name string) (time.Time, bool) {
+	base, _, _ := strings.Cut(name, "-")
+	t, err := time.Parse(nameLayout, base)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// copyFile copies src to dst via a temporary file so that dst is replaced
+// atomically.
+func copyFile(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if err = tmp.Chmod(0o600); err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(tmp, in); err != nil {
+		return err
+	}
+
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), dst)
+}
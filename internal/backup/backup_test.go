@@ -0,0 +1,225 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package backup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/unfunco/t/internal/calendar"
+	"github.com/unfunco/t/internal/clock"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/storage"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+}
+
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", name, err)
+	}
+
+	return string(data)
+}
+
+func TestCreateListAndRestore(t *testing.T) {
+	dataDir := t.TempDir()
+	now := time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC)
+
+	writeFile(t, dataDir, "today.json", "original")
+
+	snapshot, err := Create(dataDir, now)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if snapshot.Name != "20250102T090000Z" {
+		t.Fatalf("unexpected snapshot name %q", snapshot.Name)
+	}
+
+	writeFile(t, dataDir, "today.json", "broken")
+	writeFile(t, dataDir, "tomorrow.json", "new file")
+
+	snapshots, err := List(dataDir)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(snapshots) != 1 || snapshots[0].Name != snapshot.Name {
+		t.Fatalf("unexpected snapshots: %+v", snapshots)
+	}
+
+	safety, err := Restore(dataDir, snapshot.Name, now)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	if got := readFile(t, dataDir, "today.json"); got != "original" {
+		t.Fatalf("expected restored contents, got %q", got)
+	}
+
+	if _, err := os.Stat(filepath.Join(dataDir, "tomorrow.json")); !os.IsNotExist(err) {
+		t.Fatalf("expected files absent from the snapshot to be removed, got %v", err)
+	}
+
+	if safety.Name == snapshot.Name {
+		t.Fatalf("expected safety snapshot to have a distinct name")
+	}
+
+	if got := readFile(t, filepath.Join(dataDir, Dir, safety.Name), "today.json"); got != "broken" {
+		t.Fatalf("expected safety snapshot to hold the replaced data, got %q", got)
+	}
+}
+
+func TestRestoreUnknownSnapshot(t *testing.T) {
+	dataDir := t.TempDir()
+
+	for _, name := range []string{"20250102T090000Z", "../etc", "nonsense"} {
+		_, err := Restore(dataDir, name, time.Now())
+		if !errors.Is(err, ErrSnapshotNotFound) {
+			t.Fatalf("Restore(%q) expected ErrSnapshotNotFound, got %v", name, err)
+		}
+	}
+}
+
+func TestPruneKeepsNewest(t *testing.T) {
+	dataDir := t.TempDir()
+	writeFile(t, dataDir, "today.json", "data")
+
+	start := time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		if _, err := Create(dataDir, start.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	if err := Prune(dataDir, 2); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+
+	snapshots, err := List(dataDir)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(snapshots) != 2 {
+		t.Fatalf("expected 2 snapshots, got %d", len(snapshots))
+	}
+
+	if snapshots[0].Name != "20250101T120000Z" || snapshots[1].Name != "20250101T130000Z" {
+		t.Fatalf("expected newest snapshots to be kept, got %+v", snapshots)
+	}
+}
+
+func TestStorageDailyModeSnapshotsOncePerDay(t *testing.T) {
+	dataDir := t.TempDir()
+	file, err := storage.NewFileStorageWithDir(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create file storage: %v", err)
+	}
+
+	cal, err := calendar.New(0, time.UTC)
+	if err != nil {
+		t.Fatalf("calendar.New() error = %v", err)
+	}
+
+	todos := &model.TodoList{Todos: []model.Todo{{ID: "1", Title: "first"}}}
+	if err := file.SaveList(list.Today(), todos); err != nil {
+		t.Fatalf("SaveList() error = %v", err)
+	}
+
+	save := func(now time.Time) {
+		t.Helper()
+		s := NewStorage(file, dataDir, Config{Mode: ModeDaily}, cal, clock.Fixed(now))
+		for range 2 {
+			if err := s.SaveList(list.Today(), todos); err != nil {
+				t.Fatalf("SaveList() error = %v", err)
+			}
+		}
+	}
+
+	morning := time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC)
+	save(morning)
+	save(morning.Add(2 * time.Hour))
+	save(morning.Add(24 * time.Hour))
+
+	snapshots, err := List(dataDir)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(snapshots) != 2 {
+		t.Fatalf("expected one snapshot per day, got %+v", snapshots)
+	}
+}
+
+func TestStorageSaveModeSnapshotsEachOperation(t *testing.T) {
+	dataDir := t.TempDir()
+	file, err := storage.NewFileStorageWithDir(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create file storage: %v", err)
+	}
+
+	todos := &model.TodoList{Todos: []model.Todo{{ID: "1", Title: "first"}}}
+	if err := file.SaveList(list.Today(), todos); err != nil {
+		t.Fatalf("SaveList() error = %v", err)
+	}
+
+	now := time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC)
+	s := NewStorage(file, dataDir, Config{Mode: ModeSave}, calendar.Calendar{}, clock.Fixed(now))
+	for range 2 {
+		s.BeginOperation()
+		for _, def := range []list.Definition{list.Today(), list.Todos()} {
+			if err := s.SaveList(def, todos); err != nil {
+				t.Fatalf("SaveList() error = %v", err)
+			}
+		}
+	}
+
+	snapshots, err := List(dataDir)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(snapshots) != 2 {
+		t.Fatalf("expected one snapshot per operation, got %+v", snapshots)
+	}
+}
+
+func TestStorageOffModeSkipsSnapshots(t *testing.T) {
+	dataDir := t.TempDir()
+	writeFile(t, dataDir, "today.json", "[]")
+
+	file, err := storage.NewFileStorageWithDir(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create file storage: %v", err)
+	}
+
+	s := NewStorage(file, dataDir, Config{Mode: ModeOff}, calendar.Calendar{}, clock.System())
+	if err := s.SaveList(list.Today(), &model.TodoList{}); err != nil {
+		t.Fatalf("SaveList() error = %v", err)
+	}
+
+	snapshots, err := List(dataDir)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(snapshots) != 0 {
+		t.Fatalf("expected no snapshots, got %+v", snapshots)
+	}
+}
@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package backup
+
+import (
+	"fmt"
+
+	"github.com/unfunco/t/internal/calendar"
+	"github.com/unfunco/t/internal/clock"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/storage"
+)
+
+// Storage wraps another storage backend and snapshots the data directory
+// before the first save of each operation made through it, according to the
+// configured mode.
+type Storage struct {
+	inner    storage.Storage
+	dataDir  string
+	cfg      Config
+	calendar calendar.Calendar
+	clock    clock.Clock
+	checked  bool
+}
+
+var _ storage.Storage = (*Storage)(nil)
+
+// NewStorage wraps inner so that saves are preceded by a snapshot of dataDir.
+func NewStorage(
+	inner storage.Storage,
+	dataDir string,
+	cfg Config,
+	cal calendar.Calendar,
+	clk clock.Clock,
+) *Storage {
+	return &Storage{
+		inner:    inner,
+		dataDir:  dataDir,
+		cfg:      cfg.withDefaults(),
+		calendar: cal,
+		clock:    clk,
+	}
+}
+
+// BeginOperation marks the start of an operation, such as the lists saved for
+// a single change, so that a snapshot is taken before its first save if one
+// is due.
+func (s *Storage) BeginOperation() {
+	s.checked = false
+}
+
+// LoadList implements storage.Storage.
+func (s *Storage) LoadList(def list.Definition) (*model.TodoList, error) {
+	return s.inner.LoadList(def)
+}
+
+// SaveList implements storage.Storage, taking a snapshot first if one is due.
+func (s *Storage) SaveList(def list.Definition, todoList *model.TodoList) error {
+	if err := s.snapshotIfDue(); err != nil {
+		return err
+	}
+
+	return s.inner.SaveList(def, todoList)
+}
+
+// snapshotIfDue takes at most one snapshot per operation, so an operation
+// that saves several lists produces a single consistent snapshot.
+func (s *Storage) snapshotIfDue() error {
+	if s.checked {
+		return nil
+	}
+	s.checked = true
+
+	now := s.clock.Now()
+
+	switch s.cfg.Mode {
+	case ModeSave:
+	case ModeDaily:
+		snapshots, err := List(s.dataDir)
+		if err != nil {
+			return fmt.Errorf("list backups: %w", err)
+		}
+
+		if len(snapshots) > 0 {
+			latest := snapshots[len(snapshots)-1]
+			if !s.calendar.Today(latest.CreatedAt).Before(s.calendar.Today(now)) {
+				return nil
+			}
+		}
+	default:
+		return nil
+	}
+
+	files, err := listFiles(s.dataDir)
+	if err != nil {
+		return fmt.Errorf("list data files: %w", err)
+	}
+
+	// Nothing has been saved yet, so there is nothing worth keeping.
+	if len(files) == 0 {
+		return nil
+	}
+
+	if _, err := Create(s.dataDir, now); err != nil {
+		return fmt.Errorf("create backup: %w", err)
+	}
+
+	if err := Prune(s.dataDir, s.cfg.Retain); err != nil {
+		return fmt.Errorf("prune backups: %w", err)
+	}
+
+	return nil
+}
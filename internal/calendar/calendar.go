@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+// Package calendar maps instants onto the calendar days used to schedule
+// todos, honouring a configurable start-of-day time and time zone.
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Calendar determines which calendar day an instant belongs to. The zero value
+// is ready to use and starts each day at midnight in the local time zone.
+type Calendar struct {
+	dayStartsAt time.Duration
+	location    *time.Location
+}
+
+// New returns a calendar whose days begin at the provided offset from midnight
+// in the given location. A nil location uses the local time zone.
+func New(dayStartsAt time.Duration, location *time.Location) (Calendar, error) {
+	if dayStartsAt < 0 || dayStartsAt >= 24*time.Hour {
+		return Calendar{}, fmt.Errorf("day start %s must be between 00:00 and 23:59", dayStartsAt)
+	}
+
+	return Calendar{
+		dayStartsAt: dayStartsAt.Truncate(time.Minute),
+		location:    location,
+	}, nil
+}
+
+// FromConfig returns a calendar built from raw configuration values. The day
+// start is written as HH:MM and the time zone as an IANA name such as
+// "Europe/London"; empty values fall back to midnight and the local time zone.
+func FromConfig(dayStartsAt, timeZone string) (Calendar, error) {
+	start, err := parseClock(dayStartsAt)
+	if err != nil {
+		return Calendar{}, err
+	}
+
+	var location *time.Location
+	if tz := strings.TrimSpace(timeZone); tz != "" {
+		location, err = time.LoadLocation(tz)
+		if err != nil {
+			return Calendar{}, fmt.Errorf("unknown time zone %q: %w", tz, err)
+		}
+	}
+
+	return New(start, location)
+}
+
+// Location returns the time zone used to interpret calendar days.
+func (c Calendar) Location() *time.Location {
+	if c.location == nil {
+		return time.Local
+	}
+
+	return c.location
+}
+
+// DayStartsAt returns the offset from midnight at which each day begins.
+func (c Calendar) DayStartsAt() time.Duration {
+	return c.dayStartsAt
+}
+
+// Today returns midnight of the calendar day that the provided instant falls
+// in. Instants before the configured start of day belong to the previous day.
+func (c Calendar) Today(now time.Time) time.Time {
+	local := now.In(c.Location())
+	date := c.Date(local)
+
+	sinceMidnight := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+	if sinceMidnight < c.dayStartsAt {
+		return c.AddDays(date, -1)
+	}
+
+	return date
+}
+
+// NextDayStart returns the instant the calendar day after the one now falls in
+// begins, which is when todos due tomorrow become due today.
+func (c Calendar) NextDayStart(now time.Time) time.Time {
+	next := c.AddDays(c.Today(now), 1)
+	hours := int(c.dayStartsAt / time.Hour)
+	minutes := int(c.dayStartsAt % time.Hour / time.Minute)
+
+	return time.Date(next.Year(), next.Month(), next.Day(), hours, minutes, 0, 0, c.Location())
+}
+
+// Date normalises a stored date to midnight in the calendar's time zone. The
+// wall-clock date of the value is preserved, so due dates written in another
+// zone keep the day they were given.
+func (c Calendar) Date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.Location())
+}
+
+// AddDays moves a date by the given number of calendar days. Unlike adding
+// multiples of 24 hours, the result stays on midnight across DST transitions.
+func (c Calendar) AddDays(date time.Time, days int) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day()+days, 0, 0, 0, 0, c.Location())
+}
+
+func parseClock(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid day start %q, expected HH:MM", value)
+	}
+
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
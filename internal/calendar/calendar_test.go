@@ -0,0 +1,241 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s unavailable: %v", name, err)
+	}
+
+	return loc
+}
+
+func TestTodayBeforeDayStartBelongsToPreviousDay(t *testing.T) {
+	cal, err := New(4*time.Hour, time.UTC)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{
+			name: "after midnight",
+			now:  time.Date(2025, time.March, 2, 2, 0, 0, 0, time.UTC),
+			want: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "at day start",
+			now:  time.Date(2025, time.March, 2, 4, 0, 0, 0, time.UTC),
+			want: time.Date(2025, time.March, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "late evening",
+			now:  time.Date(2025, time.March, 2, 23, 59, 0, 0, time.UTC),
+			want: time.Date(2025, time.March, 2, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cal.Today(tt.now); !got.Equal(tt.want) {
+				t.Fatalf("Today(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTodayUsesConfiguredTimeZone(t *testing.T) {
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+
+	cal, err := New(0, tokyo)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// 20:00 UTC on 1 March is already 2 March in Tokyo.
+	now := time.Date(2025, time.March, 1, 20, 0, 0, 0, time.UTC)
+	want := time.Date(2025, time.March, 2, 0, 0, 0, 0, tokyo)
+
+	if got := cal.Today(now); !got.Equal(want) {
+		t.Fatalf("Today() = %v, want %v", got, want)
+	}
+}
+
+func TestAddDaysAcrossDSTTransitions(t *testing.T) {
+	london := mustLoadLocation(t, "Europe/London")
+
+	cal, err := New(0, london)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		from time.Time
+		want time.Time
+	}{
+		{
+			name: "clocks go forward",
+			from: time.Date(2025, time.March, 30, 0, 0, 0, 0, london),
+			want: time.Date(2025, time.March, 31, 0, 0, 0, 0, london),
+		},
+		{
+			name: "clocks go back",
+			from: time.Date(2025, time.October, 26, 0, 0, 0, 0, london),
+			want: time.Date(2025, time.October, 27, 0, 0, 0, 0, london),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cal.AddDays(tt.from, 1)
+			if !got.Equal(tt.want) {
+				t.Fatalf("AddDays() = %v, want %v", got, tt.want)
+			}
+
+			if got.Hour() != 0 {
+				t.Fatalf("AddDays() drifted off midnight: %v", got)
+			}
+		})
+	}
+}
+
+func TestTodayDuringDSTTransitions(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	cal, err := New(3*time.Hour, newYork)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{
+			// 02:00-03:00 does not exist on 9 March 2025, so 06:59 UTC is
+			// 01:59 EST and still belongs to 8 March.
+			name: "before skipped hour",
+			now:  time.Date(2025, time.March, 9, 6, 59, 0, 0, time.UTC),
+			want: time.Date(2025, time.March, 8, 0, 0, 0, 0, newYork),
+		},
+		{
+			// 07:00 UTC is 03:00 EDT, the moment the new day starts.
+			name: "after skipped hour",
+			now:  time.Date(2025, time.March, 9, 7, 0, 0, 0, time.UTC),
+			want: time.Date(2025, time.March, 9, 0, 0, 0, 0, newYork),
+		},
+		{
+			// 01:30 happens twice on 2 November 2025; both belong to 1 November.
+			name: "repeated hour, first pass",
+			now:  time.Date(2025, time.November, 2, 5, 30, 0, 0, time.UTC),
+			want: time.Date(2025, time.November, 1, 0, 0, 0, 0, newYork),
+		},
+		{
+			name: "repeated hour, second pass",
+			now:  time.Date(2025, time.November, 2, 6, 30, 0, 0, time.UTC),
+			want: time.Date(2025, time.November, 1, 0, 0, 0, 0, newYork),
+		},
+		{
+			// 08:00 UTC is 03:00 EST after the clocks went back.
+			name: "after repeated hour",
+			now:  time.Date(2025, time.November, 2, 8, 0, 0, 0, time.UTC),
+			want: time.Date(2025, time.November, 2, 0, 0, 0, 0, newYork),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cal.Today(tt.now); !got.Equal(tt.want) {
+				t.Fatalf("Today(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextDayStart(t *testing.T) {
+	cal, err := New(4*time.Hour+30*time.Minute, time.UTC)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{
+			name: "evening",
+			now:  time.Date(2025, time.March, 10, 22, 0, 0, 0, time.UTC),
+			want: time.Date(2025, time.March, 11, 4, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "before day start",
+			now:  time.Date(2025, time.March, 11, 3, 0, 0, 0, time.UTC),
+			want: time.Date(2025, time.March, 11, 4, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "at day start",
+			now:  time.Date(2025, time.March, 11, 4, 30, 0, 0, time.UTC),
+			want: time.Date(2025, time.March, 12, 4, 30, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cal.NextDayStart(tt.now); !got.Equal(tt.want) {
+				t.Fatalf("NextDayStart(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDatePreservesWallClockDate(t *testing.T) {
+	cal, err := New(0, time.UTC)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	due := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.FixedZone("BST", 60*60))
+	want := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	if got := cal.Date(due); !got.Equal(want) {
+		t.Fatalf("Date() = %v, want %v", got, want)
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	cal, err := FromConfig("04:30", "UTC")
+	if err != nil {
+		t.Fatalf("FromConfig() error = %v", err)
+	}
+
+	if got, want := cal.DayStartsAt(), 4*time.Hour+30*time.Minute; got != want {
+		t.Fatalf("DayStartsAt() = %v, want %v", got, want)
+	}
+
+	if got := cal.Location(); got != time.UTC {
+		t.Fatalf("Location() = %v, want UTC", got)
+	}
+
+	if _, err := FromConfig("25:00", ""); err == nil {
+		t.Fatalf("expected error for invalid day start")
+	}
+
+	if _, err := FromConfig("", "Not/AZone"); err == nil {
+		t.Fatalf("expected error for unknown time zone")
+	}
+}
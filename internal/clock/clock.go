@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+// Package clock abstracts the current time so that the application can be
+// driven at a fixed instant for demos, tests and debugging.
+package clock
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EnvVar names the environment variable that pins the clock to an instant.
+const EnvVar = "T_NOW"
+
+// layouts lists the accepted formats for fixed instants, most precise first.
+var layouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// Clock reports the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// Func adapts an ordinary function to the Clock interface.
+type Func func() time.Time
+
+// Now implements Clock.
+func (f Func) Now() time.Time {
+	return f()
+}
+
+// System returns a clock backed by the system time.
+func System() Clock {
+	return Func(time.Now)
+}
+
+// Fixed returns a clock that always reports the provided instant.
+func Fixed(t time.Time) Clock {
+	return Func(func() time.Time {
+		return t
+	})
+}
+
+// Parse parses a fixed instant written as RFC 3339 or as a local date with an
+// optional time, such as "2025-01-02" or "2025-01-02T09:30".
+func Parse(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid instant %q, expected RFC 3339 or YYYY-MM-DD[THH:MM[:SS]]", value)
+}
@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{
+			value: "2025-01-02T09:30:00Z",
+			want:  time.Date(2025, time.January, 2, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			value: "2025-01-02T09:30",
+			want:  time.Date(2025, time.January, 2, 9, 30, 0, 0, time.Local),
+		},
+		{
+			value: "2025-01-02",
+			want:  time.Date(2025, time.January, 2, 0, 0, 0, 0, time.Local),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Parse(tt.value)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if !got.Equal(tt.want) {
+				t.Fatalf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Parse("tomorrow-ish"); err == nil {
+		t.Fatalf("expected error for invalid instant")
+	}
+}
+
+func TestFixed(t *testing.T) {
+	instant := time.Date(2025, time.January, 2, 9, 30, 0, 0, time.UTC)
+	c := Fixed(instant)
+
+	if got := c.Now(); !got.Equal(instant) {
+		t.Fatalf("Now() = %v, want %v", got, instant)
+	}
+}
@@ -0,0 +1,312 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+	"github.com/unfunco/t/internal/interchange"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+)
+
+// ErrNothingToAdd is returned when t add is given neither a title nor a source
+// of titles.
+var ErrNothingToAdd = errors.New("a title, - or --file is required")
+
+func newAddCommand(a *app) *cobra.Command {
+	var (
+		flags addFlags
+		file  string
+	)
+
+	c := &cobra.Command{
+		Use:   "add [title|-] [--flags]",
+		Short: "Add one todo, or many from a file or standard input.",
+		Long: heredoc.Doc(`
+			Add a todo with the given title or, with "-" or --file, add one todo
+			for each line of standard input or the file. Blank lines and lines
+			starting with # are ignored.
+
+			Each line may include @tag and +project words, and a due date as
+			due:today, due:tomorrow or due:YYYY-MM-DD. Todos with a due date are
+			added to the list for that day, and other todos to the list and due
+			date chosen by the flags.
+
+			Every todo is validated before any is saved, and lines that cannot
+			be added are reported.
+		`),
+		Example: heredoc.Doc(`
+			t add "Do something" --description "With some detail"
+			t add "Do something" --list todos --due 2025-01-31
+			printf 'Buy milk @shop\nCall Mum due:tomorrow\n' | t add -
+			t add --file list.txt --today
+		`),
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := a.interchangeOptions()
+
+			def, due, err := flags.target(opts)
+			if err != nil {
+				return err
+			}
+
+			var r io.Reader
+			switch {
+			case file != "" && len(args) > 0:
+				return errors.New("cannot use --file with a title")
+			case flags.description != "" && (file != "" || (len(args) > 0 && args[0] == "-")):
+				return errors.New("cannot use --description when adding todos from - or --file")
+			case file != "":
+				in, closeInput, err := openInput(cmd, file)
+				if err != nil {
+					return err
+				}
+				defer closeInput()
+				r = in
+			case len(args) == 0:
+				return ErrNothingToAdd
+			case args[0] == "-":
+				r = cmd.InOrStdin()
+			default:
+				todo, err := a.addTitle(args[0], flags.description, def, due)
+				if err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Added to %s: %s (%s)\n", def.Name, todo.Title, todo.ID)
+				return nil
+			}
+
+			items, failures, err := parseBatch(r, def, due, opts)
+			if err != nil {
+				return err
+			}
+
+			store, err := a.openStorage()
+			if err != nil {
+				return fmt.Errorf("failed to initialise storage: %w", err)
+			}
+
+			total := len(items) + len(failures)
+
+			added, rejected, err := addItems(a, store, items)
+			if err != nil {
+				return err
+			}
+			failures = append(failures, rejected...)
+
+			for _, failure := range failures {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "skipped: %s\n", failure)
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Added %d of %d todos\n", added, total)
+
+			if len(failures) > 0 {
+				return fmt.Errorf("%d todos could not be added", len(failures))
+			}
+
+			return nil
+		},
+	}
+
+	flags.register(c)
+	c.Flags().StringVar(&file, "file", "", "Add one todo for each line of a file")
+
+	return c
+}
+
+// addFlags holds the flags that describe the todos being added.
+type addFlags struct {
+	today       bool
+	tomorrow    bool
+	listID      string
+	due         string
+	description string
+}
+
+// register adds the flags to c. Only one list may be chosen, and --due cannot
+// be combined with --today or --tomorrow, which imply a due date of their own.
+func (f *addFlags) register(c *cobra.Command) {
+	c.Flags().BoolVar(&f.today, "today", false, "Add the todo to the Today list")
+	c.Flags().BoolVar(&f.tomorrow, "tomorrow", false, "Add the todo to the Tomorrow list")
+	c.Flags().StringVar(&f.listID, "list", "", "Add the todo to the list with this ID ("+strings.Join(listIDs(), ", ")+")")
+	c.Flags().StringVar(&f.due, "due", "", "Due date (today, tomorrow or YYYY-MM-DD)")
+	c.Flags().StringVarP(&f.description, "description", "d", "", "Description of the todo")
+
+	_ = c.RegisterFlagCompletionFunc("list", completeListIDs)
+	_ = c.RegisterFlagCompletionFunc("due", cobra.FixedCompletions([]cobra.Completion{"today", "tomorrow"}, cobra.ShellCompDirectiveNoFileComp))
+
+	c.MarkFlagsMutuallyExclusive("today", "tomorrow", "list")
+	c.MarkFlagsMutuallyExclusive("today", "tomorrow", "due")
+}
+
+// target returns the list the todo is added to and its due date. Without a
+// list flag, todos with a due date are added to the list for that day. Without
+// a due date, todos are due when the list implies.
+func (f *addFlags) target(opts interchange.Options) (list.Definition, *time.Time, error) {
+	var due *time.Time
+	if f.due != "" {
+		d, err := parseDue(f.due, opts)
+		if err != nil {
+			return list.Definition{}, nil, err
+		}
+		due = d
+	}
+
+	var def list.Definition
+	switch {
+	case f.listID != "":
+		d, ok := list.Lookup(list.ID(f.listID))
+		if !ok {
+			return list.Definition{}, nil, fmt.Errorf("unknown list %q, expected one of %s", f.listID, strings.Join(listIDs(), ", "))
+		}
+		def = d
+	case f.today:
+		def = list.Today()
+	case f.tomorrow:
+		def = list.Tomorrow()
+	case due != nil:
+		def, _ = list.Lookup(interchange.ListForDueDate(opts.Calendar, due, opts.Now))
+	default:
+		def = list.Todos()
+	}
+
+	if due == nil {
+		due = list.DefaultDueDate(opts.Calendar, def.ID, opts.Now)
+	}
+
+	return def, due, nil
+}
+
+// listIDs returns the IDs of the default lists.
+func listIDs() []string {
+	ids := make([]string, 0, len(list.Default()))
+	for _, def := range list.Default() {
+		ids = append(ids, string(def.ID))
+	}
+
+	return ids
+}
+
+// addTitle adds a single todo to a list and returns it.
+func (a *app) addTitle(title, description string, def list.Definition, due *time.Time) (model.Todo, error) {
+	title = strings.TrimSpace(title)
+	if err := model.ValidateTitle(title); err != nil {
+		return model.Todo{}, err
+	}
+
+	store, err := a.openStorage()
+	if err != nil {
+		return model.Todo{}, fmt.Errorf("failed to initialise storage: %w", err)
+	}
+
+	if _, err := a.syncLists(store); err != nil {
+		return model.Todo{}, err
+	}
+
+	todo := model.NewTodo(title, strings.TrimSpace(description), due, a.now())
+
+	return todo, appendToList(store, def, &todo)
+}
+
+// parseBatch reads one todo per line. Todos without a due date are added to
+// def and given the due date due. It returns the todos along with a
+// description of every line that could not be read.
+func parseBatch(r io.Reader, def list.Definition, due *time.Time, opts interchange.Options) ([]interchange.Item, []string, error) {
+	var (
+		items    []interchange.Item
+		failures []string
+	)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		item, err := parseBatchLine(text, def, due, opts)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+
+		item.Line = line
+		items = append(items, item)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read todos: %w", err)
+	}
+
+	return items, failures, nil
+}
+
+func parseBatchLine(text string, def list.Definition, defaultDue *time.Time, opts interchange.Options) (interchange.Item, error) {
+	var (
+		words   []string
+		tags    []string
+		project string
+		due     *time.Time
+	)
+
+	item := interchange.Item{List: def.ID}
+
+	for _, word := range strings.Fields(text) {
+		switch {
+		case len(word) > 1 && word[0] == '@':
+			tags = append(tags, word[1:])
+		case len(word) > 1 && word[0] == '+':
+			if project != "" {
+				return item, fmt.Errorf("more than one project")
+			}
+			project = word[1:]
+		case strings.HasPrefix(word, "due:"):
+			d, err := parseDue(strings.TrimPrefix(word, "due:"), opts)
+			if err != nil {
+				return item, err
+			}
+			due = d
+		default:
+			words = append(words, word)
+		}
+	}
+
+	if due != nil {
+		item.List = interchange.ListForDueDate(opts.Calendar, due, opts.Now)
+	} else {
+		due = defaultDue
+	}
+
+	item.Todo = model.NewTodo(strings.Join(words, " "), "", due, opts.Now)
+	item.Todo.Project = project
+	item.Todo.Tags = tags
+
+	return item, nil
+}
+
+// parseDue parses the value of a due: word.
+func parseDue(value string, opts interchange.Options) (*time.Time, error) {
+	switch strings.ToLower(value) {
+	case "today":
+		return list.DefaultDueDate(opts.Calendar, list.TodayID, opts.Now), nil
+	case "tomorrow":
+		return list.DefaultDueDate(opts.Calendar, list.TomorrowID, opts.Now), nil
+	}
+
+	due, err := time.ParseInLocation(time.DateOnly, value, opts.Calendar.Location())
+	if err != nil {
+		return nil, fmt.Errorf("invalid due date %q, expected today, tomorrow or YYYY-MM-DD", value)
+	}
+
+	return &due, nil
+}
@@ -0,0 +1,446 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unfunco/t/internal/automation"
+	"github.com/unfunco/t/internal/backup"
+	"github.com/unfunco/t/internal/clock"
+	"github.com/unfunco/t/internal/config"
+	"github.com/unfunco/t/internal/crypt"
+	"github.com/unfunco/t/internal/gitstore"
+	"github.com/unfunco/t/internal/hook"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/merge"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/oplog"
+	"github.com/unfunco/t/internal/paths"
+	"github.com/unfunco/t/internal/reconcile"
+	"github.com/unfunco/t/internal/storage"
+	"github.com/unfunco/t/internal/theme"
+)
+
+// app holds the dependencies resolved for an invocation of the t command and
+// shares them with its subcommands.
+type app struct {
+	opts   Options
+	clock  clock.Clock
+	errOut io.Writer
+	// global ignores project data directories in favour of the default one.
+	global bool
+	// profileFlag is the profile selected with --profile.
+	profileFlag string
+	// profile is the profile in use, if any, and profileDir its data
+	// directory.
+	profile    string
+	profileDir string
+}
+
+// now returns the current time according to the resolved clock.
+func (a *app) now() time.Time {
+	return a.clock.Now()
+}
+
+// dataDir returns the directory holding the list files: the nearest .t
+// directory above the working directory, unless --global or --profile is
+// given, or the data directory of the profile in use.
+func (a *app) dataDir() (string, error) {
+	if !a.global && a.profileFlag == "" {
+		if cwd, err := os.Getwd(); err == nil {
+			if dataDir, ok := paths.FindLocalDataDir(cwd); ok {
+				return dataDir, nil
+			}
+		}
+	}
+
+	if a.profileDir != "" {
+		return a.profileDir, nil
+	}
+
+	dataDir, err := paths.DefaultDataDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get data directory: %w", err)
+	}
+
+	return dataDir, nil
+}
+
+// selectedProfile returns the name of the profile selected with --profile,
+// the T_PROFILE environment variable or the configuration, in that order.
+func (a *app) selectedProfile() string {
+	for _, name := range []string{a.profileFlag, os.Getenv(config.ProfileEnvVar), a.opts.Profile} {
+		if name != "" {
+			return name
+		}
+	}
+
+	return config.DefaultProfile
+}
+
+// useProfile applies the settings of the named profile.
+func (a *app) useProfile(name string) error {
+	if name == config.DefaultProfile {
+		return nil
+	}
+
+	p, ok := a.opts.Profiles[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", config.ErrUnknownProfile, name)
+	}
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("invalid profile %s: %w", name, err)
+	}
+
+	dataDir, err := p.DataDirFor(name)
+	if err != nil {
+		return fmt.Errorf("failed to get data directory for profile %s: %w", name, err)
+	}
+
+	if p.Theme != nil {
+		th, err := theme.FromConfig(*p.Theme, a.opts.DarkBackground)
+		if err != nil {
+			return fmt.Errorf("invalid theme for profile %s: %w", name, err)
+		}
+		a.opts.Theme = th
+	}
+	if p.Git != nil {
+		a.opts.Git = *p.Git
+	}
+
+	a.profile = name
+	a.profileDir = dataDir
+
+	return nil
+}
+
+// storeName describes the data directory for display: the path of a project
+// data directory, with the home directory abbreviated, or the name of the
+// profile in use.
+func (a *app) storeName(dataDir string) string {
+	if !isProjectDir(dataDir) {
+		if a.profile != "" {
+			return a.profile
+		}
+		return "global"
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		if rel, err := filepath.Rel(home, dataDir); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.Join("~", rel)
+		}
+	}
+
+	return dataDir
+}
+
+// isProjectDir reports whether dataDir holds the lists of a project, in a .t
+// directory created by t init.
+func isProjectDir(dataDir string) bool {
+	return filepath.Base(dataDir) == paths.LocalDirName
+}
+
+// gitEnabled reports whether saves to dataDir are committed to a git
+// repository of their own. Project lists are committed with the project's
+// code instead, so no repository is nested inside the project's.
+func (a *app) gitEnabled(dataDir string) bool {
+	return a.opts.Git.Enabled && !isProjectDir(dataDir)
+}
+
+// openStorage returns the storage backend for this invocation. Saves are
+// committed to git when enabled, snapshotted according to the backup
+// configuration and recorded in the operation log.
+func (a *app) openStorage() (*oplog.Storage, error) {
+	dataDir, err := a.dataDir()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := a.cipher(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.openStack(dataDir, c, true)
+}
+
+// openStack returns the storage backend for dataDir. With stamp, every save
+// records when each field of a todo changed; merging conflict copies leaves it
+// out so that the times worked out by the merge are saved as they are.
+func (a *app) openStack(dataDir string, c storage.Cipher, stamp bool) (*oplog.Storage, error) {
+	file, err := storage.NewFileStorageWithCipher(dataDir, c)
+	if err != nil {
+		return nil, err
+	}
+
+	var inner storage.Storage = file
+	if stamp {
+		inner = reconcile.NewStorage(file, dataDir, a.clock)
+	}
+	if a.gitEnabled(dataDir) {
+		repo, err := gitstore.Open(inner, dataDir, c, a.opts.Git, a.clock)
+		if err != nil {
+			return nil, err
+		}
+		inner = repo
+	}
+
+	backed := backup.NewStorage(inner, dataDir, a.opts.Backup, a.opts.Calendar, a.clock)
+
+	store := oplog.NewStorageWithCipher(backed, dataDir, c, a.clock)
+	if !a.opts.Hooks.Empty() {
+		r := hook.NewRunner(a.opts.Hooks, a.errOut)
+		check := runHooks(r, preHookEvents)
+		store.Check(func(events []oplog.Event) error {
+			// Merging conflicting copies only brings back todos that were
+			// already saved, so it is not offered to pre-hooks to cancel.
+			if events[0].Source == oplog.SourceReconcile {
+				return nil
+			}
+			return check(events)
+		})
+		store.Notify(runHooks(r, hookEvents))
+	}
+
+	return store, nil
+}
+
+// hookEvents maps the changes recorded in the operation log to the hooks run
+// once they are saved.
+var hookEvents = map[oplog.Kind]hook.Event{
+	oplog.KindCreated:         hook.EventAdd,
+	oplog.KindCompleted:       hook.EventComplete,
+	oplog.KindMoved:           hook.EventMove,
+	oplog.KindAutomationMoved: hook.EventAutomation,
+}
+
+// preHookEvents maps the changes recorded in the operation log to the hooks
+// run before they are saved.
+var preHookEvents = map[oplog.Kind]hook.Event{
+	oplog.KindCreated:         hook.EventPreAdd,
+	oplog.KindCompleted:       hook.EventPreComplete,
+	oplog.KindMoved:           hook.EventPreMove,
+	oplog.KindAutomationMoved: hook.EventPreAutomation,
+}
+
+// runHooks returns a function that runs the hooks in hooks for each change
+// recorded in the operation log, whether it was made by a command, the TUI or
+// an automation. It returns the error of a pre-hook that cancels the change.
+func runHooks(r *hook.Runner, hooks map[oplog.Kind]hook.Event) func([]oplog.Event) error {
+	return func(events []oplog.Event) error {
+		for _, e := range events {
+			event, ok := hooks[e.Kind]
+			if !ok || e.After == nil {
+				continue
+			}
+
+			p := hook.Payload{
+				Event:  event,
+				Source: string(e.Source),
+				List:   e.To,
+				Todo:   *e.After,
+			}
+			if e.Kind == oplog.KindMoved || e.Kind == oplog.KindAutomationMoved {
+				p.From = e.From
+			}
+
+			if err := r.Run(p); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// cipher returns the cipher the files in dataDir are encrypted with, which
+// leaves them unencrypted unless t encrypt has been run.
+func (a *app) cipher(dataDir string) (storage.Cipher, error) {
+	c, err := crypt.Open(dataDir, a.opts.Encryption)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open encrypted data directory: %w", err)
+	}
+
+	return c, nil
+}
+
+// openFile returns file storage for dataDir that records when each field of
+// a todo changed, so that conflicting copies of the lists can be merged.
+func (a *app) openFile(dataDir string, c storage.Cipher) (*reconcile.Storage, error) {
+	file, err := storage.NewFileStorageWithCipher(dataDir, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return reconcile.NewStorage(file, dataDir, a.clock), nil
+}
+
+// openGit returns file storage for dataDir that commits every save to git.
+func (a *app) openGit(dataDir string, c storage.Cipher) (*gitstore.Storage, error) {
+	file, err := a.openFile(dataDir, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return gitstore.Open(file, dataDir, c, a.opts.Git, a.clock)
+}
+
+// reconcileConflicts merges the conflict copies of the list files left by a
+// sync service and reports what they changed. The merge is saved like any
+// other change, as an operation of its own. A failure is reported without
+// stopping the command, leaving the copies for another attempt.
+func (a *app) reconcileConflicts() {
+	dataDir, err := a.dataDir()
+	if err != nil {
+		return
+	}
+
+	c, err := a.cipher(dataDir)
+	if err != nil {
+		return
+	}
+
+	store, err := a.openStack(dataDir, c, false)
+	if err != nil {
+		return
+	}
+
+	store.Begin(oplog.SourceReconcile)
+
+	reconciled, err := reconcile.Conflicts(store, dataDir, c, a.now())
+	if err != nil {
+		_, _ = fmt.Fprintf(a.errOut, "warning: failed to merge conflicting copies of your lists: %v\n", err)
+		return
+	}
+
+	for _, r := range reconciled {
+		_, _ = fmt.Fprintf(a.errOut, "Merged %s: %s\n", r.File, describeReport(r.Report))
+	}
+
+	if err := store.Commit(); err != nil {
+		_, _ = fmt.Fprintf(a.errOut, "warning: %v\n", err)
+	}
+}
+
+// describeReport summarises the changes made by a merge.
+func describeReport(report merge.Report) string {
+	if report.Empty() {
+		return "no changes"
+	}
+
+	var parts []string
+	for _, change := range []struct {
+		verb   string
+		titles []string
+	}{
+		{"added", report.Added},
+		{"updated", report.Updated},
+		{"deleted", report.Deleted},
+	} {
+		if len(change.titles) > 0 {
+			parts = append(parts, fmt.Sprintf("%s %s", change.verb, strings.Join(quoteAll(change.titles), ", ")))
+		}
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+func quoteAll(titles []string) []string {
+	quoted := make([]string, len(titles))
+	for i, title := range titles {
+		quoted[i] = strconv.Quote(title)
+	}
+
+	return quoted
+}
+
+// operationStorage is storage that records the changes saved between Begin and
+// Commit as a single operation, such as *oplog.Storage.
+type operationStorage interface {
+	storage.Storage
+	Begin(oplog.Source)
+	Commit() error
+}
+
+// syncLists merges any conflicting copies of the lists, applies scheduled
+// automations and returns every default list.
+func (a *app) syncLists(store operationStorage) (map[list.ID]*model.TodoList, error) {
+	a.reconcileConflicts()
+
+	return a.applyAutomations(store)
+}
+
+// applyAutomations applies scheduled automations and returns every default
+// list. The changes made by automations are logged as an operation of their
+// own.
+func (a *app) applyAutomations(store operationStorage) (map[list.ID]*model.TodoList, error) {
+	store.Begin(oplog.SourceAutomation)
+
+	lists, err := automation.Sync(store, a.opts.Calendar, a.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare lists: %w", err)
+	}
+
+	err = store.Commit()
+	if errors.Is(err, oplog.ErrCancelled) {
+		// A hook cancelled the automations, so carry on with the lists as
+		// they were saved.
+		_, _ = fmt.Fprintf(a.errOut, "warning: automations not applied: %v\n", err)
+		return loadLists(store)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record automations: %w", err)
+	}
+
+	return lists, nil
+}
+
+// loadLists returns every default list as it is saved in store.
+func loadLists(store storage.Storage) (map[list.ID]*model.TodoList, error) {
+	defs := list.Default()
+	lists := make(map[list.ID]*model.TodoList, len(defs))
+
+	for _, def := range defs {
+		l, err := store.LoadList(def)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s list: %w", def.Name, err)
+		}
+		lists[def.ID] = l
+	}
+
+	return lists, nil
+}
+
+// resolveClock returns the clock to use for a command invocation. The --now
+// flag takes precedence over the T_NOW environment variable, which in turn
+// takes precedence over the configured clock.
+func resolveClock(configured clock.Clock, nowFlag string) (clock.Clock, error) {
+	value := nowFlag
+	if value == "" {
+		value = os.Getenv(clock.EnvVar)
+	}
+
+	if value != "" {
+		fixed, err := clock.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fixed time: %w", err)
+		}
+
+		return clock.Fixed(fixed), nil
+	}
+
+	if configured != nil {
+		return configured, nil
+	}
+
+	return clock.System(), nil
+}
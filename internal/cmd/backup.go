@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+	"github.com/unfunco/t/internal/backup"
+)
+
+func newBackupCommand(a *app) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "backup",
+		Short: "Manage snapshots of your todo lists.",
+		Long: heredoc.Doc(`
+			Snapshots of the data directory are taken automatically before
+			changes are saved, according to the backup mode in your config.
+			Use these commands to inspect and restore them.
+		`),
+		Args: cobra.NoArgs,
+	}
+
+	c.AddCommand(newBackupListCommand(a))
+	c.AddCommand(newBackupRestoreCommand(a))
+
+	return c
+}
+
+func newBackupListCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List available snapshots.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			dataDir, err := a.dataDir()
+			if err != nil {
+				return err
+			}
+
+			snapshots, err := backup.List(dataDir)
+			if err != nil {
+				return fmt.Errorf("failed to list backups: %w", err)
+			}
+
+			if len(snapshots) == 0 {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No backups")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "SNAPSHOT\tCREATED\tFILES")
+			for _, s := range snapshots {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%d\n",
+					s.Name,
+					s.CreatedAt.In(a.opts.Calendar.Location()).Format(time.DateTime),
+					len(s.Files),
+				)
+			}
+
+			return w.Flush()
+		},
+	}
+}
+
+func newBackupRestoreCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <snapshot>",
+		Short: "Restore your todo lists from a snapshot.",
+		Long: heredoc.Doc(`
+			Replace the current todo lists with those in the given snapshot.
+			The current lists are snapshotted first, so a restore can itself
+			be undone.
+		`),
+		Example: heredoc.Doc(`
+			t backup list
+			t backup restore 20250102T090000Z
+		`),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: a.completeSnapshots,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dataDir, err := a.dataDir()
+			if err != nil {
+				return err
+			}
+
+			safety, err := backup.Restore(dataDir, args[0], a.now())
+			if err != nil {
+				return fmt.Errorf("failed to restore backup: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(),
+				"Restored snapshot %s; previous lists saved as %s\n",
+				args[0], safety.Name,
+			)
+
+			return nil
+		},
+	}
+}
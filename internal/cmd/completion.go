@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/unfunco/t/internal/backup"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/oplog"
+	"github.com/unfunco/t/internal/storage"
+)
+
+// completionFunc is the signature of cobra's argument and flag completions.
+type completionFunc = func(*cobra.Command, []string, string) ([]cobra.Completion, cobra.ShellCompDirective)
+
+// completeTodoIDs completes the IDs of the todos in every list, described by
+// their titles. When includeLog is set, todos that only appear in the
+// operation log, such as deleted ones, are completed too.
+func (a *app) completeTodoIDs(includeLog bool) completionFunc {
+	return func(_ *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		dataDir, err := a.completionDataDir()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		c, err := a.cipher(dataDir)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		seen := make(map[string]bool)
+		var completions []cobra.Completion
+		add := func(id, description string) {
+			if seen[id] || !strings.HasPrefix(id, toComplete) {
+				return
+			}
+			seen[id] = true
+			completions = append(completions, cobra.CompletionWithDesc(id, description))
+		}
+
+		err = eachStoredTodo(dataDir, c, func(def list.Definition, todo model.Todo) {
+			add(todo.ID, fmt.Sprintf("%s (%s)", todo.Title, def.Name))
+		})
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		if includeLog {
+			events, err := oplog.Read(dataDir, c)
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+			for i := len(events) - 1; i >= 0; i-- {
+				add(events[i].TodoID, events[i].Title())
+			}
+		}
+
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeOpenTodoIDs completes the IDs of the todos that have not been
+// completed, leaving out any that have already been given.
+func (a *app) completeOpenTodoIDs(_ *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	dataDir, err := a.completionDataDir()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	c, err := a.cipher(dataDir)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var completions []cobra.Completion
+	err = eachStoredTodo(dataDir, c, func(def list.Definition, todo model.Todo) {
+		if todo.Completed || slices.Contains(args, todo.ID) || !strings.HasPrefix(todo.ID, toComplete) {
+			return
+		}
+		completions = append(completions, cobra.CompletionWithDesc(todo.ID, fmt.Sprintf("%s (%s)", todo.Title, def.Name)))
+	})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completionDataDir returns the data directory to complete from. Cobra
+// completes arguments without running PersistentPreRunE, so the selected
+// profile is applied here.
+func (a *app) completionDataDir() (string, error) {
+	if err := a.useProfile(a.selectedProfile()); err != nil {
+		return "", err
+	}
+
+	return a.dataDir()
+}
+
+// eachStoredTodo calls fn for every todo in the default lists, decrypting them
+// with c. Lists are read directly rather than through openStorage so that
+// completing never runs automations or records operations.
+func eachStoredTodo(dataDir string, c storage.Cipher, fn func(list.Definition, model.Todo)) error {
+	store, err := storage.NewFileStorageWithCipher(dataDir, c)
+	if err != nil {
+		return err
+	}
+
+	for _, def := range list.Default() {
+		l, err := store.LoadList(def)
+		if err != nil {
+			return err
+		}
+		for _, todo := range l.Todos {
+			fn(def, todo)
+		}
+	}
+
+	return nil
+}
+
+// completeListIDs completes the IDs of the default lists, described by their
+// names.
+func completeListIDs(_ *cobra.Command, _ []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	completions := make([]cobra.Completion, 0, len(list.Default()))
+	for _, def := range list.Default() {
+		completions = append(completions, cobra.CompletionWithDesc(string(def.ID), def.Name))
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeFormats completes the names of the formats for which include
+// returns true.
+func completeFormats(include func(format) bool) completionFunc {
+	return func(_ *cobra.Command, _ []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		return formatNames(include), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeSnapshots completes the names of the backup snapshots, described by
+// the number of files they hold.
+func (a *app) completeSnapshots(_ *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	dataDir, err := a.completionDataDir()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	snapshots, err := backup.List(dataDir)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	completions := make([]cobra.Completion, 0, len(snapshots))
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		s := snapshots[i]
+		completions = append(completions, cobra.CompletionWithDesc(s.Name, fmt.Sprintf("%d files", len(s.Files))))
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+	"github.com/unfunco/t/internal/crypt"
+)
+
+func newDecryptCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "decrypt",
+		Short: "Decrypt your todo lists, turning encryption off.",
+		Long: heredoc.Doc(`
+			Decrypt the todo lists in the data directory, along with their
+			backups and the operation log, undoing t encrypt. The key used
+			to read them is left in place.
+		`),
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			dataDir, err := a.dataDir()
+			if err != nil {
+				return err
+			}
+
+			encrypted, err := crypt.Encrypted(dataDir)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt data directory: %w", err)
+			}
+			if !encrypted {
+				return fmt.Errorf("failed to decrypt data directory: %w", crypt.ErrNotEncrypted)
+			}
+
+			key, err := crypt.LoadKey(a.opts.Encryption)
+			if err != nil {
+				return fmt.Errorf("failed to load encryption key: %w", err)
+			}
+
+			if err := crypt.Decrypt(dataDir, key); err != nil {
+				return fmt.Errorf("failed to decrypt data directory: %w", err)
+			}
+
+			if err := a.commitConversion(dataDir, "Decrypt todos"); err != nil {
+				return fmt.Errorf("failed to commit decrypted lists: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Decrypted %s\n", dataDir)
+
+			return nil
+		},
+	}
+}
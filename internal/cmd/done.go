@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/query"
+)
+
+func newDoneCommand(a *app) *cobra.Command {
+	var where string
+
+	c := &cobra.Command{
+		Use:   "done [id...] [--where query]",
+		Short: "Complete todos by ID or by query.",
+		Long: heredoc.Doc(`
+			Complete the todos with the given IDs, or every open todo matching
+			the query given with --where. IDs can be shortened to any prefix
+			that matches a single todo. Queries are written as for t list.
+
+			The todos are completed in a single change, which t undo reverts.
+		`),
+		Example: heredoc.Doc(`
+			t done 0193a5c2
+			t done --where 'list:today and title~standup'
+			t done --where 'tag:errand and due < today'
+		`),
+		ValidArgsFunction: a.completeOpenTodoIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch {
+			case where != "" && len(args) > 0:
+				return errors.New("cannot use --where with todo IDs")
+			case where == "" && len(args) == 0:
+				return errors.New("a todo ID or --where is required")
+			}
+
+			store, err := a.openStorage()
+			if err != nil {
+				return fmt.Errorf("failed to initialise storage: %w", err)
+			}
+
+			lists, err := a.syncLists(store)
+			if err != nil {
+				return err
+			}
+
+			var matches []match
+			if where != "" {
+				q, err := query.Parse(where, a.opts.Calendar, a.now())
+				if err != nil {
+					return err
+				}
+				if q.Empty() {
+					return errors.New("--where query cannot be blank")
+				}
+				for _, m := range matchTodos(lists, q) {
+					if !m.todo.Completed {
+						matches = append(matches, m)
+					}
+				}
+			} else {
+				matches, err = findTodos(lists, args)
+				if err != nil {
+					return err
+				}
+			}
+
+			if len(matches) == 0 {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No todos to complete")
+				return nil
+			}
+
+			changed := make(map[list.ID]bool)
+			for _, m := range matches {
+				if m.todo.Completed {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Already done: %s\n", m.todo.Title)
+					continue
+				}
+				m.todo.ToggleCompleted(a.now())
+				changed[m.def.ID] = true
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Completed: %s\n", m.todo.Title)
+			}
+
+			var defs []list.Definition
+			for _, def := range list.Default() {
+				if changed[def.ID] {
+					defs = append(defs, def)
+				}
+			}
+
+			return saveOperation(store, lists, defs...)
+		},
+	}
+
+	c.Flags().StringVarP(&where, "where", "w", "", "Complete every open todo matching this query")
+
+	return c
+}
+
+// findTodos returns the todo identified by each ID, which may be the start of
+// an ID as long as it matches a single todo.
+func findTodos(lists map[list.ID]*model.TodoList, ids []string) ([]match, error) {
+	all := matchTodos(lists, nil)
+
+	var (
+		found []match
+		seen  = make(map[string]bool)
+	)
+	for _, id := range ids {
+		var candidates []match
+		for _, m := range all {
+			if m.todo.ID == id {
+				candidates = []match{m}
+				break
+			}
+			if strings.HasPrefix(m.todo.ID, id) {
+				candidates = append(candidates, m)
+			}
+		}
+
+		switch len(candidates) {
+		case 0:
+			return nil, fmt.Errorf("no todo with ID %q", id)
+		case 1:
+		default:
+			return nil, fmt.Errorf("ID %q matches %d todos, use more of the ID", id, len(candidates))
+		}
+
+		if m := candidates[0]; !seen[m.todo.ID] {
+			seen[m.todo.ID] = true
+			found = append(found, m)
+		}
+	}
+
+	return found, nil
+}
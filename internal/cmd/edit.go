@@ -0,0 +1,120 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/oplog"
+)
+
+func newEditCommand(a *app) *cobra.Command {
+	var title, description, due string
+
+	c := &cobra.Command{
+		Use:   "edit id [--flags]",
+		Short: "Change the title, description or due date of a todo.",
+		Long: heredoc.Doc(`
+			Change the title, description or due date of a todo. The ID can be
+			shortened to any prefix that matches a single todo. Only the fields
+			given as flags are changed; use t move to move a todo to another
+			list.
+		`),
+		Example: heredoc.Doc(`
+			t edit 0193a5c2 --title "Call Mum and Dad"
+			t edit 0193a5c2 --description "" --due none
+		`),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: a.completeTodoIDs(false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags := cmd.Flags()
+			if !flags.Changed("title") && !flags.Changed("description") && !flags.Changed("due") {
+				return errors.New("nothing to change, use --title, --description or --due")
+			}
+
+			if flags.Changed("title") {
+				title = strings.TrimSpace(title)
+				if err := model.ValidateTitle(title); err != nil {
+					return err
+				}
+			}
+
+			var dueDate *time.Time
+			if flags.Changed("due") && !strings.EqualFold(due, "none") {
+				d, err := parseDue(due, a.interchangeOptions())
+				if err != nil {
+					return err
+				}
+				dueDate = d
+			}
+
+			store, err := a.openStorage()
+			if err != nil {
+				return fmt.Errorf("failed to initialise storage: %w", err)
+			}
+
+			lists, err := a.syncLists(store)
+			if err != nil {
+				return err
+			}
+
+			matches, err := findTodos(lists, args)
+			if err != nil {
+				return err
+			}
+			m := matches[0]
+
+			if flags.Changed("title") {
+				m.todo.Title = title
+			}
+			if flags.Changed("description") {
+				m.todo.Description = strings.TrimSpace(description)
+			}
+			if flags.Changed("due") {
+				m.todo.SetDueDate(dueDate)
+			}
+
+			if err := saveOperation(store, lists, m.def); err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Updated: %s\n", m.todo.Title)
+
+			return nil
+		},
+	}
+
+	c.Flags().StringVar(&title, "title", "", "New title of the todo")
+	c.Flags().StringVarP(&description, "description", "d", "", "New description of the todo")
+	c.Flags().StringVar(&due, "due", "", "New due date (today, tomorrow, YYYY-MM-DD or none)")
+
+	_ = c.RegisterFlagCompletionFunc("due", cobra.FixedCompletions([]cobra.Completion{"today", "tomorrow", "none"}, cobra.ShellCompDirectiveNoFileComp))
+
+	return c
+}
+
+// saveOperation saves the given lists as a single operation run from the
+// command line.
+func saveOperation(store *oplog.Storage, lists map[list.ID]*model.TodoList, defs ...list.Definition) error {
+	store.Begin(oplog.SourceCLI)
+
+	for _, def := range defs {
+		if err := store.SaveList(def, lists[def.ID]); err != nil {
+			return fmt.Errorf("failed to save %s list: %w", def.Name, err)
+		}
+	}
+
+	if err := store.Commit(); err != nil {
+		return fmt.Errorf("failed to record changes: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+	"github.com/unfunco/t/internal/crypt"
+	"github.com/unfunco/t/internal/gitstore"
+)
+
+func newEncryptCommand(a *app) *cobra.Command {
+	var force bool
+
+	c := &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypt your todo lists at rest.",
+		Long: heredoc.Doc(`
+			Encrypt the todo lists in the data directory, along with their
+			backups and the operation log, using age. From then on every
+			command reads and writes them encrypted.
+
+			The secret key is read from the T_KEY environment variable, or
+			from the key file, which defaults to key.txt in the config
+			directory and can be changed in config.json:
+
+			  "encryption": {"key_file": "/path/to/key.txt"}
+
+			If there is no key yet, one is generated and saved to the key
+			file. Keep a copy of it somewhere safe: without it your todos
+			cannot be read.
+
+			With git storage, the encrypted lists are committed straight
+			away. Encrypting cannot remove the unencrypted lists from earlier
+			commits, or from a remote they were pushed to, so t encrypt
+			refuses to run when the history holds any unless --force is
+			given.
+		`),
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			dataDir, err := a.dataDir()
+			if err != nil {
+				return err
+			}
+
+			encrypted, err := crypt.Encrypted(dataDir)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt data directory: %w", err)
+			}
+			if encrypted {
+				return fmt.Errorf("failed to encrypt data directory: %w", crypt.ErrEncrypted)
+			}
+
+			history, err := gitstore.HoldsLists(dataDir)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt data directory: %w", err)
+			}
+			if history && !force {
+				return fmt.Errorf("the git history in %s holds your todos unencrypted, and encrypting them will not remove them from it or from any remote; use --force to encrypt them anyway", dataDir)
+			}
+
+			key, err := crypt.LoadKey(a.opts.Encryption)
+			if errors.Is(err, crypt.ErrNoKey) {
+				var path string
+				key, path, err = crypt.GenerateKey(a.opts.Encryption, a.now())
+				if err == nil {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Generated a key in %s, keep a copy somewhere safe\n", path)
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("failed to load encryption key: %w", err)
+			}
+
+			if err := crypt.Encrypt(dataDir, key); err != nil {
+				return fmt.Errorf("failed to encrypt data directory: %w", err)
+			}
+
+			if err := a.commitConversion(dataDir, "Encrypt todos"); err != nil {
+				return fmt.Errorf("failed to commit encrypted lists: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Encrypted %s\n", dataDir)
+
+			if history {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: earlier commits in %s still hold your todos unencrypted, and so does any remote they were pushed to\n", dataDir)
+			}
+
+			return nil
+		},
+	}
+
+	c.Flags().BoolVar(&force, "force", false, "Encrypt even though the git history holds the lists unencrypted")
+
+	return c
+}
+
+// commitConversion commits the lists rewritten by t encrypt or t decrypt when
+// they are kept in git, so that the repository matches them.
+func (a *app) commitConversion(dataDir, message string) error {
+	if !a.gitEnabled(dataDir) {
+		return nil
+	}
+
+	c, err := a.cipher(dataDir)
+	if err != nil {
+		return err
+	}
+
+	store, err := a.openGit(dataDir, c)
+	if err != nil {
+		return err
+	}
+
+	return store.CommitLists(message)
+}
@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+	"github.com/unfunco/t/internal/interchange"
+	"github.com/unfunco/t/internal/list"
+)
+
+func newExportCommand(a *app) *cobra.Command {
+	var (
+		formatName string
+		output     string
+	)
+
+	c := &cobra.Command{
+		Use:   "export --format <format> [--flags]",
+		Short: "Export todos for use in another application.",
+		Long: heredoc.Docf(`
+			Export every todo list to standard output, or to a file with
+			--output. Supported formats: %s.
+
+			Anything that the format cannot represent is reported.
+		`, strings.Join(formatNames(canExport), ", ")),
+		Example: heredoc.Doc(`
+			t export --format todotxt > todo.txt
+			t export --format todotxt --output todo.txt
+			t export --output todos.ics
+			t export --format markdown
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			f, err := lookupFormat(formatName, output)
+			if err != nil {
+				return err
+			}
+			if f.exporter == nil {
+				return fmt.Errorf("format %s does not support export", f.name)
+			}
+
+			store, err := a.openStorage()
+			if err != nil {
+				return fmt.Errorf("failed to initialise storage: %w", err)
+			}
+
+			lists, err := a.syncLists(store)
+			if err != nil {
+				return err
+			}
+
+			var exported []interchange.List
+			for _, def := range list.Default() {
+				exported = append(exported, interchange.List{
+					Definition: def,
+					Todos:      lists[def.ID].Todos,
+				})
+			}
+
+			var (
+				w    io.Writer = cmd.OutOrStdout()
+				file *os.File
+			)
+			if output != "" && output != "-" {
+				file, err = os.OpenFile(output, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+				if err != nil {
+					return fmt.Errorf("failed to create %s: %w", output, err)
+				}
+				w = file
+			}
+
+			report, err := f.exporter(w, exported, a.interchangeOptions())
+			if file != nil {
+				if closeErr := file.Close(); err == nil && closeErr != nil {
+					err = closeErr
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("failed to export %s: %w", f.name, err)
+			}
+
+			printReport(cmd.ErrOrStderr(), report)
+
+			return nil
+		},
+	}
+
+	c.Flags().StringVarP(&formatName, "format", "f", "", "Format of the output ("+strings.Join(formatNames(canExport), ", ")+")")
+	_ = c.RegisterFlagCompletionFunc("format", completeFormats(canExport))
+	c.Flags().StringVarP(&output, "output", "o", "", "Write to a file instead of standard output")
+
+	return c
+}
@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/unfunco/t/internal/interchange"
+	"github.com/unfunco/t/internal/interchange/csv"
+	"github.com/unfunco/t/internal/interchange/ical"
+	"github.com/unfunco/t/internal/interchange/markdown"
+	"github.com/unfunco/t/internal/interchange/taskwarrior"
+	"github.com/unfunco/t/internal/interchange/todotxt"
+)
+
+// format describes a file format that todos can be imported from or exported
+// to. Formats that only support one direction leave the other function nil.
+type format struct {
+	name       string
+	extensions []string
+	// columns reports whether the format has named columns that can be
+	// mapped onto fields on import.
+	columns  bool
+	importer func(io.Reader, interchange.Options) ([]interchange.Item, interchange.Report, error)
+	exporter func(io.Writer, []interchange.List, interchange.Options) (interchange.Report, error)
+}
+
+var formats = []format{
+	{
+		name:       "todotxt",
+		extensions: []string{".txt"},
+		importer:   todotxt.Import,
+		exporter:   todotxt.Export,
+	},
+	{
+		name:       "ical",
+		extensions: []string{".ics", ".ical"},
+		importer:   ical.Import,
+		exporter:   ical.Export,
+	},
+	{
+		name:       "markdown",
+		extensions: []string{".md", ".markdown"},
+		importer:   markdown.Import,
+		exporter:   markdown.Export,
+	},
+	{
+		name:       "csv",
+		extensions: []string{".csv"},
+		columns:    true,
+		importer:   csv.Import,
+		exporter:   csv.Export,
+	},
+	{
+		name:     "taskwarrior",
+		importer: taskwarrior.Import,
+	},
+}
+
+// lookupFormat returns the format with the given name or, if name is empty,
+// the format matching the extension of path.
+func lookupFormat(name, path string) (format, error) {
+	if name == "" {
+		if path == "" || path == "-" {
+			return format{}, fmt.Errorf("--format is required, expected one of %s", strings.Join(formatNames(nil), ", "))
+		}
+
+		ext := strings.ToLower(filepath.Ext(path))
+		for _, f := range formats {
+			for _, e := range f.extensions {
+				if e == ext {
+					return f, nil
+				}
+			}
+		}
+
+		return format{}, fmt.Errorf("cannot infer format from %q, use --format (one of %s)", path, strings.Join(formatNames(nil), ", "))
+	}
+
+	for _, f := range formats {
+		if f.name == name {
+			return f, nil
+		}
+	}
+
+	return format{}, fmt.Errorf("unknown format %q, expected one of %s", name, strings.Join(formatNames(nil), ", "))
+}
+
+// formatNames returns the names of the formats for which include returns
+// true, or of every format when include is nil.
+func formatNames(include func(format) bool) []string {
+	var names []string
+	for _, f := range formats {
+		if include == nil || include(f) {
+			names = append(names, f.name)
+		}
+	}
+
+	return names
+}
+
+func canImport(f format) bool {
+	return f.importer != nil
+}
+
+func canExport(f format) bool {
+	return f.exporter != nil
+}
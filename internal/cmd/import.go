@@ -0,0 +1,244 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+	"github.com/unfunco/t/internal/calendar"
+	"github.com/unfunco/t/internal/interchange"
+	"github.com/unfunco/t/internal/interchange/csv"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/oplog"
+)
+
+func newImportCommand(a *app) *cobra.Command {
+	var (
+		formatName string
+		columns    map[string]string
+	)
+
+	c := &cobra.Command{
+		Use:   "import [file] [--flags]",
+		Short: "Import todos from another application.",
+		Long: heredoc.Docf(`
+			Import todos from a file, or from standard input when no file or "-"
+			is given. The format is inferred from the file extension unless
+			--format is set. Supported formats: %s.
+
+			Imported todos are placed in the Today or Tomorrow list when they
+			are due then, and in the Todos list otherwise. Anything that cannot
+			be represented is reported and skipped.
+
+			CSV columns are matched to fields by name. Use --map to read a
+			field from a differently named column. The fields are: %s.
+		`, strings.Join(formatNames(canImport), ", "), strings.Join(csv.Fields, ", ")),
+		Example: heredoc.Doc(`
+			t import todo.txt
+			t import reminders.ics
+			t import notes.md
+			t import tasks.csv --map title=Task,due_date=Deadline,completed=Done
+			task export | t import --format taskwarrior
+			cat todo.txt | t import --format todotxt
+		`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "-"
+			if len(args) == 1 {
+				path = args[0]
+			}
+
+			f, err := lookupFormat(formatName, path)
+			if err != nil {
+				return err
+			}
+			if f.importer == nil {
+				return fmt.Errorf("format %s does not support import", f.name)
+			}
+			if len(columns) > 0 && !f.columns {
+				return fmt.Errorf("format %s does not support --map", f.name)
+			}
+
+			r, closeInput, err := openInput(cmd, path)
+			if err != nil {
+				return err
+			}
+			defer closeInput()
+
+			opts := a.interchangeOptions()
+			opts.Columns = columns
+
+			items, report, err := f.importer(r, opts)
+			if err != nil {
+				return fmt.Errorf("failed to import %s: %w", f.name, err)
+			}
+
+			store, err := a.openStorage()
+			if err != nil {
+				return fmt.Errorf("failed to initialise storage: %w", err)
+			}
+
+			added, failures, err := addItems(a, store, items)
+			if err != nil {
+				return err
+			}
+
+			failures = append(report.Failed, failures...)
+
+			printReport(cmd.ErrOrStderr(), report)
+			for _, failure := range failures {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "skipped: %s\n", failure)
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Imported %d of %d todos\n", added, len(items)+len(report.Failed))
+
+			if len(failures) > 0 {
+				return fmt.Errorf("%d todos could not be imported", len(failures))
+			}
+
+			return nil
+		},
+	}
+
+	c.Flags().StringVarP(&formatName, "format", "f", "", "Format of the input ("+strings.Join(formatNames(canImport), ", ")+")")
+	_ = c.RegisterFlagCompletionFunc("format", completeFormats(canImport))
+	c.Flags().StringToStringVar(&columns, "map", nil, "Read fields from the named columns, as field=column pairs")
+
+	return c
+}
+
+// interchangeOptions returns the options used to convert todos between formats.
+func (a *app) interchangeOptions() interchange.Options {
+	return interchange.Options{
+		Calendar: a.opts.Calendar,
+		Now:      a.now(),
+	}
+}
+
+// openInput opens the file at path, or standard input when path is "-".
+func openInput(cmd *cobra.Command, path string) (io.Reader, func(), error) {
+	if path == "-" {
+		return cmd.InOrStdin(), func() {}, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	return f, func() { _ = f.Close() }, nil
+}
+
+// addItems validates the items and appends the valid ones to their lists,
+// saving each list that changed once. It returns the number of todos added and
+// a description of every item that was skipped.
+func addItems(a *app, store *oplog.Storage, items []interchange.Item) (int, []string, error) {
+	lists, err := a.syncLists(store)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	existing := make(map[string]struct{})
+	for _, l := range lists {
+		for _, todo := range l.Todos {
+			existing[todo.ID] = struct{}{}
+		}
+	}
+
+	var failures []string
+	changed := make(map[list.ID]bool)
+	added := 0
+
+	for _, item := range items {
+		todo := item.Todo
+		todo.Title = strings.TrimSpace(todo.Title)
+
+		if err := model.ValidateTitle(todo.Title); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", describeItem(item), err))
+			continue
+		}
+
+		if todo.ID == "" {
+			todo.ID = model.NewID(a.now())
+		}
+
+		if _, dup := existing[todo.ID]; dup {
+			failures = append(failures, fmt.Sprintf("%s: todo %s already exists", describeItem(item), todo.ID))
+			continue
+		}
+
+		target := lists[item.List]
+		if target == nil {
+			failures = append(failures, fmt.Sprintf("%s: unknown list %q", describeItem(item), item.List))
+			continue
+		}
+
+		scheduleItem(a.opts.Calendar, item.List, &todo)
+
+		target.Todos = append(target.Todos, todo)
+		existing[todo.ID] = struct{}{}
+		changed[item.List] = true
+		added++
+	}
+
+	store.Begin(oplog.SourceCLI)
+	for _, def := range list.Default() {
+		if !changed[def.ID] {
+			continue
+		}
+		if err := store.SaveList(def, lists[def.ID]); err != nil {
+			return 0, nil, fmt.Errorf("failed to save %s list: %w", def.Name, err)
+		}
+	}
+
+	if err := store.Commit(); err != nil {
+		return 0, nil, fmt.Errorf("failed to record changes: %w", err)
+	}
+
+	return added, failures, nil
+}
+
+// scheduleItem keeps the due date of a todo imported into the Today or
+// Tomorrow list. Automations give todos in those lists the due date they would
+// have had when they were created, so the todo is dated as if it had been
+// added to its list on the day that gives it the due date it was imported
+// with.
+func scheduleItem(cal calendar.Calendar, id list.ID, todo *model.Todo) {
+	if todo.DueDate == nil {
+		return
+	}
+
+	due := cal.Date(*todo.DueDate)
+	if derived := list.DefaultDueDate(cal, id, todo.CreatedAt); derived == nil || derived.Equal(due) {
+		return
+	}
+
+	added := due
+	if id == list.TomorrowID {
+		added = cal.AddDays(due, -1)
+	}
+
+	todo.CreatedAt = added.Add(cal.DayStartsAt())
+}
+
+func describeItem(item interchange.Item) string {
+	if item.Line > 0 {
+		return fmt.Sprintf("line %d", item.Line)
+	}
+
+	return fmt.Sprintf("%q", item.Todo.Title)
+}
+
+// printReport writes the data a conversion could not represent to w.
+func printReport(w io.Writer, report interchange.Report) {
+	for _, dropped := range report.Dropped {
+		_, _ = fmt.Fprintf(w, "dropped: %s\n", dropped)
+	}
+}
@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+	"github.com/unfunco/t/internal/gitstore"
+	"github.com/unfunco/t/internal/paths"
+)
+
+func newInitCommand(_ *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "init [directory]",
+		Short: "Create todo lists for a project.",
+		Long: heredoc.Doc(`
+			Create a .t directory to hold todo lists for a project, in the
+			given directory or the current one. Like git with .git, t looks
+			for a .t directory in the current directory and each of its
+			parents, and uses the nearest one instead of your global lists.
+			Use --global to reach your global lists from inside a project.
+
+			The lists can be committed alongside the project's code; the
+			history and backups kept by t are ignored.
+		`),
+		Example: heredoc.Doc(`
+			t init
+			t init ~/code/website
+			t --global list
+		`),
+		Args: cobra.MaximumNArgs(1),
+		ValidArgsFunction: func(*cobra.Command, []string, string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			return nil, cobra.ShellCompDirectiveFilterDirs
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) == 1 {
+				dir = args[0]
+			}
+
+			dataDir, err := filepath.Abs(filepath.Join(dir, paths.LocalDirName))
+			if err != nil {
+				return fmt.Errorf("failed to resolve %s: %w", dir, err)
+			}
+
+			if err := os.Mkdir(dataDir, 0o700); err != nil {
+				if errors.Is(err, os.ErrExist) {
+					return fmt.Errorf("todo lists already exist in %s", dataDir)
+				}
+				return fmt.Errorf("failed to create %s: %w", dataDir, err)
+			}
+
+			if err := gitstore.WriteIgnore(dataDir); err != nil {
+				return fmt.Errorf("failed to initialise %s: %w", dataDir, err)
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Created todo lists in %s\n", dataDir)
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,152 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/query"
+)
+
+func newListCommand(a *app) *cobra.Command {
+	var asJSON bool
+
+	c := &cobra.Command{
+		Use:   "list [query]",
+		Short: "List the todos matching a query.",
+		Long: heredoc.Doc(`
+			List every todo, or only those matching a query. Queries combine
+			terms with and, or and not, and group them with parentheses. Terms
+			written next to each other must all match.
+
+			Fields are compared with : or = for equality, != for inequality and
+			~ for a partial match. Dates can also be compared with <, <=, > and
+			>=, and are written as YYYY-MM-DD, today, tomorrow, yesterday or
+			relative to today such as +3d, -1w, +1m or +1y.
+
+			  list        today, tomorrow or todos
+			  title       the title of the todo
+			  description the description of the todo
+			  text        the title or the description
+			  tag         any of the todo's tags
+			  project     the project of the todo
+			  id          the ID, or the start of it
+			  due         the due date, or none
+			  created     the day the todo was added
+			  completed   the day the todo was completed, or none
+
+			The words done, open and overdue match todos in that state, and
+			any other word or quoted phrase matches todos whose title or
+			description contains it.
+		`),
+		Example: heredoc.Doc(`
+			t list
+			t list 'due < +3d and not done and tag:work'
+			t list 'list:today and title~standup'
+			t list overdue or '(tag:home and project:garden)'
+		`),
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			q, err := query.Parse(strings.Join(args, " "), a.opts.Calendar, a.now())
+			if err != nil {
+				return err
+			}
+
+			store, err := a.openStorage()
+			if err != nil {
+				return fmt.Errorf("failed to initialise storage: %w", err)
+			}
+
+			lists, err := a.syncLists(store)
+			if err != nil {
+				return err
+			}
+
+			matches := matchTodos(lists, q)
+			if asJSON {
+				return writeMatchesJSON(cmd.OutOrStdout(), matches)
+			}
+
+			if len(matches) == 0 {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No todos")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "ID\tLIST\tDUE\tTITLE")
+
+			for _, m := range matches {
+				due := "-"
+				if m.todo.DueDate != nil {
+					due = a.opts.Calendar.Date(*m.todo.DueDate).Format(time.DateOnly)
+				}
+
+				check := "[ ]"
+				if m.todo.Completed {
+					check = "[x]"
+				}
+
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s %s\n", m.todo.ID, m.def.ID, due, check, m.todo.Title)
+			}
+
+			return w.Flush()
+		},
+	}
+
+	c.Flags().BoolVar(&asJSON, "json", false, "Write the todos as a JSON array")
+
+	return c
+}
+
+// listedTodo is the JSON representation of a todo along with its list.
+type listedTodo struct {
+	List list.ID `json:"list"`
+	model.Todo
+}
+
+func writeMatchesJSON(w io.Writer, matches []match) error {
+	todos := make([]listedTodo, 0, len(matches))
+	for _, m := range matches {
+		todos = append(todos, listedTodo{List: m.def.ID, Todo: *m.todo})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(todos)
+}
+
+// match is a todo found by a query along with the list it belongs to.
+type match struct {
+	def  list.Definition
+	todo *model.Todo
+}
+
+// matchTodos returns the todos matching q, in list order. The todos point
+// into lists so that they can be changed in place.
+func matchTodos(lists map[list.ID]*model.TodoList, q *query.Query) []match {
+	var matches []match
+	for _, def := range list.Default() {
+		l := lists[def.ID]
+		if l == nil {
+			continue
+		}
+		for i := range l.Todos {
+			if q.Match(l.Todos[i], def.ID) {
+				matches = append(matches, match{def: def, todo: &l.Todos[i]})
+			}
+		}
+	}
+
+	return matches
+}
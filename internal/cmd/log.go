@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+	"github.com/unfunco/t/internal/oplog"
+)
+
+func newLogCommand(a *app) *cobra.Command {
+	var limit int
+
+	c := &cobra.Command{
+		Use:   "log [id]",
+		Short: "Show the history of changes to your todos.",
+		Long: heredoc.Doc(`
+			Show the most recent changes to your todos, newest first. Pass a todo
+			ID, or the start of one, to show the history of a single todo.
+		`),
+		Example: heredoc.Doc(`
+			t log
+			t log 0193a5c2
+			t log --limit 50
+		`),
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: a.completeTodoIDs(true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dataDir, err := a.dataDir()
+			if err != nil {
+				return err
+			}
+
+			c, err := a.cipher(dataDir)
+			if err != nil {
+				return err
+			}
+
+			events, err := oplog.Read(dataDir, c)
+			if err != nil {
+				return fmt.Errorf("failed to read log: %w", err)
+			}
+
+			if len(args) == 1 {
+				events = filterEvents(events, args[0])
+			}
+
+			if len(events) == 0 {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No history")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "TIME\tEVENT\tTODO\tLIST\tSOURCE")
+
+			shown := 0
+			for i := len(events) - 1; i >= 0 && (limit <= 0 || shown < limit); i-- {
+				e := events[i]
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+					e.Time.In(a.opts.Calendar.Location()).Format(time.DateTime),
+					e.Kind,
+					e.Title(),
+					describeLists(e),
+					e.Source,
+				)
+				shown++
+			}
+
+			return w.Flush()
+		},
+	}
+
+	c.Flags().IntVarP(&limit, "limit", "n", 20, "Maximum number of events to show (0 for all)")
+
+	return c
+}
+
+func filterEvents(events []oplog.Event, id string) []oplog.Event {
+	var out []oplog.Event
+	for _, e := range events {
+		if strings.HasPrefix(e.TodoID, id) {
+			out = append(out, e)
+		}
+	}
+
+	return out
+}
+
+func describeLists(e oplog.Event) string {
+	switch {
+	case e.From != "" && e.To != "" && e.From != e.To:
+		return fmt.Sprintf("%s → %s", e.From, e.To)
+	case e.To != "":
+		return string(e.To)
+	default:
+		return string(e.From)
+	}
+}
@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+)
+
+func newMoveCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "move id list",
+		Short: "Move a todo to another list.",
+		Long: heredoc.Doc(`
+			Move a todo to another list, giving it the due date that list
+			implies. The ID can be shortened to any prefix that matches a single
+			todo.
+		`),
+		Example: heredoc.Doc(`
+			t move 0193a5c2 tomorrow
+		`),
+		Args: cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			if len(args) == 1 {
+				return completeListIDs(cmd, args, toComplete)
+			}
+			return a.completeTodoIDs(false)(cmd, args, toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, ok := list.Lookup(list.ID(args[1]))
+			if !ok {
+				return fmt.Errorf("unknown list %q, expected one of %s", args[1], strings.Join(listIDs(), ", "))
+			}
+
+			store, err := a.openStorage()
+			if err != nil {
+				return fmt.Errorf("failed to initialise storage: %w", err)
+			}
+
+			lists, err := a.syncLists(store)
+			if err != nil {
+				return err
+			}
+
+			matches, err := findTodos(lists, args[:1])
+			if err != nil {
+				return err
+			}
+			m := matches[0]
+
+			if m.def.ID == target.ID {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Already in %s: %s\n", target.Name, m.todo.Title)
+				return nil
+			}
+
+			todo := *m.todo
+			todo.SetDueDate(list.DefaultDueDate(a.opts.Calendar, target.ID, a.now()))
+
+			source := lists[m.def.ID]
+			source.Todos = slices.DeleteFunc(source.Todos, func(t model.Todo) bool { return t.ID == todo.ID })
+			lists[target.ID].Todos = append(lists[target.ID].Todos, todo)
+
+			if err := saveOperation(store, lists, m.def, target); err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Moved to %s: %s\n", target.Name, todo.Title)
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,184 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+	"github.com/unfunco/t/internal/config"
+	"github.com/unfunco/t/internal/paths"
+)
+
+func newProfileCommand(a *app) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage separate sets of todo lists.",
+		Long: heredoc.Doc(`
+			Profiles keep separate sets of todo lists, such as personal and
+			work todos, each in its own data directory and optionally with
+			its own git storage and theme, configured in config.json:
+
+			  "profiles": {
+			    "work": {
+			      "data_dir": "/Users/me/Work/todos",
+			      "git": {"enabled": true, "remote": "git@example.com:me/work.git"}
+			    }
+			  }
+
+			The active profile is used unless another is selected with the
+			--profile flag or the T_PROFILE environment variable.
+		`),
+		Args: cobra.NoArgs,
+		// Profiles are managed without applying one, so that a profile that
+		// no longer exists can be switched away from.
+		PersistentPreRunE: func(*cobra.Command, []string) error {
+			return nil
+		},
+	}
+
+	c.AddCommand(newProfileAddCommand())
+	c.AddCommand(newProfileListCommand(a))
+	c.AddCommand(newProfileSwitchCommand(a))
+
+	return c
+}
+
+func newProfileListCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List profiles, marking the active one.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			active := a.selectedProfile()
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "  PROFILE\tDATA DIRECTORY")
+			for _, name := range config.ProfileNames(a.opts.Profiles) {
+				var (
+					dataDir string
+					err     error
+				)
+				if name == config.DefaultProfile {
+					dataDir, err = paths.DefaultDataDir()
+				} else {
+					dataDir, err = a.opts.Profiles[name].DataDirFor(name)
+				}
+				if err != nil {
+					return fmt.Errorf("failed to get data directory for profile %s: %w", name, err)
+				}
+
+				marker := " "
+				if name == active {
+					marker = "*"
+				}
+				_, _ = fmt.Fprintf(w, "%s %s\t%s\n", marker, name, dataDir)
+			}
+
+			return w.Flush()
+		},
+	}
+}
+
+func newProfileAddCommand() *cobra.Command {
+	var dataDir string
+
+	c := &cobra.Command{
+		Use:   "add <name> [--data-dir directory]",
+		Short: "Add a profile.",
+		Long: heredoc.Doc(`
+			Add a profile to config.json. Its lists are kept in the given
+			data directory, or in a directory named after the profile beneath
+			your data directory.
+		`),
+		Example: heredoc.Doc(`
+			t profile add work
+			t profile add work --data-dir ~/Work/todos
+		`),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			var profile config.Profile
+			if dataDir != "" {
+				abs, err := filepath.Abs(dataDir)
+				if err != nil {
+					return fmt.Errorf("failed to resolve %s: %w", dataDir, err)
+				}
+				profile.DataDir = abs
+			}
+
+			configDir, err := paths.DefaultConfigDir()
+			if err != nil {
+				return fmt.Errorf("failed to get config directory: %w", err)
+			}
+
+			if err := config.AddProfile(configDir, name, profile); err != nil {
+				return fmt.Errorf("failed to add profile: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Added profile %s\n", name)
+
+			return nil
+		},
+	}
+
+	c.Flags().StringVar(&dataDir, "data-dir", "", "Keep the profile's lists in this directory")
+	_ = c.MarkFlagDirname("data-dir")
+
+	return c
+}
+
+func newProfileSwitchCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "switch <name>",
+		Short: "Make a profile the active one.",
+		Long: heredoc.Doc(`
+			Make the named profile the one used when no other is selected
+			with --profile or T_PROFILE. Switch to the default profile to
+			return to your original lists.
+		`),
+		Example: heredoc.Doc(`
+			t profile switch work
+			t profile switch default
+		`),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: a.completeProfiles,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configDir, err := paths.DefaultConfigDir()
+			if err != nil {
+				return fmt.Errorf("failed to get config directory: %w", err)
+			}
+
+			if err := config.SwitchProfile(configDir, args[0]); err != nil {
+				return fmt.Errorf("failed to switch profile: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Switched to profile %s\n", args[0])
+
+			return nil
+		},
+	}
+}
+
+// completeProfiles completes the names of the configured profiles.
+func (a *app) completeProfiles(_ *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []cobra.Completion
+	for _, name := range config.ProfileNames(a.opts.Profiles) {
+		if strings.HasPrefix(name, toComplete) {
+			completions = append(completions, name)
+		}
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
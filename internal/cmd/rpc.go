@@ -0,0 +1,290 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+	"github.com/unfunco/t/internal/rpc"
+	"github.com/unfunco/t/internal/version"
+)
+
+func newRPCCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rpc",
+		Short: "Serve your todos over JSON-RPC on standard input and output.",
+		Long: heredoc.Doc(`
+			Answer JSON-RPC 2.0 requests read from standard input, one per line,
+			for editor assistants and scripts. The server speaks the stdio
+			transport of the Model Context Protocol, so it can be added to any
+			MCP client as a command.
+
+			The list, search, add, complete, edit and move methods can be called
+			directly or as MCP tools. Each runs the matching t command, so they
+			validate their arguments and record their changes exactly as the
+			command line does.
+		`),
+		Example: heredoc.Doc(`
+			echo '{"jsonrpc":"2.0","id":1,"method":"list","params":{"list":"today"}}' | t rpc
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			server := rpc.NewServer("t", version.SemanticVersion, a.rpcTools())
+			return server.Serve(cmd.Context(), cmd.InOrStdin(), cmd.OutOrStdout())
+		},
+	}
+}
+
+// run runs t in-process with the given arguments, as though they were typed
+// on the command line after the flags t rpc was run with, and returns what it
+// writes to standard output.
+func (a *app) run(ctx context.Context, args ...string) (string, error) {
+	inner := &app{opts: a.opts, global: a.global, profileFlag: a.profileFlag}
+	inner.opts.Clock = a.clock
+
+	var out, errOut bytes.Buffer
+	c := newTCommand(strings.NewReader(""), &out, &errOut, inner)
+	c.SilenceErrors = true
+	c.SilenceUsage = true
+	c.SetArgs(args)
+
+	if err := c.ExecuteContext(ctx); err != nil {
+		if detail := strings.TrimSpace(errOut.String()); detail != "" {
+			return "", fmt.Errorf("%w\n%s", err, detail)
+		}
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+// runText runs t and returns its output as the result of a tool.
+func (a *app) runText(ctx context.Context, args ...string) (any, error) {
+	out, err := a.run(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.TrimSpace(out), nil
+}
+
+// listTodos runs t list with a query and returns the matching todos as JSON.
+func (a *app) listTodos(ctx context.Context, query string) (any, error) {
+	out, err := a.run(ctx, "list", "--json", "--", query)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(out), nil
+}
+
+// rpcTools returns the tools offered by t rpc.
+func (a *app) rpcTools() []rpc.Tool {
+	return []rpc.Tool{
+		{
+			Name:        "list",
+			Description: "List todos, optionally only those in one list or matching a query written as for t list, such as 'due < +3d and not done and tag:work'.",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"list": {"type": "string", "enum": ["today", "tomorrow", "todos"], "description": "Only list the todos in this list."},
+					"query": {"type": "string", "description": "Only list the todos matching this query."}
+				},
+				"additionalProperties": false
+			}`),
+			Call: func(ctx context.Context, raw json.RawMessage) (any, error) {
+				var args struct {
+					List  string `json:"list"`
+					Query string `json:"query"`
+				}
+				if err := rpc.DecodeArgs(raw, &args); err != nil {
+					return nil, err
+				}
+
+				query := args.Query
+				if args.List != "" {
+					query = "list:" + quoteQuery(args.List)
+					if args.Query != "" {
+						query += " and (" + args.Query + ")"
+					}
+				}
+
+				return a.listTodos(ctx, query)
+			},
+		},
+		{
+			Name:        "search",
+			Description: "Find the todos whose title or description contains the given text, ignoring case.",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"text": {"type": "string", "description": "The text to look for."}
+				},
+				"required": ["text"],
+				"additionalProperties": false
+			}`),
+			Call: func(ctx context.Context, raw json.RawMessage) (any, error) {
+				var args struct {
+					Text string `json:"text"`
+				}
+				if err := rpc.DecodeArgs(raw, &args); err != nil {
+					return nil, err
+				}
+				if args.Text == "" {
+					return nil, rpc.InvalidParams("text is required")
+				}
+
+				return a.listTodos(ctx, "text~"+quoteQuery(args.Text))
+			},
+		},
+		{
+			Name:        "add",
+			Description: "Add a todo. Todos with a due date are added to the list for that day unless a list is given.",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"title": {"type": "string", "maxLength": 100},
+					"description": {"type": "string"},
+					"list": {"type": "string", "enum": ["today", "tomorrow", "todos"]},
+					"due": {"type": "string", "description": "today, tomorrow or YYYY-MM-DD."}
+				},
+				"required": ["title"],
+				"additionalProperties": false
+			}`),
+			Call: func(ctx context.Context, raw json.RawMessage) (any, error) {
+				var args struct {
+					Title       string `json:"title"`
+					Description string `json:"description"`
+					List        string `json:"list"`
+					Due         string `json:"due"`
+				}
+				if err := rpc.DecodeArgs(raw, &args); err != nil {
+					return nil, err
+				}
+
+				cmdArgs := []string{"add"}
+				cmdArgs = appendFlag(cmdArgs, "description", args.Description)
+				cmdArgs = appendFlag(cmdArgs, "list", args.List)
+				cmdArgs = appendFlag(cmdArgs, "due", args.Due)
+
+				// A title of - would read titles from standard input.
+				if strings.TrimSpace(args.Title) == "-" {
+					return nil, rpc.InvalidParams("invalid title %q", args.Title)
+				}
+
+				return a.runText(ctx, append(cmdArgs, "--", args.Title)...)
+			},
+		},
+		{
+			Name:        "complete",
+			Description: "Complete the todos with the given IDs, or every open todo matching a query. IDs can be shortened to any unique prefix.",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"ids": {"type": "array", "items": {"type": "string"}},
+					"where": {"type": "string", "description": "A query written as for t list."}
+				},
+				"additionalProperties": false
+			}`),
+			Call: func(ctx context.Context, raw json.RawMessage) (any, error) {
+				var args struct {
+					IDs   []string `json:"ids"`
+					Where string   `json:"where"`
+				}
+				if err := rpc.DecodeArgs(raw, &args); err != nil {
+					return nil, err
+				}
+
+				cmdArgs := appendFlag([]string{"done"}, "where", args.Where)
+
+				return a.runText(ctx, append(append(cmdArgs, "--"), args.IDs...)...)
+			},
+		},
+		{
+			Name:        "edit",
+			Description: "Change the title, description or due date of a todo. Only the fields given are changed.",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"id": {"type": "string"},
+					"title": {"type": "string", "maxLength": 100},
+					"description": {"type": "string"},
+					"due": {"type": "string", "description": "today, tomorrow, YYYY-MM-DD or none."}
+				},
+				"required": ["id"],
+				"additionalProperties": false
+			}`),
+			Call: func(ctx context.Context, raw json.RawMessage) (any, error) {
+				var args struct {
+					ID          string  `json:"id"`
+					Title       *string `json:"title"`
+					Description *string `json:"description"`
+					Due         *string `json:"due"`
+				}
+				if err := rpc.DecodeArgs(raw, &args); err != nil {
+					return nil, err
+				}
+
+				// Flags are passed with = so that empty values still count as
+				// given, which is how a description is cleared.
+				cmdArgs := []string{"edit"}
+				if args.Title != nil {
+					cmdArgs = append(cmdArgs, "--title="+*args.Title)
+				}
+				if args.Description != nil {
+					cmdArgs = append(cmdArgs, "--description="+*args.Description)
+				}
+				if args.Due != nil {
+					cmdArgs = append(cmdArgs, "--due="+*args.Due)
+				}
+
+				return a.runText(ctx, append(cmdArgs, "--", args.ID)...)
+			},
+		},
+		{
+			Name:        "move",
+			Description: "Move a todo to another list, giving it the due date that list implies.",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"id": {"type": "string"},
+					"list": {"type": "string", "enum": ["today", "tomorrow", "todos"]}
+				},
+				"required": ["id", "list"],
+				"additionalProperties": false
+			}`),
+			Call: func(ctx context.Context, raw json.RawMessage) (any, error) {
+				var args struct {
+					ID   string `json:"id"`
+					List string `json:"list"`
+				}
+				if err := rpc.DecodeArgs(raw, &args); err != nil {
+					return nil, err
+				}
+
+				return a.runText(ctx, "move", "--", args.ID, args.List)
+			},
+		},
+	}
+}
+
+// appendFlag appends --name=value to args when value is set.
+func appendFlag(args []string, name, value string) []string {
+	if value == "" {
+		return args
+	}
+
+	return append(args, "--"+name+"="+value)
+}
+
+// quoteQuery quotes a value for use in a query.
+func quoteQuery(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/server"
+)
+
+// TokenEnvVar names the environment variable holding the token that t serve
+// requires of API requests.
+const TokenEnvVar = "T_SERVE_TOKEN"
+
+func newServeCommand(a *app) *cobra.Command {
+	var (
+		listen string
+		token  string
+	)
+
+	c := &cobra.Command{
+		Use:   "serve [--listen address]",
+		Short: "Serve your todos over a local HTTP API.",
+		Long: heredoc.Doc(`
+			Serve the todo lists over an HTTP/JSON API, for dashboards and
+			launchers built on top of t. Listen on a TCP address such as
+			127.0.0.1:7878, or on a Unix socket written as unix:PATH.
+
+			Requests must carry a bearer token, which is taken from --token or
+			T_SERVE_TOKEN, or generated and printed when the server starts.
+			Responses describing a list carry an ETag; send it back in If-Match
+			to have a change refused if the list has changed since.
+
+			The OpenAPI description of the API is served at /openapi.json.
+		`),
+		Example: heredoc.Doc(`
+			t serve
+			t serve --listen unix:$XDG_RUNTIME_DIR/t.sock
+			curl -H "Authorization: Bearer $T_SERVE_TOKEN" http://127.0.0.1:7878/lists/today
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if token == "" {
+				token = os.Getenv(TokenEnvVar)
+			}
+			generated := token == ""
+			if generated {
+				token = rand.Text()
+			}
+
+			handler, err := a.newServer(token)
+			if err != nil {
+				return err
+			}
+
+			ln, url, err := listenOn(listen)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				_ = srv.Shutdown(shutdownCtx)
+			}()
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Serving todos on %s\n", url)
+			if generated {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Token: %s\n", token)
+			}
+
+			if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("failed to serve: %w", err)
+			}
+
+			return nil
+		},
+	}
+
+	c.Flags().StringVar(&listen, "listen", "127.0.0.1:7878", "TCP address, or unix:PATH for a Unix socket")
+	c.Flags().StringVar(&token, "token", "", "Token that requests must present (default $"+TokenEnvVar+" or generated)")
+
+	return c
+}
+
+// newServer returns the API handler for the lists of this invocation. The
+// storage is opened for every request, and the lists are prepared as they are
+// for any command.
+func (a *app) newServer(token string) (*server.Server, error) {
+	// Check the storage can be opened before serving, rather than failing
+	// every request.
+	if _, err := a.openStorage(); err != nil {
+		return nil, fmt.Errorf("failed to initialise storage: %w", err)
+	}
+
+	return server.New(server.Config{
+		Open: func() (server.Store, error) {
+			return a.openStorage()
+		},
+		Lists: func(store server.Store) (map[list.ID]*model.TodoList, error) {
+			return a.syncLists(store)
+		},
+		Calendar: a.opts.Calendar,
+		Clock:    a.clock,
+		Token:    token,
+	})
+}
+
+// listenOn listens on a TCP address, or on a Unix socket for addresses
+// written as unix:PATH. It returns the listener along with a description of
+// the address for people to connect to.
+func listenOn(address string) (net.Listener, string, error) {
+	path, isSocket := strings.CutPrefix(address, "unix:")
+	if !isSocket {
+		ln, err := net.Listen("tcp", address)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to listen on %s: %w", address, err)
+		}
+		return ln, "http://" + ln.Addr().String(), nil
+	}
+
+	// A socket left behind by a server that did not shut down cleanly would
+	// prevent listening again.
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, "", fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = ln.Close()
+		return nil, "", fmt.Errorf("failed to set permissions on %s: %w", path, err)
+	}
+
+	return ln, address, nil
+}
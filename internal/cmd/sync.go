@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+	"github.com/unfunco/t/internal/gitstore"
+)
+
+func newSyncCommand(a *app) *cobra.Command {
+	var remote string
+
+	c := &cobra.Command{
+		Use:   "sync [--remote url]",
+		Short: "Pull and push your todo lists with a git remote.",
+		Long: heredoc.Doc(`
+			Synchronise the git repository holding your todo lists with the
+			configured remote. Changes on the remote are pulled in, and local
+			changes are pushed to it. When the lists changed in both places
+			they are merged todo by todo, keeping the changes made on each
+			side; if both changed the same field of a todo, the local change
+			wins.
+
+			Git storage is enabled in config.json:
+
+			  "git": {"enabled": true, "remote": "git@example.com:me/todos.git"}
+
+			The remote can be any URL git understands, or the path to a
+			repository such as a bare repository on a mounted drive.
+		`),
+		Example: heredoc.Doc(`
+			t sync
+			t sync --remote ~/Dropbox/todos.git
+		`),
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if !a.opts.Git.Enabled {
+				return errors.New(`git storage is not enabled, set "git": {"enabled": true} in config.json`)
+			}
+
+			dataDir, err := a.dataDir()
+			if err != nil {
+				return err
+			}
+
+			if isProjectDir(dataDir) {
+				return fmt.Errorf("the todo lists in %s belong to a project, commit them with the project's git repository instead", dataDir)
+			}
+
+			c, err := a.cipher(dataDir)
+			if err != nil {
+				return err
+			}
+
+			store, err := a.openGit(dataDir, c)
+			if err != nil {
+				return fmt.Errorf("failed to initialise storage: %w", err)
+			}
+
+			if remote != "" {
+				store.SetRemote(remote)
+			}
+
+			result, err := store.Sync(cmd.Context())
+			if errors.Is(err, gitstore.ErrNoRemote) {
+				return errors.New(`no remote to sync with, use --remote or set "remote" in the git section of config.json`)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to sync: %w", err)
+			}
+
+			out := cmd.OutOrStdout()
+			switch {
+			case result.Merged:
+				_, _ = fmt.Fprintf(out, "Merged changes with %s\n", store.Remote())
+			case result.Pulled:
+				_, _ = fmt.Fprintf(out, "Pulled changes from %s\n", store.Remote())
+			case result.Pushed:
+				_, _ = fmt.Fprintf(out, "Pushed changes to %s\n", store.Remote())
+			default:
+				_, _ = fmt.Fprintln(out, "Already up to date")
+			}
+
+			return nil
+		},
+	}
+
+	c.Flags().StringVar(&remote, "remote", "", "Sync with this remote instead of the configured one")
+
+	return c
+}
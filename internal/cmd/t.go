@@ -4,32 +4,59 @@
 package cmd
 
 import (
-	"errors"
 	"fmt"
 	"io"
 	"os"
-	"strings"
-	"time"
-	"unicode/utf8"
 
 	"github.com/MakeNowJust/heredoc/v2"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
-	"github.com/unfunco/t/internal/automation"
+	"github.com/unfunco/t/internal/backup"
+	"github.com/unfunco/t/internal/calendar"
+	"github.com/unfunco/t/internal/clock"
+	"github.com/unfunco/t/internal/config"
+	"github.com/unfunco/t/internal/crypt"
+	"github.com/unfunco/t/internal/gitstore"
+	"github.com/unfunco/t/internal/hook"
 	"github.com/unfunco/t/internal/list"
 	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/oplog"
 	"github.com/unfunco/t/internal/storage"
 	"github.com/unfunco/t/internal/theme"
 	"github.com/unfunco/t/internal/tui"
 	"github.com/unfunco/t/internal/version"
+	"github.com/unfunco/t/internal/watch"
 )
 
-const titleCharLimit = 100
-
-var (
-	ErrAmbiguousDateFlags = errors.New("only one of --today and --tomorrow may be specified")
-	ErrEmptyTitle         = errors.New("todo title cannot be blank")
-)
+// ErrEmptyTitle is returned when a todo title is blank.
+var ErrEmptyTitle = model.ErrEmptyTitle
+
+// Options holds the settings shared by the t command and its subcommands.
+type Options struct {
+	// Theme styles the interactive interface.
+	Theme theme.Theme
+	// Calendar decides which day todos are scheduled for.
+	Calendar calendar.Calendar
+	// Clock provides the current time. Defaults to the system clock, and can
+	// be pinned with the T_NOW environment variable or the --now flag.
+	Clock clock.Clock
+	// Backup controls automatic snapshots of the data directory.
+	Backup backup.Config
+	// Git versions the data directory in a git repository when enabled.
+	Git gitstore.Config
+	// Encryption locates the key used to read an encrypted data directory.
+	Encryption crypt.Config
+	// Profile is the profile used when none is selected with --profile or
+	// the T_PROFILE environment variable.
+	Profile string
+	// Profiles holds the named profiles that can be selected.
+	Profiles map[string]config.Profile
+	// Hooks lists the executables run when todos change.
+	Hooks hook.Config
+	// DarkBackground reports whether the terminal has a dark background, and
+	// picks the palette of a profile's theme.
+	DarkBackground bool
+}
 
 // NewDefaultTCommandWithTheme returns a new t command using the provided theme
 // and configured with the standard IO file descriptors.
@@ -37,6 +64,12 @@ func NewDefaultTCommandWithTheme(th theme.Theme) *cobra.Command {
 	return NewTCommandWithTheme(os.Stdin, os.Stdout, os.Stderr, th)
 }
 
+// NewDefaultTCommandWithOptions returns a new t command using the provided
+// options and configured with the standard IO file descriptors.
+func NewDefaultTCommandWithOptions(opts Options) *cobra.Command {
+	return NewTCommandWithOptions(os.Stdin, os.Stdout, os.Stderr, opts)
+}
+
 // NewTCommand returns a new t command configured with the given input, output,
 // and error file descriptors.
 func NewTCommand(in io.Reader, out, errOut io.Writer) *cobra.Command {
@@ -46,11 +79,27 @@ func NewTCommand(in io.Reader, out, errOut io.Writer) *cobra.Command {
 // NewTCommandWithTheme returns a new t command configured with the provided
 // input, output, error descriptors and theme.
 func NewTCommandWithTheme(in io.Reader, out, errOut io.Writer, th theme.Theme) *cobra.Command {
+	return NewTCommandWithOptions(in, out, errOut, Options{Theme: th})
+}
+
+// NewTCommandWithOptions returns a new t command configured with the provided
+// input, output, error descriptors and options.
+func NewTCommandWithOptions(in io.Reader, out, errOut io.Writer, opts Options) *cobra.Command {
+	return newTCommand(in, out, errOut, &app{opts: opts})
+}
+
+// newTCommand returns a new t command for a. The --global and --profile flags
+// default to those already selected in a, so that t can be run again
+// in-process with the lists it was started with.
+func newTCommand(in io.Reader, out, errOut io.Writer, a *app) *cobra.Command {
 	var (
-		today    bool
-		tomorrow bool
+		flags   addFlags
+		nowFlag string
 	)
 
+	opts := a.opts
+	a.errOut = errOut
+
 	t := &cobra.Command{
 		Use:   "t [title] [--flags]",
 		Short: "Manage your todo lists in the CLI.",
@@ -59,8 +108,9 @@ func NewTCommandWithTheme(in io.Reader, out, errOut io.Writer, th theme.Theme) *
 			Add new todos, or launch an interactive interface to view and manage
 			your todos.
 		`),
-		Args:    cobra.MaximumNArgs(1),
-		Version: version.SemanticVersion,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: cobra.NoFileCompletions,
+		Version:           version.SemanticVersion,
 		Annotations: map[string]string{
 			"versionInfo": fmt.Sprintf(
 				"t %s (%s)",
@@ -73,80 +123,86 @@ func NewTCommandWithTheme(in io.Reader, out, errOut io.Writer, th theme.Theme) *
 			t "Do something"
 			t "Do something today" --today
 			t "Do something tomorrow" --tomorrow
+			t "Do something" -d "With a description" --due 2025-01-31
+			t add --file list.txt
 
 			# Open the interactive interface.
 			t
 		`),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			if today && tomorrow {
-				return ErrAmbiguousDateFlags
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			c, err := resolveClock(opts.Clock, nowFlag)
+			if err != nil {
+				return err
 			}
 
+			a.clock = c
+
+			return a.useProfile(a.selectedProfile())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
 			// Launch the TUI if no title argument is provided.
 			if len(args) == 0 {
-				store, err := storage.NewFileStorage()
+				store, err := a.openStorage()
 				if err != nil {
 					return fmt.Errorf("failed to initialise storage: %w", err)
 				}
 
-				lists, err := automation.Sync(store, time.Now())
+				lists, err := a.syncLists(store)
 				if err != nil {
-					return fmt.Errorf("failed to prepare lists: %w", err)
+					return err
+				}
+
+				tuiOpts := []tui.Option{
+					tui.WithCalendar(a.opts.Calendar),
+					tui.WithClock(a.clock),
+					tui.WithStore(a.storeName(store.DataDir())),
+					tui.WithRollover(a.rolloverLists(store)),
+				}
+
+				// Live reload is a convenience, so the TUI still runs on
+				// systems where the data directory cannot be watched.
+				w, err := watch.New(store.DataDir())
+				if err == nil {
+					tuiOpts = append(tuiOpts, tui.WithWatch(watchLists(w, store)))
 				}
 
 				m := tui.New(
-					th,
+					a.opts.Theme,
 					lists[list.TodayID],
 					lists[list.TomorrowID],
 					lists[list.TodosID],
+					tuiOpts...,
 				)
 				p := tea.NewProgram(&m)
 
 				tuiModel, err := p.Run()
+				if w != nil {
+					_ = w.Close()
+				}
 				if err != nil {
 					return fmt.Errorf("error running TUI: %w", err)
 				}
 
 				if m, ok := tuiModel.(*tui.Model); ok && m.WasSubmitted() {
+					store.Begin(oplog.SourceTUI)
 					if err := saveLists(store, m); err != nil {
 						return err
 					}
+					if err := store.Commit(); err != nil {
+						return fmt.Errorf("failed to record changes: %w", err)
+					}
 				}
 
 				return nil
 			}
 
-			title := strings.TrimSpace(args[0])
-			if err := validateTitle(title); err != nil {
-				return err
-			}
-
-			store, err := storage.NewFileStorage()
+			def, due, err := flags.target(a.interchangeOptions())
 			if err != nil {
-				return fmt.Errorf("failed to initialise storage: %w", err)
-			}
-
-			if _, err := automation.Sync(store, time.Now()); err != nil {
-				return fmt.Errorf("failed to prepare lists: %w", err)
-			}
-
-			var def list.Definition
-			switch {
-			case today:
-				def = list.Today()
-			case tomorrow:
-				def = list.Tomorrow()
-			default:
-				def = list.Todos()
-			}
-
-			todo := model.NewTodo(title, "", list.DefaultDueDate(def.ID, time.Now()))
-
-			if err := appendToList(store, def, &todo); err != nil {
 				return err
 			}
 
-			return nil
+			_, err = a.addTitle(args[0], flags.description, def, due)
+			return err
 		},
 	}
 
@@ -154,17 +210,70 @@ func NewTCommandWithTheme(in io.Reader, out, errOut io.Writer, th theme.Theme) *
 	t.SetOut(out)
 	t.SetErr(errOut)
 
-	t.CompletionOptions.DisableDefaultCmd = true
 	t.SetHelpCommand(&cobra.Command{
 		Hidden: true,
 	})
 
-	t.Flags().BoolVar(&today, "today", false, "Add a todo for today")
-	t.Flags().BoolVar(&tomorrow, "tomorrow", false, "Add a todo for tomorrow")
+	flags.register(t)
+
+	t.AddCommand(newAddCommand(a))
+	t.AddCommand(newBackupCommand(a))
+	t.AddCommand(newDecryptCommand(a))
+	t.AddCommand(newDoneCommand(a))
+	t.AddCommand(newEditCommand(a))
+	t.AddCommand(newEncryptCommand(a))
+	t.AddCommand(newExportCommand(a))
+	t.AddCommand(newImportCommand(a))
+	t.AddCommand(newInitCommand(a))
+	t.AddCommand(newListCommand(a))
+	t.AddCommand(newLogCommand(a))
+	t.AddCommand(newMoveCommand(a))
+	t.AddCommand(newProfileCommand(a))
+	t.AddCommand(newRPCCommand(a))
+	t.AddCommand(newServeCommand(a))
+	t.AddCommand(newSyncCommand(a))
+	t.AddCommand(newUndoCommand(a))
+
+	t.PersistentFlags().BoolVar(&a.global, "global", a.global, "Use your global todo lists, ignoring any project .t directory")
+	t.PersistentFlags().StringVar(&a.profileFlag, "profile", a.profileFlag, "Use the todo lists of the named profile")
+	_ = t.RegisterFlagCompletionFunc("profile", a.completeProfiles)
+	t.PersistentFlags().StringVar(&nowFlag, "now", "", "Pin the current time (RFC 3339 or YYYY-MM-DD)")
+	_ = t.PersistentFlags().MarkHidden("now")
 
 	return t
 }
 
+// watchLists returns a command that waits for the lists to be saved outside
+// the TUI and loads them.
+func watchLists(w *watch.Watcher, store storage.Storage) tea.Cmd {
+	return func() tea.Msg {
+		if _, ok := <-w.Changes(); !ok {
+			return nil
+		}
+
+		lists := make(map[list.ID]*model.TodoList)
+		for _, def := range list.Default() {
+			l, err := store.LoadList(def)
+			if err != nil {
+				return tui.ListsChangedMsg{Err: fmt.Errorf("failed to load %s list: %w", def.Name, err)}
+			}
+			lists[def.ID] = l
+		}
+
+		return tui.ListsChangedMsg{Lists: lists}
+	}
+}
+
+// rolloverLists returns a command that applies the automations for a new day
+// to the stored lists, saving them as an operation of their own rather than
+// with the changes made in the TUI.
+func (a *app) rolloverLists(store *oplog.Storage) tea.Cmd {
+	return func() tea.Msg {
+		lists, err := a.applyAutomations(store)
+		return tui.RolloverMsg{Lists: lists, Err: err}
+	}
+}
+
 func saveLists(store storage.Storage, m *tui.Model) error {
 	for _, def := range list.Default() {
 		l := m.ListByID(def.ID)
@@ -193,15 +302,3 @@ func appendToList(store storage.Storage, def list.Definition, todo *model.Todo)
 
 	return nil
 }
-
-func validateTitle(t string) error {
-	if t == "" {
-		return ErrEmptyTitle
-	}
-
-	if utf8.RuneCountInString(t) > titleCharLimit {
-		return fmt.Errorf("todo title must be %d characters or fewer", titleCharLimit)
-	}
-
-	return nil
-}
@@ -4,11 +4,32 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/spf13/cobra"
+	"github.com/unfunco/t/internal/clock"
+	"github.com/unfunco/t/internal/config"
+	"github.com/unfunco/t/internal/crypt"
+	"github.com/unfunco/t/internal/gitstore"
+	"github.com/unfunco/t/internal/hook"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/oplog"
+	"github.com/unfunco/t/internal/storage"
+	"github.com/unfunco/t/internal/tui"
 )
 
 func TestNewTCommandRejectsBlankTitle(t *testing.T) {
@@ -27,7 +48,7 @@ func TestNewTCommandRejectsBlankTitle(t *testing.T) {
 
 func TestNewTCommandRejectsLongTitle(t *testing.T) {
 	cmd := NewTCommand(strings.NewReader(""), io.Discard, io.Discard)
-	longTitle := strings.Repeat("a", titleCharLimit+1)
+	longTitle := strings.Repeat("a", model.TitleCharLimit+1)
 	cmd.SetArgs([]string{longTitle})
 
 	err := cmd.Execute()
@@ -35,8 +56,1368 @@ func TestNewTCommandRejectsLongTitle(t *testing.T) {
 		t.Fatalf("expected an error for long title, got nil")
 	}
 
-	expected := fmt.Sprintf("todo title must be %d characters or fewer", titleCharLimit)
+	expected := fmt.Sprintf("todo title must be %d characters or fewer", model.TitleCharLimit)
 	if err.Error() != expected {
 		t.Fatalf("expected error %q, got %q", expected, err.Error())
 	}
 }
+
+func TestNewTCommandUsesFixedTime(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+
+	cmd := NewTCommand(strings.NewReader(""), io.Discard, io.Discard)
+	cmd.SetArgs([]string{"Pinned", "--today"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	store, err := storage.NewFileStorageWithDir(filepath.Join(dataHome, "t"))
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+
+	today, err := store.LoadList(list.Today())
+	if err != nil {
+		t.Fatalf("LoadList() error = %v", err)
+	}
+
+	if len(today.Todos) != 1 {
+		t.Fatalf("expected 1 todo, got %d", len(today.Todos))
+	}
+
+	want := time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC)
+	if !today.Todos[0].CreatedAt.Equal(want) {
+		t.Fatalf("expected creation time %v, got %v", want, today.Todos[0].CreatedAt)
+	}
+}
+
+func TestNewTCommandRejectsInvalidFixedTime(t *testing.T) {
+	cmd := NewTCommand(strings.NewReader(""), io.Discard, io.Discard)
+	cmd.SetArgs([]string{"Pinned", "--now", "soon"})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatalf("expected an error for an invalid --now value")
+	}
+}
+
+func TestBackupListAndRestore(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+
+	run := func(now string, args ...string) string {
+		t.Helper()
+
+		var out bytes.Buffer
+		cmd := NewTCommand(strings.NewReader(""), &out, io.Discard)
+		cmd.SetArgs(append(args, "--now", now))
+
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+
+		return out.String()
+	}
+
+	run("2025-01-01T09:00:00Z", "First")
+	run("2025-01-02T09:00:00Z", "Second")
+
+	if out := run("2025-01-02T10:00:00Z", "backup", "list"); !strings.Contains(out, "20250102T090000Z") {
+		t.Fatalf("expected snapshot in backup list, got %q", out)
+	}
+
+	run("2025-01-02T11:00:00Z", "backup", "restore", "20250102T090000Z")
+
+	store, err := storage.NewFileStorageWithDir(filepath.Join(dataHome, "t"))
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+
+	todos, err := store.LoadList(list.Todos())
+	if err != nil {
+		t.Fatalf("LoadList() error = %v", err)
+	}
+
+	if len(todos.Todos) != 1 || todos.Todos[0].Title != "First" {
+		t.Fatalf("expected restored list to hold only the first todo, got %+v", todos.Todos)
+	}
+}
+
+func TestLogAndUndo(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+
+	run := func(args ...string) string {
+		t.Helper()
+
+		var out bytes.Buffer
+		cmd := NewTCommand(strings.NewReader(""), &out, io.Discard)
+		cmd.SetArgs(args)
+
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+
+		return out.String()
+	}
+
+	run("Keep me")
+	run("Undo me")
+
+	if out := run("log"); !strings.Contains(out, "created") || !strings.Contains(out, "Undo me") {
+		t.Fatalf("expected log to show created todos, got %q", out)
+	}
+
+	if out := run("undo"); !strings.Contains(out, "Reverted created: Undo me") {
+		t.Fatalf("unexpected undo output %q", out)
+	}
+
+	store, err := storage.NewFileStorageWithDir(filepath.Join(dataHome, "t"))
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+
+	todos, err := store.LoadList(list.Todos())
+	if err != nil {
+		t.Fatalf("LoadList() error = %v", err)
+	}
+
+	if len(todos.Todos) != 1 || todos.Todos[0].Title != "Keep me" {
+		t.Fatalf("expected only the first todo to remain, got %+v", todos.Todos)
+	}
+}
+
+func TestImportAndExportTodoTxt(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+
+	run := func(stdin string, args ...string) (string, string, error) {
+		t.Helper()
+
+		var out, errOut bytes.Buffer
+		cmd := NewTCommand(strings.NewReader(stdin), &out, &errOut)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		return out.String(), errOut.String(), err
+	}
+
+	input := "(A) Call Mum @phone due:2025-01-02 id:call\n" +
+		"Plan trip +holiday\n" +
+		"2025-01-01 \n"
+
+	out, errOut, err := run(input, "import", "--format", "todotxt")
+	if err == nil {
+		t.Fatal("expected an error for the todo without a title")
+	}
+	if !strings.Contains(out, "Imported 2 of 3 todos") {
+		t.Fatalf("unexpected import output %q", out)
+	}
+	if !strings.Contains(errOut, "dropped: line 1: priority (A)") || !strings.Contains(errOut, "skipped: line 3") {
+		t.Fatalf("expected dropped and skipped data to be reported, got %q", errOut)
+	}
+
+	if _, errOut, err := run("Call Mum id:call\n", "import", "--format", "todotxt"); err == nil || !strings.Contains(errOut, "already exists") {
+		t.Fatalf("expected importing the same todo again to fail, got %v %q", err, errOut)
+	}
+
+	out, _, err = run("", "export", "--format", "todotxt")
+	if err != nil {
+		t.Fatalf("export error = %v", err)
+	}
+
+	if !strings.Contains(out, "2025-01-02 Call Mum @phone due:2025-01-02 id:call\n") {
+		t.Fatalf("expected the Today list to be exported first, got %q", out)
+	}
+	if strings.Count(out, "Plan trip +holiday") != 1 {
+		t.Fatalf("expected the Todos list to be exported once, got %q", out)
+	}
+}
+
+func TestImportKeepsDueDatesInTodayAndTomorrow(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+
+	run := func(stdin string, args ...string) (string, error) {
+		t.Helper()
+
+		var out bytes.Buffer
+		cmd := NewTCommand(strings.NewReader(stdin), &out, io.Discard)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		return out.String(), err
+	}
+
+	input := "2024-12-01 Water plants due:2025-01-03\n" +
+		"2024-12-01 Pay rent due:2024-12-30\n"
+	if _, err := run(input, "import", "--format", "todotxt"); err != nil {
+		t.Fatalf("import error = %v", err)
+	}
+
+	if out, _ := run("", "list", "list:tomorrow"); !strings.Contains(out, "Water plants") {
+		t.Fatalf("expected the todo due tomorrow to stay in Tomorrow, got %q", out)
+	}
+
+	out, err := run("", "export", "--format", "todotxt")
+	if err != nil {
+		t.Fatalf("export error = %v", err)
+	}
+	if !strings.Contains(out, "Water plants due:2025-01-03") || !strings.Contains(out, "Pay rent due:2024-12-30") {
+		t.Fatalf("expected the imported due dates to be kept, got %q", out)
+	}
+}
+
+func TestImportCSVReportsRowErrors(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+
+	input := "Task,Deadline\n" +
+		"Write report,2025-01-03\n" +
+		",2025-01-03\n" +
+		strings.Repeat("a", model.TitleCharLimit+1) + ",\n" +
+		"Book venue,soon\n"
+
+	var out, errOut bytes.Buffer
+	cmd := NewTCommand(strings.NewReader(input), &out, &errOut)
+	cmd.SetArgs([]string{"import", "--format", "csv", "--map", "title=Task,due_date=Deadline"})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error for the invalid rows")
+	}
+
+	if !strings.Contains(out.String(), "Imported 1 of 4 todos") {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+
+	for _, want := range []string{
+		`skipped: line 5: invalid due_date value "soon"`,
+		"skipped: line 3: " + ErrEmptyTitle.Error(),
+		"skipped: line 4: todo title must be",
+	} {
+		if !strings.Contains(errOut.String(), want) {
+			t.Fatalf("expected %q in %q", want, errOut.String())
+		}
+	}
+}
+
+func TestAddReadsTodosFromStdinAndFiles(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+
+	run := func(stdin string, args ...string) (string, string, error) {
+		t.Helper()
+
+		var out, errOut bytes.Buffer
+		cmd := NewTCommand(strings.NewReader(stdin), &out, &errOut)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		return out.String(), errOut.String(), err
+	}
+
+	input := "Buy milk @shop\n" +
+		"# Ignored\n" +
+		"\n" +
+		"Call Mum +family due:tomorrow\n" +
+		"Book venue due:someday\n" +
+		"@blank\n"
+
+	out, errOut, err := run(input, "add", "-", "--today")
+	if err == nil {
+		t.Fatal("expected an error for the invalid lines")
+	}
+	if !strings.Contains(out, "Added 2 of 4 todos") {
+		t.Fatalf("unexpected output %q", out)
+	}
+	if !strings.Contains(errOut, `skipped: line 5: invalid due date "someday"`) || !strings.Contains(errOut, "skipped: line 6: "+ErrEmptyTitle.Error()) {
+		t.Fatalf("expected per-line failures, got %q", errOut)
+	}
+
+	file := filepath.Join(t.TempDir(), "list.txt")
+	if err := os.WriteFile(file, []byte("Water plants\n"), 0o600); err != nil {
+		t.Fatalf("failed to write list: %v", err)
+	}
+	if _, _, err := run("", "add", "--file", file); err != nil {
+		t.Fatalf("add --file error = %v", err)
+	}
+
+	store, err := storage.NewFileStorageWithDir(filepath.Join(dataHome, "t"))
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+
+	want := map[list.Definition][]string{
+		list.Today():    {"Buy milk"},
+		list.Tomorrow(): {"Call Mum"},
+		list.Todos():    {"Water plants"},
+	}
+	for def, titles := range want {
+		l, err := store.LoadList(def)
+		if err != nil {
+			t.Fatalf("LoadList(%s) error = %v", def.Name, err)
+		}
+		if len(l.Todos) != len(titles) || l.Todos[0].Title != titles[0] {
+			t.Fatalf("unexpected %s list: %+v", def.Name, l.Todos)
+		}
+	}
+
+	if _, _, err := run("", "undo"); err != nil {
+		t.Fatalf("undo error = %v", err)
+	}
+	if out, _, _ := run("", "undo"); !strings.Contains(out, "Buy milk") || !strings.Contains(out, "Call Mum") {
+		t.Fatalf("expected the batch to be undone as one operation, got %q", out)
+	}
+}
+
+func TestNewTCommandAddsWithDescriptionListAndDue(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+
+	for _, args := range [][]string{
+		{"Due tomorrow", "--due", "2025-01-03", "-d", "With detail"},
+		{"Kept in todos", "--list", "todos", "--due", "tomorrow"},
+		{"add", "Added today", "--list", "today"},
+	} {
+		cmd := NewTCommand(strings.NewReader(""), io.Discard, io.Discard)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+	}
+
+	store, err := storage.NewFileStorageWithDir(filepath.Join(dataHome, "t"))
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+
+	tomorrow := time.Date(2025, time.January, 3, 0, 0, 0, 0, time.Local)
+	want := map[list.Definition]string{
+		list.Today():    "Added today",
+		list.Tomorrow(): "Due tomorrow",
+		list.Todos():    "Kept in todos",
+	}
+	for def, title := range want {
+		l, err := store.LoadList(def)
+		if err != nil {
+			t.Fatalf("LoadList(%s) error = %v", def.Name, err)
+		}
+		if len(l.Todos) != 1 || l.Todos[0].Title != title {
+			t.Fatalf("unexpected %s list: %+v", def.Name, l.Todos)
+		}
+		if def.ID != list.TodayID && (l.Todos[0].DueDate == nil || !l.Todos[0].DueDate.Equal(tomorrow)) {
+			t.Fatalf("expected %q to be due tomorrow, got %v", title, l.Todos[0].DueDate)
+		}
+	}
+
+	tomorrowList, _ := store.LoadList(list.Tomorrow())
+	if got := tomorrowList.Todos[0].Description; got != "With detail" {
+		t.Fatalf("expected description to be saved, got %q", got)
+	}
+}
+
+func TestNewTCommandRejectsConflictingListFlags(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	for _, args := range [][]string{
+		{"Title", "--today", "--tomorrow"},
+		{"Title", "--today", "--list", "todos"},
+		{"Title", "--tomorrow", "--due", "2025-01-03"},
+		{"Title", "--list", "someday"},
+		{"add", "-", "--description", "Shared"},
+	} {
+		cmd := NewTCommand(strings.NewReader(""), io.Discard, io.Discard)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err == nil {
+			t.Fatalf("Execute(%v) expected an error", args)
+		}
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+
+	run := func(args ...string) string {
+		t.Helper()
+
+		var out bytes.Buffer
+		cmd := NewTCommand(strings.NewReader(""), &out, io.Discard)
+		cmd.SetArgs(args)
+
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+
+		return out.String()
+	}
+
+	run("Call Mum", "--today")
+
+	if out := run("completion", "bash"); !strings.Contains(out, "bash completion") {
+		t.Fatalf("expected a bash completion script, got %q", out)
+	}
+
+	out := run(cobra.ShellCompRequestCmd, "log", "")
+	if !strings.Contains(out, "\tCall Mum (Today)\n") {
+		t.Fatalf("expected todo IDs described by their titles, got %q", out)
+	}
+
+	id, _, _ := strings.Cut(out, "\t")
+	if out := run(cobra.ShellCompRequestCmd, "log", id[:8]); !strings.HasPrefix(out, id+"\t") {
+		t.Fatalf("expected the ID prefix to be completed, got %q", out)
+	}
+
+	if out := run(cobra.ShellCompRequestCmd, "Title", "--list", ""); !strings.Contains(out, "tomorrow\tTomorrow\n") {
+		t.Fatalf("expected list IDs described by their names, got %q", out)
+	}
+}
+
+func TestListAndDoneWithQueries(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+
+		var out bytes.Buffer
+		cmd := NewTCommand(strings.NewReader(""), &out, io.Discard)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		return out.String(), err
+	}
+
+	input := "Daily standup @work\nWrite report @work due:2025-01-03\nBuy milk @shop\n"
+	cmd := NewTCommand(strings.NewReader(input), io.Discard, io.Discard)
+	cmd.SetArgs([]string{"add", "-", "--today"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("add error = %v", err)
+	}
+
+	out, err := run("list", "due < +3d and not done and tag:work")
+	if err != nil {
+		t.Fatalf("list error = %v", err)
+	}
+	if !strings.Contains(out, "Daily standup") || !strings.Contains(out, "Write report") || strings.Contains(out, "Buy milk") {
+		t.Fatalf("unexpected list output %q", out)
+	}
+
+	if _, err := run("list", "due <"); err == nil || !strings.Contains(err.Error(), "expected a value") {
+		t.Fatalf("expected an invalid query to be rejected, got %v", err)
+	}
+
+	out, err = run("done", "--where", "list:today and title~standup")
+	if err != nil {
+		t.Fatalf("done error = %v", err)
+	}
+	if out != "Completed: Daily standup\n" {
+		t.Fatalf("unexpected done output %q", out)
+	}
+
+	if out, _ := run("list", "done"); !strings.Contains(out, "[x] Daily standup") || strings.Contains(out, "Buy milk") {
+		t.Fatalf("expected only the completed todo to be listed, got %q", out)
+	}
+
+	if _, err := run("done", "nope"); err == nil || !strings.Contains(err.Error(), `no todo with ID "nope"`) {
+		t.Fatalf("expected an unknown ID to be rejected, got %v", err)
+	}
+
+	if out, _ := run("undo"); !strings.Contains(out, "Daily standup") {
+		t.Fatalf("expected the bulk completion to be undone, got %q", out)
+	}
+
+	if out, _ := run("list", "done"); out != "No todos\n" {
+		t.Fatalf("expected no completed todos after undo, got %q", out)
+	}
+}
+
+func TestEditAndMove(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+
+		var out bytes.Buffer
+		cmd := NewTCommand(strings.NewReader(""), &out, io.Discard)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		return out.String(), err
+	}
+
+	out, err := run("add", "Call Mum", "--today")
+	if err != nil {
+		t.Fatalf("add error = %v", err)
+	}
+	if !strings.HasPrefix(out, "Added to Today: Call Mum (") {
+		t.Fatalf("unexpected add output %q", out)
+	}
+	id := strings.TrimSuffix(strings.TrimPrefix(out, "Added to Today: Call Mum ("), ")\n")
+
+	if _, err := run("edit", id); err == nil || !strings.Contains(err.Error(), "nothing to change") {
+		t.Fatalf("expected an edit without flags to be rejected, got %v", err)
+	}
+
+	out, err = run("edit", id[:8], "--title", "Call Mum and Dad", "--description", "About Sunday")
+	if err != nil {
+		t.Fatalf("edit error = %v", err)
+	}
+	if out != "Updated: Call Mum and Dad\n" {
+		t.Fatalf("unexpected edit output %q", out)
+	}
+
+	out, err = run("move", id, "tomorrow")
+	if err != nil {
+		t.Fatalf("move error = %v", err)
+	}
+	if out != "Moved to Tomorrow: Call Mum and Dad\n" {
+		t.Fatalf("unexpected move output %q", out)
+	}
+
+	out, _ = run("list", "list:tomorrow and due:tomorrow and description~sunday")
+	if !strings.Contains(out, "Call Mum and Dad") {
+		t.Fatalf("expected the edited todo in tomorrow's list, got %q", out)
+	}
+
+	if out, _ := run("undo"); !strings.Contains(out, "Call Mum and Dad") {
+		t.Fatalf("expected the move to be undone, got %q", out)
+	}
+	if out, _ := run("list", "list:today"); !strings.Contains(out, "Call Mum and Dad") {
+		t.Fatalf("expected the todo back in today's list, got %q", out)
+	}
+}
+
+func TestRPC(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"add","params":{"title":"Call Mum","list":"today"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"search","arguments":{"text":"mum"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"add","params":{"title":" "}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"complete","params":{"where":"title~mum"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"list","params":{"list":"today","query":"done"}}`,
+	}, "\n")
+
+	var out bytes.Buffer
+	cmd := NewTCommand(strings.NewReader(input), &out, io.Discard)
+	cmd.SetArgs([]string{"rpc"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("rpc error = %v", err)
+	}
+
+	type reply struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	var replies []reply
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var r reply
+		if err := dec.Decode(&r); err != nil {
+			t.Fatalf("failed to decode reply: %v", err)
+		}
+		replies = append(replies, r)
+	}
+
+	if len(replies) != 5 {
+		t.Fatalf("expected 5 replies, got %d", len(replies))
+	}
+
+	if !strings.HasPrefix(string(replies[0].Result), `"Added to Today: Call Mum (`) {
+		t.Fatalf("unexpected add result %s", replies[0].Result)
+	}
+
+	if !strings.Contains(string(replies[1].Result), `\"title\":\"Call Mum\"`) {
+		t.Fatalf("expected search to find the todo, got %s", replies[1].Result)
+	}
+
+	if replies[2].Error == nil || replies[2].Error.Message != ErrEmptyTitle.Error() {
+		t.Fatalf("expected a blank title to be rejected, got %+v", replies[2])
+	}
+
+	if string(replies[3].Result) != `"Completed: Call Mum"` {
+		t.Fatalf("unexpected complete result %s", replies[3].Result)
+	}
+
+	var listed []struct {
+		List      string `json:"list"`
+		Title     string `json:"title"`
+		Completed bool   `json:"completed"`
+	}
+	if err := json.Unmarshal(replies[4].Result, &listed); err != nil {
+		t.Fatalf("failed to decode list result: %v", err)
+	}
+	if len(listed) != 1 || listed[0].List != "today" || !listed[0].Completed {
+		t.Fatalf("unexpected list result %+v", listed)
+	}
+}
+
+func TestSyncWithGitRemote(t *testing.T) {
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+
+	remote := filepath.Join(t.TempDir(), "todos.git")
+	if _, err := git.PlainInit(remote, true); err != nil {
+		t.Fatalf("failed to create remote: %v", err)
+	}
+
+	laptop, desktop := t.TempDir(), t.TempDir()
+
+	run := func(dataHome string, args ...string) (string, error) {
+		t.Helper()
+		t.Setenv("XDG_DATA_HOME", dataHome)
+
+		var out bytes.Buffer
+		cmd := NewTCommandWithOptions(strings.NewReader(""), &out, io.Discard, Options{
+			Git: gitstore.Config{Enabled: true, Remote: remote},
+		})
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		return out.String(), err
+	}
+
+	if _, err := run(laptop, "add", "Buy milk", "--today"); err != nil {
+		t.Fatalf("add error = %v", err)
+	}
+
+	if out, err := run(laptop, "sync"); err != nil || out != "Pushed changes to "+remote+"\n" {
+		t.Fatalf("expected the laptop to push, got %q, %v", out, err)
+	}
+
+	if out, err := run(desktop, "sync"); err != nil || out != "Pulled changes from "+remote+"\n" {
+		t.Fatalf("expected the desktop to pull, got %q, %v", out, err)
+	}
+
+	if _, err := run(desktop, "done", "--where", "title~milk"); err != nil {
+		t.Fatalf("done error = %v", err)
+	}
+	if _, err := run(laptop, "add", "Call Mum", "--today"); err != nil {
+		t.Fatalf("add error = %v", err)
+	}
+
+	if _, err := run(desktop, "sync"); err != nil {
+		t.Fatalf("sync error = %v", err)
+	}
+	if out, err := run(laptop, "sync"); err != nil || out != "Merged changes with "+remote+"\n" {
+		t.Fatalf("expected the laptop to merge, got %q, %v", out, err)
+	}
+
+	out, _ := run(laptop, "list", "list:today")
+	if !strings.Contains(out, "[x] Buy milk") || !strings.Contains(out, "[ ] Call Mum") {
+		t.Fatalf("expected changes from both machines, got %q", out)
+	}
+
+	if out, _ := run(laptop, "sync"); out != "Already up to date\n" {
+		t.Fatalf("expected nothing to sync, got %q", out)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	events := filepath.Join(dir, "events.log")
+	script := filepath.Join(dir, "hook.sh")
+	body := "#!/bin/sh\nprintf '%s %s\\n' \"$T_HOOK_EVENT\" \"$(cat)\" >> \"" + events + "\"\n"
+	if err := os.WriteFile(script, []byte(body), 0o700); err != nil {
+		t.Fatalf("failed to write hook: %v", err)
+	}
+
+	run := func(now string, hooks hook.Config, args ...string) (string, error) {
+		t.Helper()
+		t.Setenv(clock.EnvVar, now)
+
+		var out bytes.Buffer
+		cmd := NewTCommandWithOptions(strings.NewReader(""), &out, io.Discard, Options{Hooks: hooks})
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		return out.String(), err
+	}
+
+	all := hook.Config{
+		OnAdd:        []string{script},
+		OnComplete:   []string{script},
+		OnMove:       []string{script},
+		OnAutomation: []string{script},
+		PreComplete:  []string{script},
+	}
+
+	out, err := run("2025-01-02T09:00:00Z", all, "add", "Buy milk", "--tomorrow")
+	if err != nil {
+		t.Fatalf("add error = %v", err)
+	}
+	id := strings.TrimSuffix(strings.TrimPrefix(out, "Added to Tomorrow: Buy milk ("), ")\n")
+
+	if _, err := run("2025-01-03T09:00:00Z", all, "done", "--where", "title~milk"); err != nil {
+		t.Fatalf("done error = %v", err)
+	}
+	if _, err := run("2025-01-03T09:00:00Z", all, "move", id, "todos"); err != nil {
+		t.Fatalf("move error = %v", err)
+	}
+
+	data, err := os.ReadFile(events)
+	if err != nil {
+		t.Fatalf("failed to read hook log: %v", err)
+	}
+
+	var got []string
+	for line := range strings.Lines(string(data)) {
+		event, payload, _ := strings.Cut(strings.TrimSpace(line), " ")
+
+		var p hook.Payload
+		if err := json.Unmarshal([]byte(payload), &p); err != nil {
+			t.Fatalf("failed to decode payload %q: %v", payload, err)
+		}
+		if p.Todo.Title != "Buy milk" || string(p.Event) != event {
+			t.Fatalf("unexpected payload for %s: %q", event, payload)
+		}
+		got = append(got, fmt.Sprintf("%s %s>%s", event, p.From, p.List))
+	}
+
+	want := []string{
+		"on-add >tomorrow",
+		"on-automation tomorrow>today",
+		"pre-complete >today",
+		"on-complete >today",
+		"on-move today>todos",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("expected hooks %q, got %q", want, got)
+	}
+
+	var errOut bytes.Buffer
+	missing := filepath.Join(dir, "missing")
+	cmd := NewTCommandWithOptions(strings.NewReader(""), io.Discard, &errOut, Options{
+		Hooks: hook.Config{OnAdd: []string{missing}},
+	})
+	cmd.SetArgs([]string{"add", "Call Mum"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected a failing hook not to fail the saved change, got %v", err)
+	}
+	if !strings.Contains(errOut.String(), "warning: on-add hook "+missing) {
+		t.Fatalf("expected the failing hook to be reported, got %q", errOut.String())
+	}
+}
+
+func TestPreHookAbortCancelsChange(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+
+	veto := filepath.Join(t.TempDir(), "veto.sh")
+	if err := os.WriteFile(veto, []byte("#!/bin/sh\nexit 1\n"), 0o700); err != nil {
+		t.Fatalf("failed to write hook: %v", err)
+	}
+
+	run := func(hooks hook.Config, args ...string) (string, error) {
+		t.Helper()
+
+		var out bytes.Buffer
+		cmd := NewTCommandWithOptions(strings.NewReader(""), &out, io.Discard, Options{Hooks: hooks})
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		return out.String(), err
+	}
+
+	if _, err := run(hook.Config{}, "add", "Buy milk", "--today"); err != nil {
+		t.Fatalf("add error = %v", err)
+	}
+
+	abort := hook.Config{PreComplete: []string{veto}, Failure: hook.PolicyAbort}
+	_, err := run(abort, "done", "--where", "title~milk")
+	if !errors.Is(err, oplog.ErrCancelled) {
+		t.Fatalf("expected the pre-hook to cancel the change, got %v", err)
+	}
+
+	if out, _ := run(hook.Config{}, "list", "list:today"); !strings.Contains(out, "[ ] Buy milk") {
+		t.Fatalf("expected the todo to stay open, got %q", out)
+	}
+
+	warn := hook.Config{PreComplete: []string{veto}}
+	if _, err := run(warn, "done", "--where", "title~milk"); err != nil {
+		t.Fatalf("expected a failing pre-hook only to warn, got %v", err)
+	}
+
+	if out, _ := run(hook.Config{}, "list", "list:today"); !strings.Contains(out, "[x] Buy milk") {
+		t.Fatalf("expected the todo to be completed, got %q", out)
+	}
+}
+
+func TestSyncRequiresGitStorage(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	cmd := NewTCommand(strings.NewReader(""), io.Discard, io.Discard)
+	cmd.SetArgs([]string{"sync"})
+
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "git storage is not enabled") {
+		t.Fatalf("expected sync to require git storage, got %v", err)
+	}
+}
+
+func TestListMergesConflictCopies(t *testing.T) {
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+
+	laptop, desktop := t.TempDir(), t.TempDir()
+
+	run := func(dataHome string, args ...string) (string, string) {
+		t.Helper()
+		t.Setenv("XDG_DATA_HOME", dataHome)
+
+		var out, errOut bytes.Buffer
+		cmd := NewTCommand(strings.NewReader(""), &out, &errOut)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v error = %v", args, err)
+		}
+
+		return out.String(), errOut.String()
+	}
+
+	run(laptop, "add", "Buy milk", "--today")
+	run(desktop, "add", "Call Mum", "--today")
+
+	data, err := os.ReadFile(filepath.Join(desktop, "t", "today.json"))
+	if err != nil {
+		t.Fatalf("failed to read list: %v", err)
+	}
+	conflict := "today (conflicted copy 2025-01-02).json"
+	if err := os.WriteFile(filepath.Join(laptop, "t", conflict), data, 0o600); err != nil {
+		t.Fatalf("failed to write conflict copy: %v", err)
+	}
+
+	out, errOut := run(laptop, "list")
+	if want := "Merged " + conflict + `: added "Call Mum"` + "\n"; errOut != want {
+		t.Fatalf("expected the merge to be reported as %q, got %q", want, errOut)
+	}
+	if !strings.Contains(out, "Buy milk") || !strings.Contains(out, "Call Mum") {
+		t.Fatalf("expected todos from both copies, got %q", out)
+	}
+
+	if _, errOut := run(laptop, "list"); errOut != "" {
+		t.Fatalf("expected nothing more to merge, got %q", errOut)
+	}
+
+	out, _ = run(laptop, "log")
+	merged := slices.ContainsFunc(strings.Split(out, "\n"), func(line string) bool {
+		return strings.Contains(line, "Call Mum") && strings.HasSuffix(line, string(oplog.SourceReconcile))
+	})
+	if !merged {
+		t.Fatalf("expected the merge to be logged as an operation of its own, got %q", out)
+	}
+}
+
+func TestRolloverIsLoggedAsAutomation(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+
+	cmd := NewTCommand(strings.NewReader(""), io.Discard, io.Discard)
+	cmd.SetArgs([]string{"add", "Call Mum", "--tomorrow"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("add error = %v", err)
+	}
+
+	a := &app{clock: clock.Fixed(time.Date(2025, time.January, 3, 9, 0, 0, 0, time.UTC))}
+	store, err := a.openStorage()
+	if err != nil {
+		t.Fatalf("openStorage error = %v", err)
+	}
+
+	msg, ok := a.rolloverLists(store)().(tui.RolloverMsg)
+	if !ok || msg.Err != nil || len(msg.Lists[list.TodayID].Todos) != 1 {
+		t.Fatalf("expected the todo to move to today, got %+v", msg)
+	}
+
+	events, err := oplog.Read(store.DataDir(), storage.Plaintext)
+	if err != nil {
+		t.Fatalf("oplog.Read error = %v", err)
+	}
+	last := events[len(events)-1]
+	if last.Source != oplog.SourceAutomation || last.Kind != oplog.KindAutomationMoved {
+		t.Fatalf("expected the rollover to be logged as an automation, got %+v", last)
+	}
+}
+
+func TestServeMergesConflictCopies(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+
+	run := func(args ...string) {
+		t.Helper()
+
+		cmd := NewTCommand(strings.NewReader(""), io.Discard, io.Discard)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v error = %v", args, err)
+		}
+	}
+
+	run("add", "Call Mum", "--today")
+	listFile := filepath.Join(dataHome, "t", "today.json")
+	data, err := os.ReadFile(listFile)
+	if err != nil {
+		t.Fatalf("failed to read list: %v", err)
+	}
+	if err := os.Remove(listFile); err != nil {
+		t.Fatalf("failed to remove list: %v", err)
+	}
+	run("add", "Buy milk", "--today")
+
+	conflict := "today (conflicted copy 2025-01-02).json"
+	if err := os.WriteFile(filepath.Join(dataHome, "t", conflict), data, 0o600); err != nil {
+		t.Fatalf("failed to write conflict copy: %v", err)
+	}
+
+	var errOut bytes.Buffer
+	a := &app{clock: clock.Fixed(time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC)), errOut: &errOut}
+	handler, err := a.newServer("secret")
+	if err != nil {
+		t.Fatalf("newServer error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/lists", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Buy milk") || !strings.Contains(rec.Body.String(), "Call Mum") {
+		t.Fatalf("expected todos from both copies, got %d %s", rec.Code, rec.Body)
+	}
+	if !strings.Contains(errOut.String(), "Merged "+conflict) {
+		t.Fatalf("expected the merge to be reported, got %q", errOut.String())
+	}
+}
+
+func TestEncryptAndDecrypt(t *testing.T) {
+	dataHome, configHome := t.TempDir(), t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+	t.Setenv(crypt.EnvVar, "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+
+		var out bytes.Buffer
+		cmd := NewTCommand(strings.NewReader(""), &out, io.Discard)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		return out.String(), err
+	}
+
+	listFile := filepath.Join(dataHome, "t", "today.json")
+	contains := func(title string) bool {
+		t.Helper()
+		data, err := os.ReadFile(listFile)
+		if err != nil {
+			t.Fatalf("failed to read list: %v", err)
+		}
+		return strings.Contains(string(data), title)
+	}
+
+	if _, err := run("add", "Call Mum", "--today"); err != nil {
+		t.Fatalf("add error = %v", err)
+	}
+
+	keyFile := filepath.Join(configHome, "t", "key.txt")
+	out, err := run("encrypt")
+	if err != nil {
+		t.Fatalf("encrypt error = %v", err)
+	}
+	if !strings.Contains(out, "Generated a key in "+keyFile) || !strings.Contains(out, "Encrypted ") {
+		t.Fatalf("expected a key to be generated, got %q", out)
+	}
+	if contains("Call Mum") {
+		t.Fatalf("expected %s to be encrypted", listFile)
+	}
+
+	if _, err := run("add", "Buy milk", "--today"); err != nil {
+		t.Fatalf("add error = %v", err)
+	}
+	if contains("Buy milk") {
+		t.Fatalf("expected new todos to be encrypted")
+	}
+
+	out, err = run("list", "list:today")
+	if err != nil || !strings.Contains(out, "Call Mum") || !strings.Contains(out, "Buy milk") {
+		t.Fatalf("expected to list the encrypted todos, got %q, %v", out, err)
+	}
+	if out, err := run("log"); err != nil || !strings.Contains(out, "Buy milk") {
+		t.Fatalf("expected to read the encrypted log, got %q, %v", out, err)
+	}
+
+	key, err := os.ReadFile(keyFile)
+	if err != nil {
+		t.Fatalf("failed to read key: %v", err)
+	}
+	if err := os.Rename(keyFile, keyFile+".moved"); err != nil {
+		t.Fatalf("failed to move key: %v", err)
+	}
+	if _, err := run("list"); !errors.Is(err, crypt.ErrNoKey) {
+		t.Fatalf("expected listing without the key to fail, got %v", err)
+	}
+
+	// The secret key follows the comments in the key file.
+	fields := strings.Fields(string(key))
+	t.Setenv(crypt.EnvVar, fields[len(fields)-1])
+	if _, err := run("decrypt"); err != nil {
+		t.Fatalf("decrypt error = %v", err)
+	}
+	if !contains("Call Mum") || !contains("Buy milk") {
+		t.Fatalf("expected %s to be decrypted", listFile)
+	}
+
+	if _, err := run("decrypt"); !errors.Is(err, crypt.ErrNotEncrypted) {
+		t.Fatalf("expected decrypting twice to fail, got %v", err)
+	}
+}
+
+func TestEncryptWithGitStorage(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+	t.Setenv(crypt.EnvVar, "")
+
+	run := func(args ...string) (string, string, error) {
+		t.Helper()
+
+		var out, errOut bytes.Buffer
+		cmd := NewTCommandWithOptions(strings.NewReader(""), &out, &errOut, Options{
+			Git: gitstore.Config{Enabled: true},
+		})
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		return out.String(), errOut.String(), err
+	}
+
+	if _, _, err := run("add", "Call Mum", "--today"); err != nil {
+		t.Fatalf("add error = %v", err)
+	}
+
+	dataDir := filepath.Join(dataHome, "t")
+	if _, _, err := run("encrypt"); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected encrypt to refuse a plaintext git history, got %v", err)
+	}
+	if encrypted, err := crypt.Encrypted(dataDir); err != nil || encrypted {
+		t.Fatalf("expected the lists to stay unencrypted, got %v, %v", encrypted, err)
+	}
+
+	_, errOut, err := run("encrypt", "--force")
+	if err != nil {
+		t.Fatalf("encrypt --force error = %v", err)
+	}
+	if !strings.Contains(errOut, "still hold your todos unencrypted") {
+		t.Fatalf("expected a warning about the git history, got %q", errOut)
+	}
+
+	clean := func() bool {
+		t.Helper()
+		repo, err := git.PlainOpen(dataDir)
+		if err != nil {
+			t.Fatalf("failed to open repository: %v", err)
+		}
+		w, err := repo.Worktree()
+		if err != nil {
+			t.Fatalf("failed to open worktree: %v", err)
+		}
+		status, err := w.Status()
+		if err != nil {
+			t.Fatalf("failed to read status: %v", err)
+		}
+		return status.IsClean()
+	}
+	if !clean() {
+		t.Fatalf("expected the encrypted lists to be committed")
+	}
+
+	if _, _, err := run("decrypt"); err != nil {
+		t.Fatalf("decrypt error = %v", err)
+	}
+	if !clean() {
+		t.Fatalf("expected the decrypted lists to be committed")
+	}
+}
+
+func TestInitUsesProjectLists(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+
+	project := t.TempDir()
+	nested := filepath.Join(project, "cmd", "server")
+	if err := os.MkdirAll(nested, 0o700); err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+
+		var out bytes.Buffer
+		cmd := NewTCommand(strings.NewReader(""), &out, io.Discard)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		return out.String(), err
+	}
+
+	if _, err := run("add", "Water the plants", "--today"); err != nil {
+		t.Fatalf("add error = %v", err)
+	}
+
+	localDir := filepath.Join(project, ".t")
+	if out, err := run("init", project); err != nil || out != "Created todo lists in "+localDir+"\n" {
+		t.Fatalf("expected the project lists to be created, got %q, %v", out, err)
+	}
+	if _, err := run("init", project); err == nil {
+		t.Fatalf("expected init to refuse to replace existing lists")
+	}
+	if _, err := os.Stat(filepath.Join(localDir, ".gitignore")); err != nil {
+		t.Fatalf("expected a .gitignore in %s: %v", localDir, err)
+	}
+
+	t.Chdir(nested)
+
+	if _, err := run("add", "Fix the build", "--today"); err != nil {
+		t.Fatalf("add error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(localDir, "today.json")); err != nil {
+		t.Fatalf("expected the todo to be saved to the project: %v", err)
+	}
+
+	out, err := run("list")
+	if err != nil || !strings.Contains(out, "Fix the build") || strings.Contains(out, "Water the plants") {
+		t.Fatalf("expected only the project's todos, got %q, %v", out, err)
+	}
+
+	out, err = run("--global", "list")
+	if err != nil || strings.Contains(out, "Fix the build") || !strings.Contains(out, "Water the plants") {
+		t.Fatalf("expected only the global todos, got %q, %v", out, err)
+	}
+
+	var rpcOut bytes.Buffer
+	cmd := NewTCommand(strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"list","params":{"list":"today"}}`), &rpcOut, io.Discard)
+	cmd.SetArgs([]string{"--global", "rpc"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("rpc error = %v", err)
+	}
+	if out := rpcOut.String(); strings.Contains(out, "Fix the build") || !strings.Contains(out, "Water the plants") {
+		t.Fatalf("expected rpc to use the global todos, got %q", out)
+	}
+}
+
+func TestInitWithGitStorage(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+
+	project := t.TempDir()
+	t.Chdir(project)
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+
+		var out bytes.Buffer
+		cmd := NewTCommandWithOptions(strings.NewReader(""), &out, io.Discard, Options{
+			Git: gitstore.Config{Enabled: true},
+		})
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		return out.String(), err
+	}
+
+	if _, err := run("init"); err != nil {
+		t.Fatalf("init error = %v", err)
+	}
+	if _, err := run("add", "Fix the build", "--today"); err != nil {
+		t.Fatalf("add error = %v", err)
+	}
+
+	localDir := filepath.Join(project, ".t")
+	if _, err := os.Stat(filepath.Join(localDir, "today.json")); err != nil {
+		t.Fatalf("expected the todo to be saved to the project: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(localDir, ".git")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected no git repository inside the project lists, got %v", err)
+	}
+
+	if _, err := run("sync"); err == nil || !strings.Contains(err.Error(), "belong to a project") {
+		t.Fatalf("expected sync to refuse project lists, got %v", err)
+	}
+}
+
+func TestStoreName(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := map[string]string{
+		filepath.Join(home, ".local", "share", "t"):                 "global",
+		filepath.Join(home, "code", "t", ".t"):                      filepath.Join("~", "code", "t", ".t"),
+		filepath.Join(string(filepath.Separator), "srv", "t", ".t"): filepath.Join(string(filepath.Separator), "srv", "t", ".t"),
+	}
+	for dataDir, want := range tests {
+		if got := (&app{}).storeName(dataDir); got != want {
+			t.Errorf("storeName(%q) = %q, want %q", dataDir, got, want)
+		}
+	}
+
+	work := &app{profile: "work"}
+	if got := work.storeName(filepath.Join(home, ".local", "share", "t", "profiles", "work")); got != "work" {
+		t.Errorf("storeName() = %q, want the profile name", got)
+	}
+}
+
+func TestCompletionUsesSelectedProfile(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+	t.Setenv(config.ProfileEnvVar, "")
+
+	run := func(args ...string) string {
+		t.Helper()
+
+		var out bytes.Buffer
+		cmd := NewTCommandWithOptions(strings.NewReader(""), &out, io.Discard, Options{
+			Profiles: map[string]config.Profile{"work": {}},
+		})
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+
+		return out.String()
+	}
+
+	run("Global todo")
+	run("--profile", "work", "Work todo")
+
+	for _, command := range []string{"done", "log"} {
+		out := run(cobra.ShellCompRequestCmd, "--profile", "work", command, "")
+		if !strings.Contains(out, "Work todo") || strings.Contains(out, "Global todo") {
+			t.Fatalf("expected %s to complete the work todos with --profile, got %q", command, out)
+		}
+	}
+
+	t.Setenv(config.ProfileEnvVar, "work")
+	if out := run(cobra.ShellCompRequestCmd, "done", ""); !strings.Contains(out, "Work todo") || strings.Contains(out, "Global todo") {
+		t.Fatalf("expected the work todos to be completed with %s, got %q", config.ProfileEnvVar, out)
+	}
+}
+
+func TestRPCUsesSelectedProfile(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+	t.Setenv(config.ProfileEnvVar, "")
+
+	opts := Options{Profiles: map[string]config.Profile{"home": {}}}
+	input := `{"jsonrpc":"2.0","id":1,"method":"add","params":{"title":"Home thing","list":"today"}}`
+
+	var out bytes.Buffer
+	cmd := NewTCommandWithOptions(strings.NewReader(input), &out, io.Discard, opts)
+	cmd.SetArgs([]string{"--profile", "home", "rpc"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("rpc error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Added to Today: Home thing") {
+		t.Fatalf("unexpected rpc reply %q", out.String())
+	}
+
+	if _, err := os.Stat(filepath.Join(dataHome, "t", "profiles", "home", "today.json")); err != nil {
+		t.Fatalf("expected the todo to be saved to the profile: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dataHome, "t", "today.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the default lists to be left alone, got %v", err)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	dataHome, configHome := t.TempDir(), t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv(clock.EnvVar, "2025-01-02T09:00:00Z")
+	t.Setenv(config.ProfileEnvVar, "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+
+		cfg, err := config.Load()
+		if err != nil {
+			t.Fatalf("config.Load() error = %v", err)
+		}
+
+		var out bytes.Buffer
+		cmd := NewTCommandWithOptions(strings.NewReader(""), &out, io.Discard, Options{
+			Profile:  cfg.Profile,
+			Profiles: cfg.Profiles,
+		})
+		cmd.SetArgs(args)
+		err = cmd.Execute()
+
+		return out.String(), err
+	}
+
+	if _, err := run("add", "Water the plants", "--today"); err != nil {
+		t.Fatalf("add error = %v", err)
+	}
+	if out, err := run("profile", "add", "work"); err != nil || out != "Added profile work\n" {
+		t.Fatalf("expected the profile to be added, got %q, %v", out, err)
+	}
+	if _, err := run("--profile", "work", "add", "Write the report", "--today"); err != nil {
+		t.Fatalf("add error = %v", err)
+	}
+
+	workDir := filepath.Join(dataHome, "t", "profiles", "work")
+	if _, err := os.Stat(filepath.Join(workDir, "today.json")); err != nil {
+		t.Fatalf("expected the todo to be saved to the profile: %v", err)
+	}
+
+	if out, _ := run("list"); strings.Contains(out, "Write the report") || !strings.Contains(out, "Water the plants") {
+		t.Fatalf("expected the default profile's todos, got %q", out)
+	}
+
+	t.Setenv(config.ProfileEnvVar, "work")
+	if out, _ := run("list"); !strings.Contains(out, "Write the report") || strings.Contains(out, "Water the plants") {
+		t.Fatalf("expected the work profile's todos, got %q", out)
+	}
+	t.Setenv(config.ProfileEnvVar, "")
+
+	if out, err := run("profile", "switch", "work"); err != nil || out != "Switched to profile work\n" {
+		t.Fatalf("expected to switch profile, got %q, %v", out, err)
+	}
+	if out, _ := run("list"); !strings.Contains(out, "Write the report") {
+		t.Fatalf("expected the work profile's todos, got %q", out)
+	}
+
+	out, err := run("profile", "list")
+	if err != nil || !strings.Contains(out, "* work") || !strings.Contains(out, workDir) {
+		t.Fatalf("expected work to be listed as active, got %q, %v", out, err)
+	}
+
+	if _, err := run("--profile", "home", "list"); !errors.Is(err, config.ErrUnknownProfile) {
+		t.Fatalf("expected an unknown profile to be rejected, got %v", err)
+	}
+
+	if _, err := run("profile", "switch", "default"); err != nil {
+		t.Fatalf("switch error = %v", err)
+	}
+	if out, _ := run("list"); !strings.Contains(out, "Water the plants") {
+		t.Fatalf("expected the default profile's todos, got %q", out)
+	}
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/oplog"
+)
+
+func newUndoCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "undo",
+		Short: "Revert the most recent change.",
+		Long: heredoc.Doc(`
+			Revert the most recent change made from the command line or the
+			interactive interface. Run it again to step further back through
+			the history shown by t log.
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, err := a.openStorage()
+			if err != nil {
+				return fmt.Errorf("failed to initialise storage: %w", err)
+			}
+
+			reverted, err := oplog.Undo(store, list.Default())
+			if errors.Is(err, oplog.ErrNothingToUndo) {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Nothing to undo")
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to undo: %w", err)
+			}
+
+			for _, e := range reverted {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Reverted %s: %s\n", e.Kind, e.Title())
+			}
+
+			return nil
+		},
+	}
+}
@@ -11,6 +11,10 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/unfunco/t/internal/backup"
+	"github.com/unfunco/t/internal/crypt"
+	"github.com/unfunco/t/internal/gitstore"
+	"github.com/unfunco/t/internal/hook"
 	"github.com/unfunco/t/internal/paths"
 	"github.com/unfunco/t/internal/theme"
 )
@@ -20,6 +24,26 @@ const configFilename = "config.json"
 // Config captures the configurable application properties.
 type Config struct {
 	Theme theme.Config `json:"theme"`
+	// DayStartsAt is the HH:MM time at which a new day begins, allowing work
+	// after midnight to count towards the previous day. Defaults to 00:00.
+	DayStartsAt string `json:"day_starts_at,omitempty"`
+	// TimeZone is the IANA time zone used to decide which day it is. Defaults
+	// to the system time zone.
+	TimeZone string `json:"time_zone,omitempty"`
+	// Backup controls automatic snapshots of the data directory.
+	Backup backup.Config `json:"backup"`
+	// Git versions the data directory in a git repository.
+	Git gitstore.Config `json:"git"`
+	// Encryption locates the key used to read an encrypted data directory.
+	Encryption crypt.Config `json:"encryption"`
+	// Hooks lists the executables run when todos change.
+	Hooks hook.Config `json:"hooks"`
+	// Profile is the profile used when none is selected with --profile or
+	// the T_PROFILE environment variable. Defaults to the default profile.
+	Profile string `json:"profile,omitempty"`
+	// Profiles holds named sets of todo lists, each with a data directory and
+	// settings of its own.
+	Profiles map[string]Profile `json:"profiles,omitempty"`
 }
 
 // Load retrieves the configuration from the default data directory.
@@ -39,7 +63,11 @@ func LoadFromDir(configDir string) (Config, error) {
 	}
 
 	cfg := Config{
-		Theme: theme.DefaultConfig(),
+		Theme:      theme.DefaultConfig(),
+		Backup:     backup.DefaultConfig(),
+		Git:        gitstore.DefaultConfig(),
+		Encryption: crypt.DefaultConfig(),
+		Hooks:      hook.DefaultConfig(),
 	}
 
 	configPath := filepath.Join(configDir, configFilename)
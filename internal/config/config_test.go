@@ -4,10 +4,13 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
+	"github.com/unfunco/t/internal/gitstore"
 	"github.com/unfunco/t/internal/theme"
 )
 
@@ -77,3 +80,94 @@ func TestLoadFromDirReadsConfigFile(t *testing.T) {
 		t.Fatalf("theme mismatch, want %+v got %+v", want, cfg.Theme)
 	}
 }
+
+func TestLoadFromDirReadsCalendarSettings(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte(`{"day_starts_at": "04:00", "time_zone": "Europe/London"}`)
+
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), content, 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	cfg, err := LoadFromDir(dir)
+	if err != nil {
+		t.Fatalf("LoadFromDir() error = %v", err)
+	}
+
+	if cfg.DayStartsAt != "04:00" || cfg.TimeZone != "Europe/London" {
+		t.Fatalf("unexpected calendar settings: %+v", cfg)
+	}
+
+	if want := theme.DefaultConfig(); cfg.Theme != want {
+		t.Fatalf("theme mismatch, want %+v got %+v", want, cfg.Theme)
+	}
+}
+
+func TestLoadFromDirReadsGitSettings(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte(`{"git": {"enabled": true, "remote": "git@example.com:me/todos.git"}}`)
+
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), content, 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	cfg, err := LoadFromDir(dir)
+	if err != nil {
+		t.Fatalf("LoadFromDir() error = %v", err)
+	}
+
+	want := gitstore.Config{Enabled: true, Remote: "git@example.com:me/todos.git", Branch: gitstore.DefaultBranch}
+	if cfg.Git != want {
+		t.Fatalf("git mismatch, want %+v got %+v", want, cfg.Git)
+	}
+}
+
+func TestAddAndSwitchProfile(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte(`{"day_starts_at": "04:00", "git": {"enabled": true}}`)
+
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), content, 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	if err := AddProfile(dir, "work", Profile{DataDir: "/srv/work"}); err != nil {
+		t.Fatalf("AddProfile() error = %v", err)
+	}
+	if err := AddProfile(dir, "work", Profile{}); !errors.Is(err, ErrProfileExists) {
+		t.Fatalf("AddProfile() twice error = %v, want ErrProfileExists", err)
+	}
+	for _, name := range []string{"default", "../work", ""} {
+		if err := AddProfile(dir, name, Profile{}); err == nil {
+			t.Fatalf("AddProfile(%q) accepted an invalid name", name)
+		}
+	}
+
+	if err := SwitchProfile(dir, "home"); !errors.Is(err, ErrUnknownProfile) {
+		t.Fatalf("SwitchProfile() error = %v, want ErrUnknownProfile", err)
+	}
+	if err := SwitchProfile(dir, "work"); err != nil {
+		t.Fatalf("SwitchProfile() error = %v", err)
+	}
+
+	cfg, err := LoadFromDir(dir)
+	if err != nil {
+		t.Fatalf("LoadFromDir() error = %v", err)
+	}
+
+	if cfg.Profile != "work" || cfg.Profiles["work"].DataDir != "/srv/work" {
+		t.Fatalf("expected the work profile to be active, got %q %+v", cfg.Profile, cfg.Profiles)
+	}
+	if cfg.DayStartsAt != "04:00" || !cfg.Git.Enabled {
+		t.Fatalf("expected the other settings to be kept, got %+v", cfg)
+	}
+	if names := ProfileNames(cfg.Profiles); !slices.Equal(names, []string{DefaultProfile, "work"}) {
+		t.Fatalf("ProfileNames() = %v", names)
+	}
+
+	if err := SwitchProfile(dir, DefaultProfile); err != nil {
+		t.Fatalf("SwitchProfile() error = %v", err)
+	}
+	if cfg, err := LoadFromDir(dir); err != nil || cfg.Profile != "" {
+		t.Fatalf("expected no active profile, got %q, %v", cfg.Profile, err)
+	}
+}
@@ -0,0 +1,219 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/unfunco/t/internal/gitstore"
+	"github.com/unfunco/t/internal/paths"
+	"github.com/unfunco/t/internal/theme"
+)
+
+// ProfileEnvVar is the environment variable selecting the profile to use,
+// which takes precedence over the active profile in the configuration.
+const ProfileEnvVar = "T_PROFILE"
+
+// DefaultProfile names the settings outside of any profile, and the data
+// directory used when no profile is selected.
+const DefaultProfile = "default"
+
+// profileName matches the names profiles can be given, which are also used as
+// directory names.
+var profileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+var (
+	// ErrUnknownProfile is returned when selecting a profile that has not
+	// been added.
+	ErrUnknownProfile = errors.New("unknown profile")
+	// ErrProfileExists is returned when adding a profile that already exists.
+	ErrProfileExists = errors.New("profile already exists")
+)
+
+// Profile is a named set of todo lists with settings of its own, such as
+// separate lists for work and personal todos.
+type Profile struct {
+	// DataDir is the directory holding the profile's lists. Defaults to a
+	// directory named after the profile beneath the data directory.
+	DataDir string `json:"data_dir,omitempty"`
+	// Git replaces the top-level git storage settings for the profile.
+	Git *gitstore.Config `json:"git,omitempty"`
+	// Theme replaces the top-level theme for the profile.
+	Theme *theme.Config `json:"theme,omitempty"`
+}
+
+// Validate reports whether the profile can be used.
+func (p Profile) Validate() error {
+	if p.DataDir != "" && !filepath.IsAbs(p.DataDir) {
+		return fmt.Errorf("data directory %q must be an absolute path", p.DataDir)
+	}
+
+	if p.Git != nil {
+		if err := p.Git.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DataDirFor returns the directory holding the named profile's lists.
+func (p Profile) DataDirFor(name string) (string, error) {
+	if p.DataDir != "" {
+		return p.DataDir, nil
+	}
+
+	return paths.ProfileDataDir(name)
+}
+
+// ValidateProfileName reports whether name can be given to a new profile.
+func ValidateProfileName(name string) error {
+	if name == DefaultProfile {
+		return fmt.Errorf("profile name %q is reserved", name)
+	}
+
+	if !profileName.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q, use letters, digits, hyphens and underscores", name)
+	}
+
+	return nil
+}
+
+// ProfileNames returns the names of the profiles, sorted, after the default
+// profile.
+func ProfileNames(profiles map[string]Profile) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return append([]string{DefaultProfile}, names...)
+}
+
+// AddProfile adds the named profile to the configuration file in configDir.
+func AddProfile(configDir, name string, p Profile) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	return update(configDir, func(doc map[string]json.RawMessage) error {
+		profiles := make(map[string]json.RawMessage)
+		if raw, ok := doc["profiles"]; ok {
+			if err := json.Unmarshal(raw, &profiles); err != nil {
+				return fmt.Errorf("decode profiles: %w", err)
+			}
+		}
+
+		if _, ok := profiles[name]; ok {
+			return fmt.Errorf("%w: %s", ErrProfileExists, name)
+		}
+
+		data, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("encode profile: %w", err)
+		}
+		profiles[name] = data
+
+		return set(doc, "profiles", profiles)
+	})
+}
+
+// SwitchProfile makes the named profile active in the configuration file in
+// configDir, so that it is used when no other profile is selected.
+func SwitchProfile(configDir, name string) error {
+	return update(configDir, func(doc map[string]json.RawMessage) error {
+		if name == DefaultProfile {
+			delete(doc, "profile")
+			return nil
+		}
+
+		var profiles map[string]json.RawMessage
+		if raw, ok := doc["profiles"]; ok {
+			if err := json.Unmarshal(raw, &profiles); err != nil {
+				return fmt.Errorf("decode profiles: %w", err)
+			}
+		}
+
+		if _, ok := profiles[name]; !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownProfile, name)
+		}
+
+		return set(doc, "profile", name)
+	})
+}
+
+// update applies fn to the configuration file in configDir and saves it,
+// keeping any settings fn leaves alone.
+func update(configDir string, fn func(map[string]json.RawMessage) error) error {
+	if configDir == "" {
+		return fmt.Errorf("config directory cannot be empty")
+	}
+
+	configPath := filepath.Join(configDir, configFilename)
+
+	doc := make(map[string]json.RawMessage)
+	data, err := os.ReadFile(configPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("read config: %w", err)
+	case len(data) > 0:
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("decode config: %w", err)
+		}
+	}
+
+	if err := fn(doc); err != nil {
+		return err
+	}
+
+	data, err = json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+
+	if err := os.MkdirAll(configDir, 0o700); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(configDir, configFilename+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file for config: %w", err)
+	}
+
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), configPath)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write config: %w", err)
+	}
+
+	return nil
+}
+
+// set stores the JSON encoding of value under key.
+func set(doc map[string]json.RawMessage, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", key, err)
+	}
+	doc[key] = data
+
+	return nil
+}
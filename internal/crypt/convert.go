@@ -0,0 +1,171 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package crypt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/unfunco/t/internal/backup"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/oplog"
+	"github.com/unfunco/t/internal/storage"
+)
+
+// Encrypt encrypts the lists in the data directory, along with their backups
+// and the operation log, to the public key of key.
+func Encrypt(dataDir string, key *age.X25519Identity) error {
+	encrypted, err := Encrypted(dataDir)
+	if err != nil {
+		return err
+	}
+	if encrypted {
+		return ErrEncrypted
+	}
+
+	if err := os.MkdirAll(dataDir, 0o700); err != nil {
+		return fmt.Errorf("create data directory: %w", err)
+	}
+
+	// The recipients are written first, so that an interrupted conversion
+	// leaves a directory that reads both encrypted and unencrypted files.
+	recipients := fmt.Sprintf("# The todos in this directory are encrypted to these age public keys.\n%s\n", key.Recipient())
+	if err := writeFile(filepath.Join(dataDir, RecipientsFilename), []byte(recipients)); err != nil {
+		return err
+	}
+
+	c, err := NewCipher(dataDir, key)
+	if err != nil {
+		return err
+	}
+
+	return convert(dataDir, c, c)
+}
+
+// Decrypt decrypts every file Encrypt encrypted with key, and marks the data
+// directory as unencrypted.
+func Decrypt(dataDir string, key *age.X25519Identity) error {
+	encrypted, err := Encrypted(dataDir)
+	if err != nil {
+		return err
+	}
+	if !encrypted {
+		return ErrNotEncrypted
+	}
+
+	c, err := NewCipher(dataDir, key)
+	if err != nil {
+		return err
+	}
+
+	if err := convert(dataDir, c, storage.Plaintext); err != nil {
+		return err
+	}
+
+	if err := os.Remove(filepath.Join(dataDir, RecipientsFilename)); err != nil {
+		return fmt.Errorf("remove %s: %w", RecipientsFilename, err)
+	}
+
+	return nil
+}
+
+// convert rewrites the files holding todos, decrypting them with read and
+// encrypting them with write.
+func convert(dataDir string, read, write storage.Cipher) error {
+	dirs := []string{dataDir}
+
+	snapshots, err := backup.List(dataDir)
+	if err != nil {
+		return err
+	}
+	for _, snapshot := range snapshots {
+		dirs = append(dirs, filepath.Join(dataDir, backup.Dir, snapshot.Name))
+	}
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("read %s: %w", dir, err)
+		}
+
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() || !holdsTodos(entry.Name()) {
+				continue
+			}
+			if err := convertFile(filepath.Join(dir, entry.Name()), read, write); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := oplog.Rewrite(dataDir, read, write); err != nil {
+		return fmt.Errorf("convert %s: %w", oplog.Filename, err)
+	}
+
+	return nil
+}
+
+// holdsTodos reports whether the named file is a list file, or a copy of one
+// such as a conflict copy or a backup taken before an upgrade.
+func holdsTodos(name string) bool {
+	if strings.Contains(name, ".tmp-") {
+		return false
+	}
+
+	for _, def := range list.Default() {
+		if strings.HasPrefix(name, strings.TrimSuffix(def.Filename, filepath.Ext(def.Filename))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func convertFile(path string, read, write storage.Cipher) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", filepath.Base(path), err)
+	}
+
+	plaintext, err := read.Decrypt(data)
+	if err != nil {
+		return fmt.Errorf("decrypt %s: %w", filepath.Base(path), err)
+	}
+
+	data, err = write.Encrypt(plaintext)
+	if err != nil {
+		return fmt.Errorf("encrypt %s: %w", filepath.Base(path), err)
+	}
+
+	return writeFile(path, data)
+}
+
+// writeFile atomically replaces the file at path.
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file for %s: %w", filepath.Base(path), err)
+	}
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write %s: %w", filepath.Base(path), err)
+	}
+
+	return nil
+}
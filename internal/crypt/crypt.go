@@ -0,0 +1,258 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+// Package crypt encrypts the data directory at rest with age.
+//
+// A data directory is encrypted when it holds a recipients file naming the
+// age public keys its files are encrypted to. Reading them takes the matching
+// secret key, which is read from the T_KEY environment variable or a key file
+// kept outside the data directory, so that the lists can be shared through a
+// sync service or a git remote without exposing them.
+package crypt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"github.com/unfunco/t/internal/paths"
+	"github.com/unfunco/t/internal/storage"
+)
+
+// EnvVar is the environment variable holding the secret key, which takes
+// precedence over the key file.
+const EnvVar = "T_KEY"
+
+// RecipientsFilename is the name of the file in the data directory listing
+// the public keys its files are encrypted to.
+const RecipientsFilename = "recipients.txt"
+
+// KeyFilename is the name of the key file in the configuration directory used
+// when none is configured.
+const KeyFilename = "key.txt"
+
+// header begins every file encrypted by age.
+var header = []byte("age-encryption.org/v1\n")
+
+var (
+	// ErrNoKey is returned when an encrypted data directory is opened without
+	// a secret key.
+	ErrNoKey = errors.New("no encryption key found")
+	// ErrEncrypted is returned when encrypting a data directory that is
+	// already encrypted.
+	ErrEncrypted = errors.New("data directory is already encrypted")
+	// ErrNotEncrypted is returned when decrypting a data directory that is not
+	// encrypted.
+	ErrNotEncrypted = errors.New("data directory is not encrypted")
+)
+
+// Config represents the raw encryption configuration values.
+type Config struct {
+	// KeyFile is the path of the file holding the age secret key. Defaults to
+	// key.txt in the configuration directory.
+	KeyFile string `json:"key_file,omitempty"`
+}
+
+// DefaultConfig returns the built-in encryption configuration.
+func DefaultConfig() Config {
+	return Config{}
+}
+
+// Validate reports whether the configuration can be used.
+func (cfg Config) Validate() error {
+	if cfg.KeyFile != "" && !filepath.IsAbs(cfg.KeyFile) {
+		return fmt.Errorf("key file %q must be an absolute path", cfg.KeyFile)
+	}
+
+	return nil
+}
+
+// Path returns the location of the key file.
+func (cfg Config) Path() (string, error) {
+	if cfg.KeyFile != "" {
+		return cfg.KeyFile, nil
+	}
+
+	configDir, err := paths.DefaultConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("determine config directory: %w", err)
+	}
+
+	return filepath.Join(configDir, KeyFilename), nil
+}
+
+// Cipher encrypts data to the recipients of a data directory and decrypts it
+// with a secret key.
+type Cipher struct {
+	identities []age.Identity
+	recipients []age.Recipient
+}
+
+var _ storage.Cipher = (*Cipher)(nil)
+
+// Encrypt implements storage.Cipher.
+func (c *Cipher) Encrypt(plaintext []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, c.recipients...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decrypt implements storage.Cipher.
+func (c *Cipher) Decrypt(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+
+	r, err := age.Decrypt(bytes.NewReader(data), c.identities...)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(r)
+}
+
+// IsEncrypted reports whether data was encrypted by age.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, header)
+}
+
+// Encrypted reports whether the data directory is encrypted.
+func Encrypted(dataDir string) (bool, error) {
+	_, err := os.Stat(filepath.Join(dataDir, RecipientsFilename))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, os.ErrNotExist):
+		return false, nil
+	default:
+		return false, fmt.Errorf("read %s: %w", RecipientsFilename, err)
+	}
+}
+
+// Open returns the cipher for the data directory: storage.Plaintext if it is
+// not encrypted, or one using the configured secret key if it is.
+func Open(dataDir string, cfg Config) (storage.Cipher, error) {
+	encrypted, err := Encrypted(dataDir)
+	if err != nil || !encrypted {
+		return storage.Plaintext, err
+	}
+
+	key, err := LoadKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCipher(dataDir, key)
+}
+
+// NewCipher returns a cipher that encrypts to the recipients of the data
+// directory and decrypts with key.
+func NewCipher(dataDir string, key *age.X25519Identity) (*Cipher, error) {
+	f, err := os.Open(filepath.Join(dataDir, RecipientsFilename))
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", RecipientsFilename, err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	recipients, err := age.ParseRecipients(f)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", RecipientsFilename, err)
+	}
+
+	return &Cipher{identities: []age.Identity{key}, recipients: recipients}, nil
+}
+
+// LoadKey returns the secret key from the T_KEY environment variable, or the
+// key file when it is unset.
+func LoadKey(cfg Config) (*age.X25519Identity, error) {
+	if value := strings.TrimSpace(os.Getenv(EnvVar)); value != "" {
+		key, err := age.ParseX25519Identity(value)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", EnvVar, err)
+		}
+
+		return key, nil
+	}
+
+	path, err := cfg.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: set %s or save the key to %s", ErrNoKey, EnvVar, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read key file: %w", err)
+	}
+
+	for line := range strings.Lines(string(data)) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, err := age.ParseX25519Identity(line)
+		if err != nil {
+			return nil, fmt.Errorf("parse key file %s: %w", path, err)
+		}
+
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("%w in %s", ErrNoKey, path)
+}
+
+// GenerateKey creates a new secret key and saves it to the key file, in the
+// format written by age-keygen. It never replaces an existing key file.
+func GenerateKey(cfg Config, now time.Time) (*age.X25519Identity, string, error) {
+	path, err := cfg.Path()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, err := age.GenerateX25519Identity()
+	if err != nil {
+		return nil, "", fmt.Errorf("generate key: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, "", fmt.Errorf("create key directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, "", fmt.Errorf("create key file: %w", err)
+	}
+
+	_, err = fmt.Fprintf(f, "# created: %s\n# public key: %s\n%s\n",
+		now.UTC().Format(time.RFC3339), key.Recipient(), key)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, "", fmt.Errorf("write key file: %w", err)
+	}
+
+	return key, path, nil
+}
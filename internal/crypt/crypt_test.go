@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package crypt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unfunco/t/internal/backup"
+	"github.com/unfunco/t/internal/clock"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/oplog"
+	"github.com/unfunco/t/internal/storage"
+)
+
+var now = time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC)
+
+// open returns the storage used by t for dataDir, encrypted according to cfg.
+func open(t *testing.T, dataDir string, cfg Config) *oplog.Storage {
+	t.Helper()
+
+	c, err := Open(dataDir, cfg)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	file, err := storage.NewFileStorageWithCipher(dataDir, c)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+
+	return oplog.NewStorageWithCipher(file, dataDir, c, clock.Fixed(now))
+}
+
+func TestEncryptAndDecrypt(t *testing.T) {
+	dataDir := t.TempDir()
+	cfg := Config{KeyFile: filepath.Join(t.TempDir(), "key.txt")}
+	t.Setenv(EnvVar, "")
+
+	today := &model.TodoList{Name: "Today", Todos: []model.Todo{model.NewTodo("Call Mum", "", nil, now)}}
+	if err := open(t, dataDir, cfg).SaveList(list.Today(), today); err != nil {
+		t.Fatalf("SaveList() error = %v", err)
+	}
+	if _, err := backup.Create(dataDir, now); err != nil {
+		t.Fatalf("backup.Create() error = %v", err)
+	}
+
+	key, path, err := GenerateKey(cfg, now)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	if path != cfg.KeyFile {
+		t.Fatalf("GenerateKey() path = %q, want %q", path, cfg.KeyFile)
+	}
+	if _, _, err := GenerateKey(cfg, now); err == nil {
+		t.Fatalf("GenerateKey() replaced the existing key")
+	}
+
+	if err := Encrypt(dataDir, key); err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if err := Encrypt(dataDir, key); !errors.Is(err, ErrEncrypted) {
+		t.Fatalf("Encrypt() twice error = %v, want ErrEncrypted", err)
+	}
+
+	snapshots, err := backup.List(dataDir)
+	if err != nil || len(snapshots) != 1 {
+		t.Fatalf("backup.List() = %v, %v", snapshots, err)
+	}
+	files := []string{
+		filepath.Join(dataDir, list.Today().Filename),
+		filepath.Join(dataDir, backup.Dir, snapshots[0].Name, list.Today().Filename),
+		filepath.Join(dataDir, oplog.Filename),
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", file, err)
+		}
+		if strings.Contains(string(data), "Call Mum") {
+			t.Fatalf("%s was not encrypted:\n%s", filepath.Base(file), data)
+		}
+	}
+
+	if _, err := Open(dataDir, Config{KeyFile: filepath.Join(t.TempDir(), "missing.txt")}); !errors.Is(err, ErrNoKey) {
+		t.Fatalf("Open() without a key error = %v, want ErrNoKey", err)
+	}
+
+	s := open(t, dataDir, cfg)
+	loaded, err := s.LoadList(list.Today())
+	if err != nil {
+		t.Fatalf("LoadList() error = %v", err)
+	}
+	if len(loaded.Todos) != 1 || loaded.Todos[0].Title != "Call Mum" {
+		t.Fatalf("LoadList() = %+v, want Call Mum", loaded.Todos)
+	}
+
+	loaded.Todos = append(loaded.Todos, model.NewTodo("Water the plants", "", nil, now))
+	if err := s.SaveList(list.Today(), loaded); err != nil {
+		t.Fatalf("SaveList() error = %v", err)
+	}
+
+	events, err := oplog.Read(dataDir, s.Cipher())
+	if err != nil || len(events) != 2 {
+		t.Fatalf("oplog.Read() = %d events, %v; want 2", len(events), err)
+	}
+
+	t.Setenv(EnvVar, key.String())
+	if err := Decrypt(dataDir, key); err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if err := Decrypt(dataDir, key); !errors.Is(err, ErrNotEncrypted) {
+		t.Fatalf("Decrypt() twice error = %v, want ErrNotEncrypted", err)
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", file, err)
+		}
+		if !strings.Contains(string(data), "Call Mum") {
+			t.Fatalf("%s was not decrypted:\n%s", filepath.Base(file), data)
+		}
+	}
+
+	events, err = oplog.Read(dataDir, storage.Plaintext)
+	if err != nil || len(events) != 2 {
+		t.Fatalf("oplog.Read() = %d events, %v; want 2", len(events), err)
+	}
+}
+
+func TestLoadKeyPrefersEnvironment(t *testing.T) {
+	cfg := Config{KeyFile: filepath.Join(t.TempDir(), "key.txt")}
+	t.Setenv(EnvVar, "")
+
+	saved, _, err := GenerateKey(cfg, now)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	key, err := LoadKey(cfg)
+	if err != nil || key.String() != saved.String() {
+		t.Fatalf("LoadKey() = %v, %v; want the saved key", key, err)
+	}
+
+	other, _, err := GenerateKey(Config{KeyFile: filepath.Join(t.TempDir(), "key.txt")}, now)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	t.Setenv(EnvVar, other.String())
+
+	key, err = LoadKey(cfg)
+	if err != nil || key.String() != other.String() {
+		t.Fatalf("LoadKey() = %v, %v; want the key from %s", key, err, EnvVar)
+	}
+
+	t.Setenv(EnvVar, "not a key")
+	if _, err := LoadKey(cfg); err == nil {
+		t.Fatalf("LoadKey() accepted an invalid key")
+	}
+}
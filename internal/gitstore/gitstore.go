@@ -0,0 +1,301 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+// Package gitstore versions the list files in a git repository inside the data
+// directory, committing every save, and synchronises it with a remote.
+package gitstore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/unfunco/t/internal/clock"
+	"github.com/unfunco/t/internal/crypt"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/storage"
+)
+
+// DefaultBranch is the branch committed to when none is configured.
+const DefaultBranch = "main"
+
+// remoteName is the name given to the configured remote in the repository.
+const remoteName = "origin"
+
+// ignored lists the files in the data directory that are local to a machine
+// and never committed.
+var ignored = []string{
+	"/backups/",
+	"/events.jsonl",
+	"*.bak",
+	"*.tmp-*",
+}
+
+// ErrNoRemote is returned when synchronising without a remote configured.
+var ErrNoRemote = errors.New("no git remote configured")
+
+// Config represents the raw git storage configuration values.
+type Config struct {
+	// Enabled versions the data directory in git.
+	Enabled bool `json:"enabled"`
+	// Remote is the URL or path of the repository t sync pulls from and
+	// pushes to.
+	Remote string `json:"remote,omitempty"`
+	// Branch is the branch to commit to. Defaults to main.
+	Branch string `json:"branch,omitempty"`
+}
+
+// DefaultConfig returns the built-in git storage configuration.
+func DefaultConfig() Config {
+	return Config{Branch: DefaultBranch}
+}
+
+// Validate reports whether the configuration can be used.
+func (cfg Config) Validate() error {
+	branch := cfg.withDefaults().Branch
+	if err := plumbing.NewBranchReferenceName(branch).Validate(); err != nil {
+		return fmt.Errorf("invalid git branch %q", branch)
+	}
+
+	return nil
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.Branch == "" {
+		cfg.Branch = DefaultBranch
+	}
+
+	return cfg
+}
+
+// Storage wraps file storage so that every save is committed to a git
+// repository in the data directory.
+type Storage struct {
+	inner   storage.Storage
+	dataDir string
+	cipher  storage.Cipher
+	cfg     Config
+	clock   clock.Clock
+	repo    *git.Repository
+}
+
+var _ storage.Storage = (*Storage)(nil)
+
+// Open wraps inner, which stores its lists in dataDir encrypted with c,
+// creating the repository and committing any existing lists if it does not
+// exist yet.
+func Open(inner storage.Storage, dataDir string, c storage.Cipher, cfg Config, clk clock.Clock) (*Storage, error) {
+	s := &Storage{
+		inner:   inner,
+		dataDir: dataDir,
+		cipher:  c,
+		cfg:     cfg.withDefaults(),
+		clock:   clk,
+	}
+
+	repo, err := git.PlainOpen(dataDir)
+	switch {
+	case err == nil:
+		s.repo = repo
+		return s, nil
+	case !errors.Is(err, git.ErrRepositoryNotExists):
+		return nil, fmt.Errorf("open git repository: %w", err)
+	}
+
+	if err := os.MkdirAll(dataDir, 0o700); err != nil {
+		return nil, fmt.Errorf("create data directory: %w", err)
+	}
+
+	repo, err = git.PlainInitWithOptions(dataDir, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{
+			DefaultBranch: plumbing.NewBranchReferenceName(s.cfg.Branch),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create git repository: %w", err)
+	}
+	s.repo = repo
+
+	if err := WriteIgnore(dataDir); err != nil {
+		return nil, err
+	}
+
+	if err := s.initialCommit(); err != nil {
+		return nil, err
+	}
+
+	if err := s.commit("Start tracking todos", listFiles()...); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// HoldsLists reports whether the git repository in dataDir, if there is one,
+// has committed any of the list files.
+func HoldsLists(dataDir string) (bool, error) {
+	repo, err := git.PlainOpen(dataDir)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("open git repository: %w", err)
+	}
+
+	head, err := repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read git HEAD: %w", err)
+	}
+
+	commits, err := repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return false, fmt.Errorf("read git history: %w", err)
+	}
+	defer commits.Close()
+
+	found := false
+	err = commits.ForEach(func(c *object.Commit) error {
+		for _, def := range list.Default() {
+			if _, err := c.File(def.Filename); err == nil {
+				found = true
+				return storer.ErrStop
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("read git history: %w", err)
+	}
+
+	return found, nil
+}
+
+// CommitLists commits every list file as it is now, along with the file
+// naming the keys they are encrypted to, such as after they were encrypted.
+func (s *Storage) CommitLists(message string) error {
+	return s.commit(message, listFiles()...)
+}
+
+// WriteIgnore writes a .gitignore file to dataDir that keeps the files local
+// to a machine out of any git repository holding it.
+func WriteIgnore(dataDir string) error {
+	ignore := strings.Join(ignored, "\n") + "\n"
+	if err := os.WriteFile(filepath.Join(dataDir, ".gitignore"), []byte(ignore), 0o600); err != nil {
+		return fmt.Errorf("write .gitignore: %w", err)
+	}
+
+	return nil
+}
+
+// LoadList implements storage.Storage.
+func (s *Storage) LoadList(def list.Definition) (*model.TodoList, error) {
+	return s.inner.LoadList(def)
+}
+
+// SaveList implements storage.Storage, committing the list once it is saved.
+func (s *Storage) SaveList(def list.Definition, todoList *model.TodoList) error {
+	if err := s.inner.SaveList(def, todoList); err != nil {
+		return err
+	}
+
+	return s.commit("Update "+def.Name, def.Filename)
+}
+
+// initialCommit commits the .gitignore file on its own, with a fixed author
+// and time, so that every repository created by t starts from the same commit
+// and can be synchronised with any other.
+func (s *Storage) initialCommit() error {
+	w, err := s.repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("open git worktree: %w", err)
+	}
+
+	if _, err := w.Add(".gitignore"); err != nil {
+		return fmt.Errorf("stage .gitignore: %w", err)
+	}
+
+	root := &object.Signature{Name: "t", When: time.Unix(0, 0).UTC()}
+	if _, err := w.Commit("Create todo repository", &git.CommitOptions{Author: root}); err != nil {
+		return fmt.Errorf("commit .gitignore: %w", err)
+	}
+
+	return nil
+}
+
+// commit stages the named files and commits them, doing nothing if none of
+// them changed.
+func (s *Storage) commit(message string, files ...string) error {
+	_, err := s.commitWithParents(message, nil, files...)
+	return err
+}
+
+func (s *Storage) commitWithParents(message string, parents []plumbing.Hash, files ...string) (plumbing.Hash, error) {
+	w, err := s.repo.Worktree()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("open git worktree: %w", err)
+	}
+
+	for _, file := range files {
+		if _, err := os.Stat(filepath.Join(s.dataDir, file)); errors.Is(err, os.ErrNotExist) {
+			if _, err := w.Remove(file); err != nil && !errors.Is(err, index.ErrEntryNotFound) {
+				return plumbing.ZeroHash, fmt.Errorf("stage removal of %s: %w", file, err)
+			}
+			continue
+		}
+		if _, err := w.Add(file); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("stage %s: %w", file, err)
+		}
+	}
+
+	hash, err := w.Commit(message, &git.CommitOptions{
+		Author:  s.signature(),
+		Parents: parents,
+	})
+	if errors.Is(err, git.ErrEmptyCommit) {
+		return plumbing.ZeroHash, nil
+	}
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("commit %s: %w", strings.Join(files, ", "), err)
+	}
+
+	return hash, nil
+}
+
+// signature returns the author of commits, taken from the git configuration
+// when it names one.
+func (s *Storage) signature() *object.Signature {
+	sig := &object.Signature{Name: "t", When: s.clock.Now()}
+
+	if cfg, err := s.repo.ConfigScoped(config.SystemScope); err == nil {
+		if cfg.User.Name != "" {
+			sig.Name = cfg.User.Name
+		}
+		sig.Email = cfg.User.Email
+	}
+
+	return sig
+}
+
+// listFiles returns the names of the default list files, along with the file
+// naming the keys they are encrypted to.
+func listFiles() []string {
+	var files []string
+	for _, def := range list.Default() {
+		files = append(files, def.Filename)
+	}
+
+	return append(files, crypt.RecipientsFilename)
+}
@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package gitstore
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/unfunco/t/internal/clock"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/storage"
+)
+
+var now = time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC)
+
+func open(t *testing.T, dataDir, remote string) *Storage {
+	t.Helper()
+
+	file, err := storage.NewFileStorageWithDir(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create file storage: %v", err)
+	}
+
+	s, err := Open(file, dataDir, storage.Plaintext, Config{Enabled: true, Remote: remote}, clock.Fixed(now))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	return s
+}
+
+func load(t *testing.T, s *Storage) *model.TodoList {
+	t.Helper()
+
+	l, err := s.LoadList(list.Today())
+	if err != nil {
+		t.Fatalf("LoadList() error = %v", err)
+	}
+
+	return l
+}
+
+func save(t *testing.T, s *Storage, l *model.TodoList) {
+	t.Helper()
+
+	if err := s.SaveList(list.Today(), l); err != nil {
+		t.Fatalf("SaveList() error = %v", err)
+	}
+}
+
+func sync(t *testing.T, s *Storage) SyncResult {
+	t.Helper()
+
+	result, err := s.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	return result
+}
+
+func commitCount(t *testing.T, dir string) int {
+	t.Helper()
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("failed to open repository: %v", err)
+	}
+
+	iter, err := repo.Log(&git.LogOptions{})
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+
+	count := 0
+	for _, err := iter.Next(); err == nil; _, err = iter.Next() {
+		count++
+	}
+
+	return count
+}
+
+func TestSaveListCommitsChanges(t *testing.T) {
+	dir := t.TempDir()
+	s := open(t, dir, "")
+
+	l := load(t, s)
+	l.Todos = append(l.Todos, model.NewTodo("Buy milk", "", nil, now))
+	save(t, s, l)
+	save(t, s, l)
+
+	// The initial commit of .gitignore, then one for the change; saving the
+	// same list again commits nothing.
+	if got := commitCount(t, dir); got != 2 {
+		t.Fatalf("expected 2 commits, got %d", got)
+	}
+
+	if _, err := s.Sync(context.Background()); err != ErrNoRemote {
+		t.Fatalf("expected ErrNoRemote, got %v", err)
+	}
+}
+
+func TestSyncPushesPullsAndMerges(t *testing.T) {
+	remote := filepath.Join(t.TempDir(), "todos.git")
+	if _, err := git.PlainInit(remote, true); err != nil {
+		t.Fatalf("failed to create remote: %v", err)
+	}
+
+	laptopDir := t.TempDir()
+	laptop := open(t, laptopDir, remote)
+	desktop := open(t, t.TempDir(), remote)
+
+	l := load(t, laptop)
+	l.Todos = append(l.Todos, model.NewTodo("Buy milk", "", nil, now))
+	save(t, laptop, l)
+
+	if result := sync(t, laptop); !result.Pushed || result.Pulled {
+		t.Fatalf("expected the first sync to push, got %+v", result)
+	}
+
+	if result := sync(t, desktop); !result.Pulled || result.Pushed {
+		t.Fatalf("expected the desktop to pull, got %+v", result)
+	}
+	if got := load(t, desktop); len(got.Todos) != 1 || got.Todos[0].Title != "Buy milk" {
+		t.Fatalf("expected the pulled todo, got %+v", got.Todos)
+	}
+
+	// Change the same list on both machines.
+	onLaptop := load(t, laptop)
+	onLaptop.Todos[0].Title = "Buy oat milk"
+	onLaptop.Todos = append(onLaptop.Todos, model.NewTodo("Call Mum", "", nil, now.Add(time.Minute)))
+	save(t, laptop, onLaptop)
+
+	onDesktop := load(t, desktop)
+	onDesktop.Todos[0].ToggleCompleted(now)
+	onDesktop.Todos = append(onDesktop.Todos, model.NewTodo("Walk the dog", "", nil, now.Add(2*time.Minute)))
+	save(t, desktop, onDesktop)
+
+	sync(t, laptop)
+
+	if result := sync(t, desktop); !result.Merged || !result.Pushed {
+		t.Fatalf("expected the desktop to merge and push, got %+v", result)
+	}
+
+	if result := sync(t, laptop); !result.Pulled || result.Merged {
+		t.Fatalf("expected the laptop to fast-forward, got %+v", result)
+	}
+
+	for name, s := range map[string]*Storage{"laptop": laptop, "desktop": desktop} {
+		got := load(t, s)
+		if len(got.Todos) != 3 {
+			t.Fatalf("%s: expected 3 todos, got %+v", name, got.Todos)
+		}
+		if got.Todos[0].Title != "Buy oat milk" || !got.Todos[0].Completed {
+			t.Fatalf("%s: expected both changes to the first todo, got %+v", name, got.Todos[0])
+		}
+	}
+
+	if result := sync(t, laptop); result != (SyncResult{}) {
+		t.Fatalf("expected nothing to sync, got %+v", result)
+	}
+}
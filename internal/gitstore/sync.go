@@ -0,0 +1,276 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package gitstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/merge"
+	"github.com/unfunco/t/internal/storage"
+)
+
+// SyncResult describes what a sync did.
+type SyncResult struct {
+	// Pulled reports whether changes were brought in from the remote.
+	Pulled bool
+	// Merged reports whether local and remote changes had to be merged.
+	Merged bool
+	// Pushed reports whether changes were sent to the remote.
+	Pushed bool
+}
+
+// Remote returns the configured remote.
+func (s *Storage) Remote() string {
+	return s.cfg.Remote
+}
+
+// SetRemote changes the remote synchronised with, for this Storage only.
+func (s *Storage) SetRemote(remote string) {
+	s.cfg.Remote = remote
+}
+
+// Sync pulls changes from the remote and pushes local ones to it. Lists that
+// changed on both sides are merged todo by todo, keeping the changes made on
+// each side, and the merge is committed before being pushed.
+func (s *Storage) Sync(ctx context.Context) (SyncResult, error) {
+	var result SyncResult
+
+	if s.cfg.Remote == "" {
+		return result, ErrNoRemote
+	}
+
+	if err := s.configureRemote(); err != nil {
+		return result, err
+	}
+
+	branch := plumbing.NewBranchReferenceName(s.cfg.Branch)
+	tracking := plumbing.NewRemoteReferenceName(remoteName, s.cfg.Branch)
+
+	err := s.repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: remoteName,
+		RefSpecs:   []config.RefSpec{config.RefSpec("+" + branch + ":" + tracking)},
+	})
+	switch {
+	case err == nil, errors.Is(err, git.NoErrAlreadyUpToDate):
+	case errors.Is(err, transport.ErrEmptyRemoteRepository), errors.Is(err, git.NoMatchingRefSpecError{}):
+		// The remote has nothing to pull yet.
+	default:
+		return result, fmt.Errorf("fetch from %s: %w", s.cfg.Remote, err)
+	}
+
+	ours, err := s.resolve(branch)
+	if err != nil {
+		return result, err
+	}
+	theirs, err := s.resolve(tracking)
+	if err != nil {
+		return result, err
+	}
+
+	switch {
+	case theirs == nil && ours == nil:
+		return result, nil
+	case theirs == nil:
+		result.Pushed = true
+	case ours == nil:
+		result.Pulled = true
+	case ours.Hash == theirs.Hash:
+		return result, nil
+	default:
+		behind, err := ours.IsAncestor(theirs)
+		if err != nil {
+			return result, fmt.Errorf("compare with %s: %w", s.cfg.Remote, err)
+		}
+		ahead, err := theirs.IsAncestor(ours)
+		if err != nil {
+			return result, fmt.Errorf("compare with %s: %w", s.cfg.Remote, err)
+		}
+
+		switch {
+		case behind:
+			result.Pulled = true
+		case ahead:
+			result.Pushed = true
+		default:
+			if err := s.merge(ours, theirs); err != nil {
+				return result, err
+			}
+			result.Pulled, result.Merged, result.Pushed = true, true, true
+		}
+	}
+
+	if result.Pulled && !result.Merged {
+		if err := s.fastForward(branch, theirs.Hash); err != nil {
+			return result, err
+		}
+	}
+
+	if result.Pushed {
+		err := s.repo.PushContext(ctx, &git.PushOptions{
+			RemoteName: remoteName,
+			RefSpecs:   []config.RefSpec{config.RefSpec(branch + ":" + branch)},
+		})
+		if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return result, fmt.Errorf("push to %s: %w", s.cfg.Remote, err)
+		}
+	}
+
+	return result, nil
+}
+
+// configureRemote points the remote at the configured URL, creating it if
+// needed.
+func (s *Storage) configureRemote() error {
+	url := s.cfg.Remote
+	if endpoint, err := transport.NewEndpoint(url); err == nil && endpoint.Protocol == "file" && !filepath.IsAbs(url) {
+		abs, err := filepath.Abs(url)
+		if err != nil {
+			return fmt.Errorf("resolve remote %s: %w", url, err)
+		}
+		url = abs
+	}
+
+	remote, err := s.repo.Remote(remoteName)
+	switch {
+	case errors.Is(err, git.ErrRemoteNotFound):
+	case err != nil:
+		return fmt.Errorf("read git remote: %w", err)
+	case len(remote.Config().URLs) == 1 && remote.Config().URLs[0] == url:
+		return nil
+	default:
+		if err := s.repo.DeleteRemote(remoteName); err != nil {
+			return fmt.Errorf("update git remote: %w", err)
+		}
+	}
+
+	if _, err := s.repo.CreateRemote(&config.RemoteConfig{Name: remoteName, URLs: []string{url}}); err != nil {
+		return fmt.Errorf("add git remote: %w", err)
+	}
+
+	return nil
+}
+
+// resolve returns the commit a reference points to, or nil if the reference
+// does not exist.
+func (s *Storage) resolve(name plumbing.ReferenceName) (*object.Commit, error) {
+	ref, err := s.repo.Reference(name, true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", name.Short(), err)
+	}
+
+	commit, err := s.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("read commit %s: %w", ref.Hash(), err)
+	}
+
+	return commit, nil
+}
+
+// fastForward moves the branch to the given commit and checks it out.
+func (s *Storage) fastForward(branch plumbing.ReferenceName, hash plumbing.Hash) error {
+	if err := s.repo.Storer.SetReference(plumbing.NewHashReference(branch, hash)); err != nil {
+		return fmt.Errorf("update %s: %w", branch.Short(), err)
+	}
+
+	w, err := s.repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("open git worktree: %w", err)
+	}
+
+	if err := w.Reset(&git.ResetOptions{Commit: hash, Mode: git.HardReset}); err != nil {
+		return fmt.Errorf("check out %s: %w", hash, err)
+	}
+
+	return nil
+}
+
+// merge merges the lists in theirs into those in ours, which is checked out,
+// and commits the result.
+func (s *Storage) merge(ours, theirs *object.Commit) error {
+	var base *object.Commit
+	bases, err := ours.MergeBase(theirs)
+	if err != nil {
+		return fmt.Errorf("find merge base: %w", err)
+	}
+	if len(bases) > 0 {
+		base = bases[0]
+	}
+
+	baseLists, err := s.readLists(base)
+	if err != nil {
+		return err
+	}
+	ourLists, err := s.readLists(ours)
+	if err != nil {
+		return err
+	}
+	theirLists, err := s.readLists(theirs)
+	if err != nil {
+		return err
+	}
+
+	merged := merge.ThreeWay(baseLists, ourLists, theirLists)
+	storage.EnsureUniqueIDs(merged[list.TodayID], merged[list.TomorrowID], merged[list.TodosID])
+
+	for _, def := range list.Default() {
+		if err := s.inner.SaveList(def, merged[def.ID]); err != nil {
+			return fmt.Errorf("save merged %s list: %w", def.Name, err)
+		}
+	}
+
+	parents := []plumbing.Hash{ours.Hash, theirs.Hash}
+	if _, err := s.commitWithParents("Merge changes from "+s.cfg.Remote, parents, listFiles()...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// readLists reads the default lists as they were in a commit. A nil commit
+// has empty lists.
+func (s *Storage) readLists(commit *object.Commit) (merge.Lists, error) {
+	lists := make(merge.Lists)
+	for _, def := range list.Default() {
+		var data []byte
+		if commit != nil {
+			file, err := commit.File(def.Filename)
+			switch {
+			case errors.Is(err, object.ErrFileNotFound):
+			case err != nil:
+				return nil, fmt.Errorf("read %s at %s: %w", def.Filename, commit.Hash, err)
+			default:
+				contents, err := file.Contents()
+				if err != nil {
+					return nil, fmt.Errorf("read %s at %s: %w", def.Filename, commit.Hash, err)
+				}
+				data = []byte(contents)
+			}
+		}
+
+		data, err := s.cipher.Decrypt(data)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt %s: %w", def.Filename, err)
+		}
+
+		l, err := storage.DecodeList(def, data)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", def.Filename, err)
+		}
+		lists[def.ID] = l
+	}
+
+	return lists, nil
+}
@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package gitstore
+
+import (
+	"context"
+
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+)
+
+func init() {
+	// Serve repositories on the local filesystem in-process, rather than by
+	// running git-upload-pack and git-receive-pack, so that syncing with a
+	// local or mounted repository does not need git to be installed.
+	client.InstallProtocol("file", localTransport{server.DefaultServer})
+}
+
+// localTransport serves local repositories with the go-git server, which
+// fails a fetch when the client has commits the server does not. Those are
+// dropped from the request, as git-upload-pack would ignore them.
+type localTransport struct {
+	transport.Transport
+}
+
+func (t localTransport) NewUploadPackSession(ep *transport.Endpoint, auth transport.AuthMethod) (transport.UploadPackSession, error) {
+	session, err := t.Transport.NewUploadPackSession(ep, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := server.DefaultLoader.Load(ep)
+	if err != nil {
+		_ = session.Close()
+		return nil, err
+	}
+
+	return knownHaves{UploadPackSession: session, objects: objects}, nil
+}
+
+type knownHaves struct {
+	transport.UploadPackSession
+	objects storer.EncodedObjectStorer
+}
+
+func (s knownHaves) UploadPack(ctx context.Context, req *packp.UploadPackRequest) (*packp.UploadPackResponse, error) {
+	haves := req.Haves[:0:0]
+	for _, h := range req.Haves {
+		if s.objects.HasEncodedObject(h) == nil {
+			haves = append(haves, h)
+		}
+	}
+	req.Haves = haves
+
+	return s.UploadPackSession.UploadPack(ctx, req)
+}
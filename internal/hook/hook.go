@@ -0,0 +1,295 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+// Package hook runs user-configured executables when todos change, such as a
+// script posting to a webhook whenever a todo is completed.
+//
+// Each hook receives a JSON description of the change on standard input, and
+// its output is written to standard error so that it never mixes with the
+// output of the command that triggered it. Pre-hooks run before a change is
+// saved and, under the abort failure policy, a failing pre-hook cancels it.
+// Every other hook runs once the change has been saved, so a failing hook is
+// only reported.
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+)
+
+// EnvVar is the environment variable naming the event a hook was run for.
+const EnvVar = "T_HOOK_EVENT"
+
+// DefaultTimeout is how long a hook may run when no timeout is configured.
+const DefaultTimeout = 10 * time.Second
+
+// waitDelay is how long to wait for a hook's output after it is killed, in
+// case it started processes of its own that hold on to it.
+const waitDelay = time.Second
+
+// Event names a kind of change that hooks can be run for.
+type Event string
+
+const (
+	// EventAdd is a todo being added to a list.
+	EventAdd Event = "on-add"
+	// EventComplete is a todo being marked as done.
+	EventComplete Event = "on-complete"
+	// EventMove is a todo being moved to another list.
+	EventMove Event = "on-move"
+	// EventAutomation is a todo being moved by a scheduled automation, such
+	// as from the Tomorrow list to the Today list when the day begins.
+	EventAutomation Event = "on-automation"
+
+	// EventPreAdd is a todo about to be added to a list.
+	EventPreAdd Event = "pre-add"
+	// EventPreComplete is a todo about to be marked as done.
+	EventPreComplete Event = "pre-complete"
+	// EventPreMove is a todo about to be moved to another list.
+	EventPreMove Event = "pre-move"
+	// EventPreAutomation is a todo about to be moved by a scheduled
+	// automation.
+	EventPreAutomation Event = "pre-automation"
+)
+
+// events lists every event that hooks can be run for.
+var events = []Event{
+	EventAdd, EventComplete, EventMove, EventAutomation,
+	EventPreAdd, EventPreComplete, EventPreMove, EventPreAutomation,
+}
+
+// Pre reports whether the event's hooks run before the change is saved.
+func (e Event) Pre() bool {
+	return strings.HasPrefix(string(e), "pre-")
+}
+
+// Policy determines whether a hook that fails or times out is reported.
+type Policy string
+
+const (
+	// PolicyWarn reports the failure on standard error.
+	PolicyWarn Policy = "warn"
+	// PolicyIgnore carries on without reporting the failure.
+	PolicyIgnore Policy = "ignore"
+	// PolicyAbort cancels the change when a pre-hook fails, and reports other
+	// failed hooks as warn does.
+	PolicyAbort Policy = "abort"
+)
+
+// Config represents the raw hook configuration values.
+type Config struct {
+	// OnAdd lists the executables run when a todo is added.
+	OnAdd []string `json:"on-add,omitempty"`
+	// OnComplete lists the executables run when a todo is completed.
+	OnComplete []string `json:"on-complete,omitempty"`
+	// OnMove lists the executables run when a todo is moved to another list.
+	OnMove []string `json:"on-move,omitempty"`
+	// OnAutomation lists the executables run when an automation moves a todo.
+	OnAutomation []string `json:"on-automation,omitempty"`
+	// PreAdd lists the executables run before a todo is added.
+	PreAdd []string `json:"pre-add,omitempty"`
+	// PreComplete lists the executables run before a todo is completed.
+	PreComplete []string `json:"pre-complete,omitempty"`
+	// PreMove lists the executables run before a todo is moved to another
+	// list.
+	PreMove []string `json:"pre-move,omitempty"`
+	// PreAutomation lists the executables run before an automation moves a
+	// todo.
+	PreAutomation []string `json:"pre-automation,omitempty"`
+	// Timeout is how long each hook may run, such as "5s". Defaults to 10s.
+	Timeout string `json:"timeout,omitempty"`
+	// Failure is the policy applied when a hook fails. Defaults to warn.
+	Failure Policy `json:"failure,omitempty"`
+}
+
+// DefaultConfig returns the built-in hook configuration, which runs no hooks.
+func DefaultConfig() Config {
+	return Config{}
+}
+
+// Validate reports whether the configuration can be used.
+func (cfg Config) Validate() error {
+	for _, event := range events {
+		for _, command := range cfg.Commands(event) {
+			if command == "" {
+				return fmt.Errorf("%s hook cannot be blank", event)
+			}
+		}
+	}
+
+	if cfg.Timeout != "" {
+		timeout, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid hook timeout %q", cfg.Timeout)
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("hook timeout must be positive, got %q", cfg.Timeout)
+		}
+	}
+
+	switch cfg.Failure {
+	case "", PolicyWarn, PolicyIgnore, PolicyAbort:
+	default:
+		return fmt.Errorf("unknown hook failure policy %q", cfg.Failure)
+	}
+
+	return nil
+}
+
+// Commands returns the executables run for event.
+func (cfg Config) Commands(event Event) []string {
+	switch event {
+	case EventAdd:
+		return cfg.OnAdd
+	case EventComplete:
+		return cfg.OnComplete
+	case EventMove:
+		return cfg.OnMove
+	case EventAutomation:
+		return cfg.OnAutomation
+	case EventPreAdd:
+		return cfg.PreAdd
+	case EventPreComplete:
+		return cfg.PreComplete
+	case EventPreMove:
+		return cfg.PreMove
+	case EventPreAutomation:
+		return cfg.PreAutomation
+	default:
+		return nil
+	}
+}
+
+// Empty reports whether no hooks are configured.
+func (cfg Config) Empty() bool {
+	for _, event := range events {
+		if len(cfg.Commands(event)) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.Timeout == "" {
+		cfg.Timeout = DefaultTimeout.String()
+	}
+	if cfg.Failure == "" {
+		cfg.Failure = PolicyWarn
+	}
+
+	return cfg
+}
+
+// Payload describes a change to a todo, and is written to a hook's standard
+// input as JSON.
+type Payload struct {
+	Event Event `json:"event"`
+	// Source identifies what made the change, such as cli, tui or automation.
+	Source string `json:"source"`
+	// List is the list holding the todo after the change.
+	List list.ID `json:"list"`
+	// From is the list the todo was moved from, for move and automation
+	// hooks.
+	From list.ID    `json:"from,omitempty"`
+	Todo model.Todo `json:"todo"`
+}
+
+// Runner runs the hooks configured for each event.
+type Runner struct {
+	cfg     Config
+	timeout time.Duration
+	stderr  io.Writer
+}
+
+// NewRunner returns a runner for the hooks in cfg, which must be valid, that
+// writes the output of hooks and any warnings to stderr.
+func NewRunner(cfg Config, stderr io.Writer) *Runner {
+	cfg = cfg.withDefaults()
+
+	timeout, err := time.ParseDuration(cfg.Timeout)
+	if err != nil || timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &Runner{cfg: cfg, timeout: timeout, stderr: stderr}
+}
+
+// Run runs each hook configured for the payload's event in turn. When the
+// failure policy is abort, the first pre-hook to fail stops the others and
+// its error is returned so that the change can be cancelled. Other failures
+// are reported according to the failure policy.
+func (r *Runner) Run(p Payload) error {
+	commands := r.cfg.Commands(p.Event)
+	if len(commands) == 0 {
+		return nil
+	}
+
+	input, err := json.Marshal(p)
+	if err != nil {
+		return r.fail(p.Event, fmt.Errorf("encode %s payload: %w", p.Event, err))
+	}
+
+	for _, command := range commands {
+		if err := r.run(command, p.Event, input); err != nil {
+			if err := r.fail(p.Event, fmt.Errorf("%s hook %s: %w", p.Event, command, err)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// fail returns err if it should cancel the change being made, and otherwise
+// reports it.
+func (r *Runner) fail(event Event, err error) error {
+	if event.Pre() && r.cfg.Failure == PolicyAbort {
+		return err
+	}
+
+	r.report(err)
+
+	return nil
+}
+
+// report reports a failed hook according to the failure policy.
+func (r *Runner) report(err error) {
+	if r.cfg.Failure == PolicyIgnore {
+		return
+	}
+
+	_, _ = fmt.Fprintf(r.stderr, "warning: %v\n", err)
+}
+
+// run runs a single hook with input on its standard input.
+func (r *Runner) run(command string, event Event, input []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command)
+	cmd.Env = append(os.Environ(), EnvVar+"="+string(event))
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = r.stderr
+	cmd.Stderr = r.stderr
+	cmd.WaitDelay = waitDelay
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", r.timeout)
+	}
+
+	return err
+}
@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package hook
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+)
+
+// writeScript writes an executable shell script to dir and returns its path.
+func writeScript(t *testing.T, dir, name, body string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o700); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+
+	return path
+}
+
+func TestRunPassesPayloadOnStdin(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "payload.json")
+	script := writeScript(t, dir, "complete.sh", `cat > "`+out+`"; echo "$T_HOOK_EVENT" >&2`)
+
+	var stderr bytes.Buffer
+	r := NewRunner(Config{OnComplete: []string{script}}, &stderr)
+
+	p := Payload{
+		Event:  EventComplete,
+		Source: "cli",
+		List:   list.TodayID,
+		Todo:   model.Todo{ID: "abc123", Title: "Call Mum", Completed: true},
+	}
+	if err := r.Run(p); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read payload: %v", err)
+	}
+
+	var got Payload
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if got.Event != EventComplete || got.List != list.TodayID || got.Todo.Title != "Call Mum" {
+		t.Errorf("Expected the change to be passed to the hook, got %+v", got)
+	}
+	if strings.TrimSpace(stderr.String()) != string(EventComplete) {
+		t.Errorf("Expected the hook output on stderr, got %q", stderr.String())
+	}
+
+	stderr.Reset()
+	if err := r.Run(Payload{Event: EventAdd}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("Expected nothing to run without hooks, got %q", stderr.String())
+	}
+}
+
+func TestRunFailurePolicy(t *testing.T) {
+	dir := t.TempDir()
+	failing := writeScript(t, dir, "fail.sh", "exit 3")
+	slow := writeScript(t, dir, "slow.sh", "sleep 5")
+
+	tests := []struct {
+		name      string
+		cfg       Config
+		event     Event
+		wantWarns string
+		wantErr   string
+	}{
+		{
+			name:      "warn",
+			cfg:       Config{OnAdd: []string{failing}},
+			event:     EventAdd,
+			wantWarns: "warning: on-add hook " + failing + ": exit status 3",
+		},
+		{
+			name:  "ignore",
+			cfg:   Config{OnAdd: []string{failing}, Failure: PolicyIgnore},
+			event: EventAdd,
+		},
+		{
+			name:      "timeout",
+			cfg:       Config{OnAdd: []string{slow}, Timeout: "100ms"},
+			event:     EventAdd,
+			wantWarns: "warning: on-add hook " + slow + ": timed out after 100ms",
+		},
+		{
+			name:      "warn pre-hook",
+			cfg:       Config{PreAdd: []string{failing}},
+			event:     EventPreAdd,
+			wantWarns: "warning: pre-add hook " + failing + ": exit status 3",
+		},
+		{
+			name:    "abort pre-hook",
+			cfg:     Config{PreAdd: []string{failing, failing}, Failure: PolicyAbort},
+			event:   EventPreAdd,
+			wantErr: "pre-add hook " + failing + ": exit status 3",
+		},
+		{
+			name:      "abort post-hook",
+			cfg:       Config{OnAdd: []string{failing}, Failure: PolicyAbort},
+			event:     EventAdd,
+			wantWarns: "warning: on-add hook " + failing + ": exit status 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			var stderr bytes.Buffer
+			err := NewRunner(tt.cfg, &stderr).Run(Payload{Event: tt.event})
+
+			var gotErr string
+			if err != nil {
+				gotErr = err.Error()
+			}
+			if gotErr != tt.wantErr {
+				t.Errorf("Run() error = %q, want %q", gotErr, tt.wantErr)
+			}
+			if got := strings.TrimSpace(stderr.String()); got != tt.wantWarns {
+				t.Errorf("stderr = %q, want %q", got, tt.wantWarns)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, cfg := range []Config{
+		{OnMove: []string{""}},
+		{Timeout: "soon"},
+		{Timeout: "-1s"},
+		{Failure: "panic"},
+		{Failure: "fail"},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", cfg)
+		}
+	}
+}
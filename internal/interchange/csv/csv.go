@@ -0,0 +1,320 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+// Package csv converts todos to and from comma-separated values, with one row
+// per todo and a header row naming the columns.
+package csv
+
+import (
+	stdcsv "encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unfunco/t/internal/interchange"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+)
+
+// The fields written by Export, in column order. They are also the names used
+// to map columns on import.
+const (
+	FieldList        = "list"
+	FieldID          = "id"
+	FieldTitle       = "title"
+	FieldDescription = "description"
+	FieldCompleted   = "completed"
+	FieldCreatedAt   = "created_at"
+	FieldCompletedAt = "completed_at"
+	FieldDueDate     = "due_date"
+	FieldProject     = "project"
+	FieldTags        = "tags"
+)
+
+// Fields lists every field in column order.
+var Fields = []string{
+	FieldList,
+	FieldID,
+	FieldTitle,
+	FieldDescription,
+	FieldCompleted,
+	FieldCreatedAt,
+	FieldCompletedAt,
+	FieldDueDate,
+	FieldProject,
+	FieldTags,
+}
+
+// timeLayouts are the layouts accepted for timestamps and dates, in the order
+// they are tried. Values without a time zone are read in the calendar's.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	time.DateOnly,
+}
+
+// Export writes every field of every todo, along with the ID of the list it
+// belongs to.
+func Export(w io.Writer, lists []interchange.List, opts interchange.Options) (interchange.Report, error) {
+	var report interchange.Report
+	cw := stdcsv.NewWriter(w)
+
+	if err := cw.Write(Fields); err != nil {
+		return report, fmt.Errorf("write CSV: %w", err)
+	}
+
+	for _, l := range lists {
+		for _, todo := range l.Todos {
+			due := ""
+			if todo.DueDate != nil {
+				due = opts.Calendar.Date(*todo.DueDate).Format(time.DateOnly)
+			}
+
+			record := []string{
+				string(l.Definition.ID),
+				todo.ID,
+				todo.Title,
+				todo.Description,
+				strconv.FormatBool(todo.Completed),
+				formatTime(&todo.CreatedAt),
+				formatTime(todo.CompletedAt),
+				due,
+				todo.Project,
+				strings.Join(todo.Tags, ","),
+			}
+
+			if err := cw.Write(record); err != nil {
+				return report, fmt.Errorf("write CSV: %w", err)
+			}
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return report, fmt.Errorf("write CSV: %w", err)
+	}
+
+	return report, nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+// Import reads todos from CSV with a header row. Columns are matched to
+// fields by name, ignoring case, unless opts.Columns maps a field onto a
+// different column. Only a title column is required. Rows that cannot be read
+// are reported as failures and skipped.
+func Import(r io.Reader, opts interchange.Options) ([]interchange.Item, interchange.Report, error) {
+	var report interchange.Report
+
+	cr := stdcsv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, report, nil
+	}
+	if err != nil {
+		return nil, report, fmt.Errorf("read CSV header: %w", err)
+	}
+
+	columns, err := columnIndexes(header, opts.Columns)
+	if err != nil {
+		return nil, report, err
+	}
+
+	for i, name := range header {
+		if !slices.Contains(columns, i) {
+			report.Drop("column %q", name)
+		}
+	}
+
+	var items []interchange.Item
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *stdcsv.ParseError
+			if errors.As(err, &parseErr) {
+				report.Fail("line %d: %v", parseErr.StartLine, parseErr.Err)
+				continue
+			}
+			return nil, report, fmt.Errorf("read CSV: %w", err)
+		}
+
+		line, _ := cr.FieldPos(0)
+		row := row{record: record, columns: columns}
+
+		item, err := row.item(opts)
+		if err != nil {
+			report.Fail("line %d: %v", line, err)
+			continue
+		}
+
+		item.Line = line
+		items = append(items, item)
+	}
+
+	return items, report, nil
+}
+
+// columnIndexes returns the index of the column holding each field, in the
+// order of Fields, or -1 for fields with no column.
+func columnIndexes(header []string, mapping map[string]string) ([]int, error) {
+	for field := range mapping {
+		if !slices.Contains(Fields, field) {
+			return nil, fmt.Errorf("unknown field %q in column mapping, expected one of %s", field, strings.Join(Fields, ", "))
+		}
+	}
+
+	indexes := make([]int, len(Fields))
+	for i, field := range Fields {
+		name, mapped := mapping[field]
+		if !mapped {
+			name = field
+		}
+
+		indexes[i] = slices.IndexFunc(header, func(h string) bool {
+			return strings.EqualFold(strings.TrimSpace(h), name)
+		})
+
+		if mapped && indexes[i] < 0 {
+			return nil, fmt.Errorf("column %q mapped to %s not found", name, field)
+		}
+	}
+
+	if indexes[slices.Index(Fields, FieldTitle)] < 0 {
+		return nil, fmt.Errorf("no %s column, use a column mapping to choose one", FieldTitle)
+	}
+
+	return indexes, nil
+}
+
+// row is a record along with the index of the column holding each field.
+type row struct {
+	record  []string
+	columns []int
+}
+
+// get returns the trimmed value of a field, or an empty string when it has no
+// column or the row is short.
+func (r row) get(field string) string {
+	i := r.columns[slices.Index(Fields, field)]
+	if i < 0 || i >= len(r.record) {
+		return ""
+	}
+
+	return strings.TrimSpace(r.record[i])
+}
+
+func (r row) item(opts interchange.Options) (interchange.Item, error) {
+	todo := model.Todo{
+		ID:          r.get(FieldID),
+		Title:       r.get(FieldTitle),
+		Description: r.get(FieldDescription),
+		Project:     r.get(FieldProject),
+		CreatedAt:   opts.Now,
+	}
+
+	for _, tag := range strings.Split(r.get(FieldTags), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			todo.Tags = append(todo.Tags, tag)
+		}
+	}
+
+	completed, err := parseCompleted(r.get(FieldCompleted))
+	if err != nil {
+		return interchange.Item{}, err
+	}
+	todo.Completed = completed
+
+	if value := r.get(FieldCreatedAt); value != "" {
+		created, err := parseTime(FieldCreatedAt, value, opts)
+		if err != nil {
+			return interchange.Item{}, err
+		}
+		todo.CreatedAt = created
+	}
+
+	if value := r.get(FieldCompletedAt); value != "" {
+		completedAt, err := parseTime(FieldCompletedAt, value, opts)
+		if err != nil {
+			return interchange.Item{}, err
+		}
+		todo.Completed = true
+		todo.CompletedAt = &completedAt
+	}
+
+	if value := r.get(FieldDueDate); value != "" {
+		due, err := parseTime(FieldDueDate, value, opts)
+		if err != nil {
+			return interchange.Item{}, err
+		}
+		date := opts.Calendar.Date(due.In(opts.Calendar.Location()))
+		todo.DueDate = &date
+	}
+
+	if todo.ID == "" {
+		todo.ID = model.NewID(todo.CreatedAt)
+	}
+
+	listID := interchange.ListForDueDate(opts.Calendar, todo.DueDate, opts.Now)
+	if value := r.get(FieldList); value != "" {
+		def, ok := lookupList(value)
+		if !ok {
+			return interchange.Item{}, fmt.Errorf("unknown list %q", value)
+		}
+		listID = def.ID
+		if todo.DueDate == nil {
+			todo.DueDate = list.DefaultDueDate(opts.Calendar, listID, opts.Now)
+		}
+	}
+
+	return interchange.Item{List: listID, Todo: todo}, nil
+}
+
+// lookupList finds a default list by ID or name, ignoring case.
+func lookupList(value string) (list.Definition, bool) {
+	for _, def := range list.Default() {
+		if strings.EqualFold(value, string(def.ID)) || strings.EqualFold(value, def.Name) {
+			return def, true
+		}
+	}
+
+	return list.Definition{}, false
+}
+
+func parseCompleted(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "", "false", "no", "n", "0":
+		return false, nil
+	case "true", "yes", "y", "1", "x", "done":
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid %s value %q", FieldCompleted, value)
+	}
+}
+
+func parseTime(field, value string, opts interchange.Options) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, opts.Calendar.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid %s value %q", field, value)
+}
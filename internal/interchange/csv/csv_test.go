@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package csv
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unfunco/t/internal/calendar"
+	"github.com/unfunco/t/internal/interchange"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+)
+
+func testOptions(t *testing.T) interchange.Options {
+	t.Helper()
+
+	cal, err := calendar.New(0, time.UTC)
+	if err != nil {
+		t.Fatalf("calendar.New() error = %v", err)
+	}
+
+	return interchange.Options{
+		Calendar: cal,
+		Now:      time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	opts := testOptions(t)
+	created := time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)
+	completed := time.Date(2025, time.January, 2, 8, 0, 0, 0, time.UTC)
+	due := time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)
+
+	lists := []interchange.List{
+		{
+			Definition: list.Today(),
+			Todos: []model.Todo{
+				{
+					ID:          "a",
+					Title:       "Call Mum, then Dad",
+					Description: "About the \"weekend\"\nand the garden",
+					CreatedAt:   created,
+					DueDate:     &due,
+					Project:     "family",
+					Tags:        []string{"phone", "evening"},
+				},
+			},
+		},
+		{
+			Definition: list.Todos(),
+			Todos: []model.Todo{
+				{
+					ID:          "b",
+					Title:       "Pay rent",
+					Completed:   true,
+					CreatedAt:   created,
+					CompletedAt: &completed,
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if _, err := Export(&buf, lists, opts); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	header, _, _ := strings.Cut(buf.String(), "\n")
+	if header != strings.Join(Fields, ",") {
+		t.Fatalf("unexpected header %q", header)
+	}
+
+	items, report, err := Import(&buf, opts)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if len(report.Dropped) != 0 || len(report.Failed) != 0 {
+		t.Fatalf("expected a clean import, got %+v", report)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+
+	for i, item := range items {
+		want := lists[i].Todos[0]
+		if item.List != lists[i].Definition.ID {
+			t.Fatalf("expected todo in %s, got %s", lists[i].Definition.ID, item.List)
+		}
+		if !reflect.DeepEqual(item.Todo, want) {
+			t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", item.Todo, want)
+		}
+	}
+}
+
+func TestImportWithColumnMapping(t *testing.T) {
+	opts := testOptions(t)
+	opts.Columns = map[string]string{
+		FieldTitle:       "Task",
+		FieldDescription: "Notes",
+		FieldDueDate:     "Deadline",
+		FieldCompleted:   "Done",
+	}
+
+	input := strings.Join([]string{
+		"Task,Notes,Deadline,Done,Owner",
+		"Write report,Quarterly,2025-01-03,no,Sam",
+		"Ship release,,2025-01-01,yes,Alex",
+		"Book venue,,next week,no,Sam",
+		"Order cake,,,maybe,Alex",
+		"",
+	}, "\n")
+
+	items, report, err := Import(strings.NewReader(input), opts)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+
+	if items[0].List != list.TomorrowID || items[0].Todo.Description != "Quarterly" || items[0].Line != 2 {
+		t.Fatalf("unexpected first item: %+v", items[0])
+	}
+
+	if items[1].List != list.TodayID || !items[1].Todo.Completed {
+		t.Fatalf("unexpected second item: %+v", items[1])
+	}
+
+	wantDropped := []string{`column "Owner"`}
+	if !reflect.DeepEqual(report.Dropped, wantDropped) {
+		t.Fatalf("unexpected dropped data, want %q got %q", wantDropped, report.Dropped)
+	}
+
+	wantFailed := []string{
+		`line 4: invalid due_date value "next week"`,
+		`line 5: invalid completed value "maybe"`,
+	}
+	if !reflect.DeepEqual(report.Failed, wantFailed) {
+		t.Fatalf("unexpected failures, want %q got %q", wantFailed, report.Failed)
+	}
+}
+
+func TestImportRejectsInvalidColumns(t *testing.T) {
+	tests := map[string]map[string]string{
+		"unknown field":   {"priority": "Priority"},
+		"missing column":  {FieldTitle: "Summary"},
+		"no title column": nil,
+	}
+
+	for name, columns := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := testOptions(t)
+			opts.Columns = columns
+
+			if _, _, err := Import(strings.NewReader("Task,Priority\nWrite report,1\n"), opts); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
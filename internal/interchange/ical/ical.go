@@ -0,0 +1,418 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+// Package ical converts todos to and from iCalendar VTODO components as
+// described in RFC 5545.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/unfunco/t/internal/interchange"
+	"github.com/unfunco/t/internal/model"
+)
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+	utcLayout      = "20060102T150405Z"
+
+	// lineLimit is the number of octets after which content lines are folded.
+	lineLimit = 75
+)
+
+// ignored lists the VTODO properties that carry no information t keeps, and
+// that are not worth reporting when dropped.
+var ignored = map[string]bool{
+	"DTSTAMP":          true,
+	"SEQUENCE":         true,
+	"LAST-MODIFIED":    true,
+	"CLASS":            true,
+	"PERCENT-COMPLETE": true,
+}
+
+// property is a single content line.
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Import reads the VTODO components of an iCalendar stream. Other components,
+// and VTODO properties that todos cannot represent, are dropped and reported.
+func Import(r io.Reader, opts interchange.Options) ([]interchange.Item, interchange.Report, error) {
+	var (
+		items  []interchange.Item
+		report interchange.Report
+		todo   *model.Todo
+		start  int
+		depth  int
+	)
+
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, report, err
+	}
+
+	for _, l := range lines {
+		p, err := parseProperty(l.text)
+		if err != nil {
+			return nil, report, fmt.Errorf("line %d: %w", l.number, err)
+		}
+
+		switch {
+		case p.name == "BEGIN" && p.value == "VTODO" && todo == nil:
+			todo = &model.Todo{}
+			start = l.number
+		case p.name == "BEGIN" && todo != nil:
+			depth++
+			if depth == 1 {
+				report.Drop("line %d: %s component", l.number, p.value)
+			}
+		case p.name == "END" && todo != nil && depth > 0:
+			depth--
+		case p.name == "END" && p.value == "VTODO" && todo != nil:
+			finish(todo, opts)
+			items = append(items, interchange.Item{
+				List: interchange.ListForDueDate(opts.Calendar, todo.DueDate, opts.Now),
+				Todo: *todo,
+				Line: start,
+			})
+			todo = nil
+		case p.name == "BEGIN" && p.value != "VCALENDAR":
+			report.Drop("line %d: %s component", l.number, p.value)
+		case todo != nil && depth == 0:
+			apply(todo, p, l.number, opts, &report)
+		}
+	}
+
+	if todo != nil {
+		return nil, report, fmt.Errorf("line %d: VTODO is not closed", start)
+	}
+
+	return items, report, nil
+}
+
+// finish fills in the fields that the component did not set.
+func finish(todo *model.Todo, opts interchange.Options) {
+	if todo.CreatedAt.IsZero() {
+		todo.CreatedAt = opts.Now
+	}
+
+	if todo.ID == "" {
+		todo.ID = model.NewID(todo.CreatedAt)
+	}
+}
+
+func apply(todo *model.Todo, p property, line int, opts interchange.Options, report *interchange.Report) {
+	switch p.name {
+	case "UID":
+		todo.ID = p.value
+	case "SUMMARY":
+		todo.Title = unescape(p.value)
+	case "DESCRIPTION":
+		todo.Description = unescape(p.value)
+	case "CATEGORIES":
+		for _, category := range splitList(p.value) {
+			if category != "" {
+				todo.Tags = append(todo.Tags, category)
+			}
+		}
+	case "CREATED":
+		created, err := parseTime(p, opts)
+		if err != nil {
+			report.Drop("line %d: %v", line, err)
+			return
+		}
+		todo.CreatedAt = created
+	case "DUE":
+		due, err := parseTime(p, opts)
+		if err != nil {
+			report.Drop("line %d: %v", line, err)
+			return
+		}
+		date := opts.Calendar.Date(due.In(opts.Calendar.Location()))
+		todo.DueDate = &date
+	case "COMPLETED":
+		completed, err := parseTime(p, opts)
+		if err != nil {
+			report.Drop("line %d: %v", line, err)
+			return
+		}
+		todo.Completed = true
+		todo.CompletedAt = &completed
+	case "STATUS":
+		switch p.value {
+		case "COMPLETED":
+			todo.Completed = true
+		case "CANCELLED":
+			todo.Completed = true
+			report.Drop("line %d: status CANCELLED imported as completed", line)
+		}
+	default:
+		if !ignored[p.name] && !strings.HasPrefix(p.name, "X-") {
+			report.Drop("line %d: %s", line, p.name)
+		}
+	}
+}
+
+// parseTime parses a DATE or DATE-TIME value. Floating times and dates are
+// interpreted in the calendar's time zone.
+func parseTime(p property, opts interchange.Options) (time.Time, error) {
+	loc := opts.Calendar.Location()
+	if tzid, ok := p.params["TZID"]; ok {
+		l, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unknown time zone %q in %s", tzid, p.name)
+		}
+		loc = l
+	}
+
+	var (
+		t   time.Time
+		err error
+	)
+	switch {
+	case p.params["VALUE"] == "DATE" || len(p.value) == len(dateLayout):
+		t, err = time.ParseInLocation(dateLayout, p.value, loc)
+	case strings.HasSuffix(p.value, "Z"):
+		t, err = time.Parse(utcLayout, p.value)
+	default:
+		t, err = time.ParseInLocation(dateTimeLayout, p.value, loc)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s value %q", p.name, p.value)
+	}
+
+	return t, nil
+}
+
+// Export writes every todo in the lists as a VTODO component of a single
+// calendar. Projects have no iCalendar equivalent and are dropped and
+// reported.
+func Export(w io.Writer, lists []interchange.List, opts interchange.Options) (interchange.Report, error) {
+	var report interchange.Report
+	bw := bufio.NewWriter(w)
+	stamp := opts.Now.UTC().Format(utcLayout)
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//unfunco//t//EN",
+	}
+
+	for _, l := range lists {
+		for _, todo := range l.Todos {
+			if todo.Project != "" {
+				report.Drop("%s: project of %q", l.Definition.Name, todo.Title)
+			}
+
+			lines = append(lines, "BEGIN:VTODO", "UID:"+todo.ID, "DTSTAMP:"+stamp)
+
+			if !todo.CreatedAt.IsZero() {
+				lines = append(lines, "CREATED:"+todo.CreatedAt.UTC().Format(utcLayout))
+			}
+
+			lines = append(lines, "SUMMARY:"+escape(todo.Title))
+
+			if todo.Description != "" {
+				lines = append(lines, "DESCRIPTION:"+escape(todo.Description))
+			}
+
+			if len(todo.Tags) > 0 {
+				escaped := make([]string, len(todo.Tags))
+				for i, tag := range todo.Tags {
+					escaped[i] = escape(tag)
+				}
+				lines = append(lines, "CATEGORIES:"+strings.Join(escaped, ","))
+			}
+
+			if todo.DueDate != nil {
+				lines = append(lines, "DUE;VALUE=DATE:"+opts.Calendar.Date(*todo.DueDate).Format(dateLayout))
+			}
+
+			if todo.Completed {
+				lines = append(lines, "STATUS:COMPLETED")
+				if todo.CompletedAt != nil {
+					lines = append(lines, "COMPLETED:"+todo.CompletedAt.UTC().Format(utcLayout))
+				}
+			} else {
+				lines = append(lines, "STATUS:NEEDS-ACTION")
+			}
+
+			lines = append(lines, "END:VTODO")
+		}
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := bw.WriteString(fold(line)); err != nil {
+			return report, fmt.Errorf("write iCalendar: %w", err)
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return report, fmt.Errorf("write iCalendar: %w", err)
+	}
+
+	return report, nil
+}
+
+// contentLine is an unfolded content line along with the line it started on.
+type contentLine struct {
+	number int
+	text   string
+}
+
+// unfold joins folded content lines, which continue on lines that start with
+// a space or a tab.
+func unfold(r io.Reader) ([]contentLine, error) {
+	var lines []contentLine
+
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+
+		if (strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1].text += text[1:]
+			continue
+		}
+
+		if text == "" {
+			continue
+		}
+
+		lines = append(lines, contentLine{number: number, text: text})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read iCalendar: %w", err)
+	}
+
+	return lines, nil
+}
+
+// fold splits a content line into lines of at most lineLimit octets without
+// breaking UTF-8 sequences, and terminates it with CRLF.
+func fold(line string) string {
+	var b strings.Builder
+
+	limit := lineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit.
+		limit = lineLimit - 1
+	}
+
+	b.WriteString(line)
+	b.WriteString("\r\n")
+
+	return b.String()
+}
+
+// parseProperty splits a content line into its name, parameters and value.
+func parseProperty(line string) (property, error) {
+	p := property{params: make(map[string]string)}
+
+	end := valueStart(line)
+	if end < 0 {
+		return p, fmt.Errorf("invalid content line %q", line)
+	}
+
+	p.value = line[end+1:]
+
+	parts := splitParams(line[:end])
+	p.name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		p.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+
+	return p, nil
+}
+
+// valueStart returns the index of the colon that separates the name and
+// parameters from the value, skipping colons inside quoted parameter values.
+func valueStart(line string) int {
+	quoted := false
+	for i, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ':' && !quoted:
+			return i
+		}
+	}
+
+	return -1
+}
+
+func splitParams(s string) []string {
+	var (
+		parts  []string
+		quoted bool
+		start  int
+	)
+
+	for i, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ';' && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// splitList splits a comma-separated TEXT list, honouring escaped commas.
+func splitList(value string) []string {
+	var (
+		parts []string
+		b     strings.Builder
+	)
+
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value):
+			b.WriteByte(value[i])
+			b.WriteByte(value[i+1])
+			i++
+		case value[i] == ',':
+			parts = append(parts, unescape(b.String()))
+			b.Reset()
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+
+	return append(parts, unescape(b.String()))
+}
+
+var (
+	escaper   = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	unescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func unescape(s string) string {
+	return unescaper.Replace(s)
+}
@@ -0,0 +1,187 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package ical
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unfunco/t/internal/calendar"
+	"github.com/unfunco/t/internal/interchange"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+)
+
+func testOptions(t *testing.T) interchange.Options {
+	t.Helper()
+
+	cal, err := calendar.New(0, time.UTC)
+	if err != nil {
+		t.Fatalf("calendar.New() error = %v", err)
+	}
+
+	return interchange.Options{
+		Calendar: cal,
+		Now:      time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestImport(t *testing.T) {
+	input := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VTODO",
+		"UID:call",
+		"DTSTAMP:20250101T120000Z",
+		"CREATED:20250101T120000Z",
+		"SUMMARY:Call Mum\\, then Dad",
+		"DESCRIPTION:Ask about\\nthe weekend",
+		"CATEGORIES:phone,family\\,close",
+		"DUE;TZID=America/New_York:20250102T230000",
+		"PRIORITY:1",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"END:VALARM",
+		"END:VTODO",
+		"BEGIN:VEVENT",
+		"SUMMARY:Lunch",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"SUMMARY:Plan a very long trip that needs a summary long enough to be fo",
+		" lded",
+		"DUE;VALUE=DATE:20250104",
+		"STATUS:COMPLETED",
+		"COMPLETED:20250102T080000Z",
+		"END:VTODO",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	items, report, err := Import(strings.NewReader(input), testOptions(t))
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+
+	call := items[0]
+	if call.Todo.ID != "call" || call.Todo.Title != "Call Mum, then Dad" || call.Todo.Description != "Ask about\nthe weekend" {
+		t.Fatalf("unexpected first item: %+v", call.Todo)
+	}
+	if !reflect.DeepEqual(call.Todo.Tags, []string{"phone", "family,close"}) {
+		t.Fatalf("unexpected categories %q", call.Todo.Tags)
+	}
+	// 23:00 in New York on 2 January is 3 January in UTC.
+	if call.List != list.TomorrowID || !call.Todo.DueDate.Equal(time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the todo to be due tomorrow, got %s %v", call.List, call.Todo.DueDate)
+	}
+
+	trip := items[1]
+	if trip.Todo.Title != "Plan a very long trip that needs a summary long enough to be folded" {
+		t.Fatalf("expected folded summary to be joined, got %q", trip.Todo.Title)
+	}
+	if trip.List != list.TodosID || !trip.Todo.Completed || trip.Todo.CompletedAt == nil {
+		t.Fatalf("unexpected second item: %+v", trip)
+	}
+	if trip.Todo.ID == "" || !trip.Todo.CreatedAt.Equal(testOptions(t).Now) {
+		t.Fatalf("expected missing UID and creation time to be filled in, got %+v", trip.Todo)
+	}
+
+	want := []string{
+		"line 11: PRIORITY",
+		"line 12: VALARM component",
+		"line 16: VEVENT component",
+	}
+	if !reflect.DeepEqual(report.Dropped, want) {
+		t.Fatalf("unexpected report, want %q got %q", want, report.Dropped)
+	}
+}
+
+func TestImportRejectsUnclosedComponent(t *testing.T) {
+	_, _, err := Import(strings.NewReader("BEGIN:VCALENDAR\nBEGIN:VTODO\nSUMMARY:Oops\n"), testOptions(t))
+	if err == nil {
+		t.Fatal("expected an error for an unclosed VTODO")
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	opts := testOptions(t)
+	created := time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)
+	completed := time.Date(2025, time.January, 2, 8, 0, 0, 0, time.UTC)
+	due := time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)
+
+	lists := []interchange.List{
+		{
+			Definition: list.Today(),
+			Todos: []model.Todo{
+				{
+					ID:          "a",
+					Title:       "Call Mum; ask about the weekend, and the garden",
+					Description: "Über-long notes that go on and on so the line has to be folded somewhere\nover two lines",
+					CreatedAt:   created,
+					DueDate:     &due,
+					Tags:        []string{"phone", "a,b"},
+					Project:     "family",
+				},
+			},
+		},
+		{
+			Definition: list.Todos(),
+			Todos: []model.Todo{
+				{
+					ID:          "b",
+					Title:       "Pay rent",
+					Completed:   true,
+					CreatedAt:   created,
+					CompletedAt: &completed,
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	report, err := Export(&buf, lists, opts)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > lineLimit {
+			t.Fatalf("line longer than %d octets: %q", lineLimit, line)
+		}
+	}
+
+	if !strings.Contains(buf.String(), "DUE;VALUE=DATE:20250102\r\n") || !strings.Contains(buf.String(), "STATUS:COMPLETED\r\n") {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+
+	if len(report.Dropped) != 1 || !strings.Contains(report.Dropped[0], "project") {
+		t.Fatalf("expected the project to be reported, got %q", report.Dropped)
+	}
+
+	items, report, err := Import(&buf, opts)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if len(report.Dropped) != 0 {
+		t.Fatalf("expected nothing to be dropped on import, got %q", report.Dropped)
+	}
+
+	for i, item := range items {
+		want := lists[i].Todos[0]
+		want.Project = ""
+		if item.List != lists[i].Definition.ID {
+			t.Fatalf("expected todo in %s, got %s", lists[i].Definition.ID, item.List)
+		}
+		if !reflect.DeepEqual(item.Todo, want) {
+			t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", item.Todo, want)
+		}
+	}
+}
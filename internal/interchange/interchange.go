@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+// Package interchange holds the types shared by the importers and exporters
+// that convert todos to and from other applications' formats.
+package interchange
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/unfunco/t/internal/calendar"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+)
+
+// Options carries the context needed to interpret dates during a conversion.
+type Options struct {
+	// Calendar decides which day dates belong to.
+	Calendar calendar.Calendar
+	// Now is the time used for todos without a creation date, and to decide
+	// which list imported todos belong to.
+	Now time.Time
+	// Columns maps field names onto the names of the columns holding them,
+	// for formats with named columns. Fields that are not mapped are read
+	// from the column with the same name.
+	Columns map[string]string
+}
+
+// List is a todo list to be exported.
+type List struct {
+	Definition list.Definition
+	Todos      []model.Todo
+}
+
+// Item is an imported todo along with the list it should be added to.
+type Item struct {
+	// List is the list the todo belongs to.
+	List list.ID
+	// Todo is the imported todo.
+	Todo model.Todo
+	// Line is the position of the todo in the source, used in messages.
+	Line int
+}
+
+// Report collects the data that a conversion could not represent.
+type Report struct {
+	// Dropped describes data that was left out of otherwise converted todos.
+	Dropped []string
+	// Failed describes records that could not be converted at all.
+	Failed []string
+}
+
+// Drop records a message about data that was not converted.
+func (r *Report) Drop(format string, args ...any) {
+	r.Dropped = append(r.Dropped, fmt.Sprintf(format, args...))
+}
+
+// Fail records a message about a record that could not be converted.
+func (r *Report) Fail(format string, args ...any) {
+	r.Failed = append(r.Failed, fmt.Sprintf(format, args...))
+}
+
+// ListForDueDate returns the list an imported todo belongs to, mirroring the
+// due dates given to todos added to the Today and Tomorrow lists. Todos due
+// today or earlier belong to Today, todos due tomorrow belong to Tomorrow, and
+// everything else belongs to Todos.
+func ListForDueDate(cal calendar.Calendar, due *time.Time, now time.Time) list.ID {
+	if due == nil {
+		return list.TodosID
+	}
+
+	date := cal.Date(*due)
+
+	today := list.DefaultDueDate(cal, list.TodayID, now)
+	if !date.After(*today) {
+		return list.TodayID
+	}
+
+	tomorrow := list.DefaultDueDate(cal, list.TomorrowID, now)
+	if date.Equal(*tomorrow) {
+		return list.TomorrowID
+	}
+
+	return list.TodosID
+}
@@ -0,0 +1,267 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+// Package markdown converts todos to and from GitHub-style Markdown task
+// lists.
+package markdown
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/unfunco/t/internal/interchange"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+)
+
+var (
+	taskPattern    = regexp.MustCompile(`^([ \t]*)[-*+][ \t]+\[([ xX])\][ \t]+(.*)$`)
+	headingPattern = regexp.MustCompile(`^#{1,6}[ \t]+(.*?)[ \t#]*$`)
+	fencePattern   = regexp.MustCompile("^[ \t]*(```|~~~)")
+)
+
+// Import reads the task-list items of a Markdown document. Each top-level item
+// becomes a todo, and anything nested beneath it, including nested items,
+// becomes its description. Items under a heading named after one of the
+// default lists are added to that list, and are due when todos added to it
+// are. All other items are added to the Todos list.
+func Import(r io.Reader, opts interchange.Options) ([]interchange.Item, interchange.Report, error) {
+	var (
+		items   []interchange.Item
+		report  interchange.Report
+		current *pending
+		section list.ID
+		fenced  bool
+	)
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		items = append(items, current.item(section, opts))
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+		indent := indentation(text)
+
+		if current != nil && (text == "" || indent > current.indent) {
+			if fencePattern.MatchString(text) {
+				fenced = !fenced
+			}
+			current.body = append(current.body, text)
+			continue
+		}
+
+		if fencePattern.MatchString(text) {
+			fenced = !fenced
+			flush()
+			continue
+		}
+		if fenced {
+			continue
+		}
+
+		if match := headingPattern.FindStringSubmatch(text); match != nil {
+			flush()
+			section = sectionFor(match[1])
+			continue
+		}
+
+		if match := taskPattern.FindStringSubmatch(text); match != nil {
+			flush()
+			current = &pending{
+				indent:    indent,
+				line:      line,
+				title:     match[3],
+				completed: match[2] != " ",
+			}
+			continue
+		}
+
+		if text != "" {
+			flush()
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, report, fmt.Errorf("read Markdown: %w", err)
+	}
+
+	flush()
+
+	return items, report, nil
+}
+
+// pending is a task-list item whose nested content is still being read.
+type pending struct {
+	indent    int
+	line      int
+	title     string
+	completed bool
+	body      []string
+}
+
+func (p *pending) item(section list.ID, opts interchange.Options) interchange.Item {
+	todo := model.Todo{
+		ID:          model.NewID(opts.Now),
+		Title:       p.title,
+		Description: dedent(p.body),
+		Completed:   p.completed,
+		CreatedAt:   opts.Now,
+	}
+
+	if section == "" {
+		section = list.TodosID
+	}
+	todo.DueDate = list.DefaultDueDate(opts.Calendar, section, opts.Now)
+
+	return interchange.Item{
+		List: section,
+		Todo: todo,
+		Line: p.line,
+	}
+}
+
+// sectionFor returns the list named by a heading, or an empty ID when the
+// heading does not name one of the default lists.
+func sectionFor(heading string) list.ID {
+	for _, def := range list.Default() {
+		if strings.EqualFold(strings.TrimSpace(heading), def.Name) {
+			return def.ID
+		}
+	}
+
+	return ""
+}
+
+// indentation returns the width of the leading whitespace of a line, counting
+// tabs as four columns.
+func indentation(text string) int {
+	width := 0
+	for _, r := range text {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+
+	return width
+}
+
+// dedent removes the indentation shared by the lines and any surrounding
+// blank lines, and joins the result.
+func dedent(lines []string) string {
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+
+	shared := -1
+	for _, l := range lines {
+		if l == "" {
+			continue
+		}
+		if indent := indentation(l); shared < 0 || indent < shared {
+			shared = indent
+		}
+	}
+
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		out[i] = trimIndent(l, shared)
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// trimIndent removes up to width columns of leading whitespace from a line.
+func trimIndent(text string, width int) string {
+	removed := 0
+	for i, r := range text {
+		if removed >= width || (r != ' ' && r != '\t') {
+			return text[i:]
+		}
+		if r == '\t' {
+			removed += 4
+		} else {
+			removed++
+		}
+	}
+
+	return ""
+}
+
+// Export writes each list as a heading followed by a task list. Descriptions
+// are nested beneath their todo. Projects, tags and due dates other than the
+// one implied by the list are dropped and reported.
+func Export(w io.Writer, lists []interchange.List, opts interchange.Options) (interchange.Report, error) {
+	var report interchange.Report
+	bw := bufio.NewWriter(w)
+
+	for i, l := range lists {
+		if i > 0 {
+			_, _ = bw.WriteString("\n")
+		}
+		_, _ = fmt.Fprintf(bw, "## %s\n", l.Definition.Name)
+
+		if len(l.Todos) > 0 {
+			_, _ = bw.WriteString("\n")
+		}
+
+		implied := list.DefaultDueDate(opts.Calendar, l.Definition.ID, opts.Now)
+
+		for _, todo := range l.Todos {
+			reportDropped(&report, l.Definition, todo, implied, opts)
+
+			mark := " "
+			if todo.Completed {
+				mark = "x"
+			}
+			_, _ = fmt.Fprintf(bw, "- [%s] %s\n", mark, todo.Title)
+
+			if todo.Description == "" {
+				continue
+			}
+			for _, line := range strings.Split(todo.Description, "\n") {
+				if strings.TrimSpace(line) == "" {
+					_, _ = bw.WriteString("\n")
+					continue
+				}
+				_, _ = fmt.Fprintf(bw, "  %s\n", line)
+			}
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return report, fmt.Errorf("write Markdown: %w", err)
+	}
+
+	return report, nil
+}
+
+func reportDropped(report *interchange.Report, def list.Definition, todo model.Todo, implied *time.Time, opts interchange.Options) {
+	if todo.Project != "" {
+		report.Drop("%s: project of %q", def.Name, todo.Title)
+	}
+
+	if len(todo.Tags) > 0 {
+		report.Drop("%s: tags of %q", def.Name, todo.Title)
+	}
+
+	if todo.DueDate != nil && (implied == nil || !opts.Calendar.Date(*todo.DueDate).Equal(*implied)) {
+		report.Drop("%s: due date of %q", def.Name, todo.Title)
+	}
+}
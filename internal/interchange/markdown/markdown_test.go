@@ -0,0 +1,147 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package markdown
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unfunco/t/internal/calendar"
+	"github.com/unfunco/t/internal/interchange"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+)
+
+func testOptions(t *testing.T) interchange.Options {
+	t.Helper()
+
+	cal, err := calendar.New(0, time.UTC)
+	if err != nil {
+		t.Fatalf("calendar.New() error = %v", err)
+	}
+
+	return interchange.Options{
+		Calendar: cal,
+		Now:      time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestImport(t *testing.T) {
+	input := strings.Join([]string{
+		"# Standup notes",
+		"",
+		"- [ ] Fix the login bug",
+		"  The session expires too early.",
+		"",
+		"  - [ ] Check the cookie lifetime",
+		"  - [x] Reproduce locally",
+		"- [X] Review the PR",
+		"- Not a task",
+		"",
+		"```",
+		"- [ ] Not a task either",
+		"```",
+		"",
+		"## Tomorrow",
+		"",
+		"* [ ] Book the room",
+		"",
+	}, "\n")
+
+	items, report, err := Import(strings.NewReader(input), testOptions(t))
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if len(report.Dropped) != 0 {
+		t.Fatalf("expected nothing to be dropped, got %q", report.Dropped)
+	}
+
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items, got %d: %+v", len(items), items)
+	}
+
+	bug := items[0]
+	wantDescription := "The session expires too early.\n\n- [ ] Check the cookie lifetime\n- [x] Reproduce locally"
+	if bug.Todo.Title != "Fix the login bug" || bug.Todo.Description != wantDescription || bug.Line != 3 {
+		t.Fatalf("unexpected first item: %+v", bug)
+	}
+	if bug.List != list.TodosID || bug.Todo.DueDate != nil {
+		t.Fatalf("expected the first item in the Todos list, got %+v", bug)
+	}
+
+	if review := items[1].Todo; review.Title != "Review the PR" || !review.Completed {
+		t.Fatalf("unexpected second item: %+v", review)
+	}
+
+	room := items[2]
+	if room.List != list.TomorrowID || room.Todo.DueDate == nil || !room.Todo.DueDate.Equal(time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the last item due in the Tomorrow list, got %+v", room)
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	opts := testOptions(t)
+	today := time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)
+	later := time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)
+
+	lists := []interchange.List{
+		{
+			Definition: list.Today(),
+			Todos: []model.Todo{
+				{Title: "Call Mum", DueDate: &today, Description: "About the weekend\n\n- [ ] Ask about the garden"},
+			},
+		},
+		{Definition: list.Tomorrow()},
+		{
+			Definition: list.Todos(),
+			Todos: []model.Todo{
+				{Title: "Pay rent", Completed: true, DueDate: &later, Tags: []string{"home"}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	report, err := Export(&buf, lists, opts)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	want := "## Today\n\n" +
+		"- [ ] Call Mum\n" +
+		"  About the weekend\n" +
+		"\n" +
+		"  - [ ] Ask about the garden\n" +
+		"\n## Tomorrow\n" +
+		"\n## Todos\n\n" +
+		"- [x] Pay rent\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	wantDropped := []string{`Todos: tags of "Pay rent"`, `Todos: due date of "Pay rent"`}
+	if !reflect.DeepEqual(report.Dropped, wantDropped) {
+		t.Fatalf("unexpected report, want %q got %q", wantDropped, report.Dropped)
+	}
+
+	items, _, err := Import(&buf, opts)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+
+	if items[0].List != list.TodayID || items[0].Todo.Description != lists[0].Todos[0].Description {
+		t.Fatalf("unexpected first item: %+v", items[0])
+	}
+
+	if items[1].List != list.TodosID || !items[1].Todo.Completed {
+		t.Fatalf("unexpected second item: %+v", items[1])
+	}
+}
@@ -0,0 +1,221 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+// Package taskwarrior imports tasks written by Taskwarrior's task export
+// command.
+package taskwarrior
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/unfunco/t/internal/interchange"
+	"github.com/unfunco/t/internal/model"
+)
+
+// timeLayout is the format of Taskwarrior's timestamps, which are always UTC.
+const timeLayout = "20060102T150405Z"
+
+// Taskwarrior task statuses.
+const (
+	statusPending   = "pending"
+	statusWaiting   = "waiting"
+	statusCompleted = "completed"
+	statusDeleted   = "deleted"
+	statusRecurring = "recurring"
+)
+
+// ignored lists the attributes that carry no information t keeps, and that
+// are not worth reporting when dropped.
+var ignored = []string{"id", "urgency", "modified", "mask", "imask", "parent"}
+
+// task is the subset of a Taskwarrior task that maps onto a todo.
+type task struct {
+	UUID        string       `json:"uuid"`
+	Description string       `json:"description"`
+	Status      string       `json:"status"`
+	Entry       string       `json:"entry"`
+	End         string       `json:"end"`
+	Due         string       `json:"due"`
+	Project     string       `json:"project"`
+	Tags        []string     `json:"tags"`
+	Annotations []annotation `json:"annotations"`
+}
+
+type annotation struct {
+	Description string `json:"description"`
+}
+
+// mapped lists the attributes read into a task.
+var mapped = []string{"uuid", "description", "status", "entry", "end", "due", "project", "tags", "annotations"}
+
+// Import reads the output of task export, which is either a JSON array of
+// tasks or, from older versions, one JSON task per line. The text of each
+// annotation becomes a line of the description. Deleted tasks, recurring task
+// templates and attributes that todos cannot represent, such as priorities,
+// are dropped and reported.
+func Import(r io.Reader, opts interchange.Options) ([]interchange.Item, interchange.Report, error) {
+	var report interchange.Report
+
+	raw, err := decode(r)
+	if err != nil {
+		return nil, report, err
+	}
+
+	var items []interchange.Item
+	for i, data := range raw {
+		var (
+			t      task
+			fields map[string]json.RawMessage
+		)
+		if err := json.Unmarshal(data, &t); err != nil {
+			report.Fail("task %d: %v", i+1, err)
+			continue
+		}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			report.Fail("task %d: %v", i+1, err)
+			continue
+		}
+
+		name := t.name(i)
+
+		switch t.Status {
+		case statusDeleted:
+			report.Drop("%s: deleted task", name)
+			continue
+		case statusRecurring:
+			report.Drop("%s: recurring task template", name)
+			continue
+		}
+
+		for _, key := range slices.Sorted(maps.Keys(fields)) {
+			if !slices.Contains(mapped, key) && !slices.Contains(ignored, key) {
+				report.Drop("%s: %s", name, key)
+			}
+		}
+
+		todo, err := t.todo(opts)
+		if err != nil {
+			report.Fail("%s: %v", name, err)
+			continue
+		}
+
+		items = append(items, interchange.Item{
+			List: interchange.ListForDueDate(opts.Calendar, todo.DueDate, opts.Now),
+			Todo: todo,
+		})
+	}
+
+	return items, report, nil
+}
+
+// decode splits the export into the JSON of each task.
+func decode(r io.Reader) ([]json.RawMessage, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read Taskwarrior export: %w", err)
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var tasks []json.RawMessage
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &tasks); err != nil {
+			return nil, fmt.Errorf("decode Taskwarrior export: %w", err)
+		}
+		return tasks, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var t json.RawMessage
+		if err := dec.Decode(&t); errors.Is(err, io.EOF) {
+			return tasks, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("decode Taskwarrior export: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+}
+
+// name describes the task in messages.
+func (t task) name(i int) string {
+	if t.Description != "" {
+		return fmt.Sprintf("task %q", t.Description)
+	}
+
+	return fmt.Sprintf("task %d", i+1)
+}
+
+func (t task) todo(opts interchange.Options) (model.Todo, error) {
+	todo := model.Todo{
+		ID:        t.UUID,
+		Title:     t.Description,
+		Completed: t.Status == statusCompleted,
+		CreatedAt: opts.Now,
+		Project:   t.Project,
+		Tags:      slices.Clone(t.Tags),
+	}
+
+	switch t.Status {
+	case "", statusPending, statusWaiting, statusCompleted:
+	default:
+		return model.Todo{}, fmt.Errorf("unknown status %q", t.Status)
+	}
+
+	if t.Entry != "" {
+		entry, err := parseTime("entry", t.Entry)
+		if err != nil {
+			return model.Todo{}, err
+		}
+		todo.CreatedAt = entry
+	}
+
+	if t.End != "" && todo.Completed {
+		end, err := parseTime("end", t.End)
+		if err != nil {
+			return model.Todo{}, err
+		}
+		todo.CompletedAt = &end
+	}
+
+	if t.Due != "" {
+		due, err := parseTime("due", t.Due)
+		if err != nil {
+			return model.Todo{}, err
+		}
+		date := opts.Calendar.Date(due.In(opts.Calendar.Location()))
+		todo.DueDate = &date
+	}
+
+	notes := make([]string, 0, len(t.Annotations))
+	for _, a := range t.Annotations {
+		notes = append(notes, a.Description)
+	}
+	todo.Description = strings.Join(notes, "\n")
+
+	if todo.ID == "" {
+		todo.ID = model.NewID(todo.CreatedAt)
+	}
+
+	return todo, nil
+}
+
+func parseTime(attribute, value string) (time.Time, error) {
+	t, err := time.Parse(timeLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s value %q", attribute, value)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package taskwarrior
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unfunco/t/internal/calendar"
+	"github.com/unfunco/t/internal/interchange"
+	"github.com/unfunco/t/internal/list"
+)
+
+func testOptions(t *testing.T) interchange.Options {
+	t.Helper()
+
+	cal, err := calendar.New(0, time.UTC)
+	if err != nil {
+		t.Fatalf("calendar.New() error = %v", err)
+	}
+
+	return interchange.Options{
+		Calendar: cal,
+		Now:      time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+const export = `[
+{"id":1,"description":"Call Mum","due":"20250102T170000Z","entry":"20241201T120000Z","modified":"20241201T120000Z","project":"Family","status":"pending","tags":["phone"],"uuid":"2f6c0f7e-6b1c-4c57-9a1e-111111111111","urgency":9.1,"priority":"H","annotations":[{"entry":"20241202T120000Z","description":"Ask about the weekend"},{"entry":"20241203T120000Z","description":"And the garden"}]},
+{"id":2,"description":"Book the room","due":"20250103T090000Z","entry":"20241215T120000Z","status":"waiting","wait":"20250103T000000Z","uuid":"2f6c0f7e-6b1c-4c57-9a1e-222222222222"},
+{"id":0,"description":"Pay rent","end":"20250101T100000Z","entry":"20241220T120000Z","status":"completed","uuid":"2f6c0f7e-6b1c-4c57-9a1e-333333333333"},
+{"id":0,"description":"Old idea","end":"20241230T100000Z","entry":"20241220T120000Z","status":"deleted","uuid":"2f6c0f7e-6b1c-4c57-9a1e-444444444444"},
+{"id":3,"description":"Water plants","entry":"20241220T120000Z","recur":"weekly","due":"20250105T000000Z","status":"recurring","uuid":"2f6c0f7e-6b1c-4c57-9a1e-555555555555"},
+{"id":4,"description":"Broken","entry":"yesterday","status":"pending"}
+]`
+
+func TestImport(t *testing.T) {
+	items, report, err := Import(strings.NewReader(export), testOptions(t))
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(items))
+	}
+
+	call := items[0]
+	if call.List != list.TodayID {
+		t.Fatalf("expected the task due today in the Today list, got %s", call.List)
+	}
+	if call.Todo.ID != "2f6c0f7e-6b1c-4c57-9a1e-111111111111" || call.Todo.Title != "Call Mum" || call.Todo.Project != "Family" {
+		t.Fatalf("unexpected first item: %+v", call.Todo)
+	}
+	if call.Todo.Description != "Ask about the weekend\nAnd the garden" {
+		t.Fatalf("expected annotations in the description, got %q", call.Todo.Description)
+	}
+	if !reflect.DeepEqual(call.Todo.Tags, []string{"phone"}) {
+		t.Fatalf("unexpected tags %q", call.Todo.Tags)
+	}
+	if !call.Todo.CreatedAt.Equal(time.Date(2024, time.December, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected entry as the creation time, got %v", call.Todo.CreatedAt)
+	}
+	if !call.Todo.DueDate.Equal(time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the due date to be the start of the day, got %v", call.Todo.DueDate)
+	}
+
+	if room := items[1]; room.List != list.TomorrowID || room.Todo.Completed {
+		t.Fatalf("expected the waiting task due tomorrow in the Tomorrow list, got %+v", room)
+	}
+
+	rent := items[2]
+	if rent.List != list.TodosID || !rent.Todo.Completed || rent.Todo.CompletedAt == nil {
+		t.Fatalf("unexpected completed item: %+v", rent)
+	}
+	if !rent.Todo.CompletedAt.Equal(time.Date(2025, time.January, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected end as the completion time, got %v", rent.Todo.CompletedAt)
+	}
+
+	wantDropped := []string{
+		`task "Call Mum": priority`,
+		`task "Book the room": wait`,
+		`task "Old idea": deleted task`,
+		`task "Water plants": recurring task template`,
+	}
+	if !reflect.DeepEqual(report.Dropped, wantDropped) {
+		t.Fatalf("unexpected dropped data, want %q got %q", wantDropped, report.Dropped)
+	}
+
+	wantFailed := []string{`task "Broken": invalid entry value "yesterday"`}
+	if !reflect.DeepEqual(report.Failed, wantFailed) {
+		t.Fatalf("unexpected failures, want %q got %q", wantFailed, report.Failed)
+	}
+}
+
+func TestImportReadsOneTaskPerLine(t *testing.T) {
+	input := `{"description":"First","status":"pending","entry":"20241201T120000Z"}
+{"description":"Second","status":"pending","entry":"20241201T120000Z"}
+`
+
+	items, _, err := Import(strings.NewReader(input), testOptions(t))
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if len(items) != 2 || items[0].Todo.Title != "First" || items[1].Todo.Title != "Second" {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+
+	if items[0].Todo.ID == "" || items[0].Todo.ID == items[1].Todo.ID {
+		t.Fatalf("expected tasks without a UUID to be given unique IDs, got %q and %q", items[0].Todo.ID, items[1].Todo.ID)
+	}
+}
+
+func TestImportRejectsInvalidJSON(t *testing.T) {
+	if _, _, err := Import(strings.NewReader(`[{"description":`), testOptions(t)); err == nil {
+		t.Fatal("expected an error for invalid JSON")
+	}
+}
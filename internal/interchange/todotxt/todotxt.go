@@ -0,0 +1,282 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+// Package todotxt converts todos to and from the todo.txt format described at
+// https://github.com/todotxt/todo.txt.
+package todotxt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/unfunco/t/internal/interchange"
+	"github.com/unfunco/t/internal/model"
+)
+
+const dateLayout = time.DateOnly
+
+var priorityPattern = regexp.MustCompile(`^\([A-Z]\)$`)
+
+// Import reads todos written in todo.txt format. Priorities, additional
+// projects and unsupported key:value extensions are dropped and reported.
+// Words starting with a backslash are read as part of the title, without it,
+// as Export writes them.
+func Import(r io.Reader, opts interchange.Options) ([]interchange.Item, interchange.Report, error) {
+	var (
+		items  []interchange.Item
+		report interchange.Report
+	)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		todo := parseLine(text, line, opts, &report)
+		items = append(items, interchange.Item{
+			List: interchange.ListForDueDate(opts.Calendar, todo.DueDate, opts.Now),
+			Todo: todo,
+			Line: line,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, report, fmt.Errorf("read todo.txt: %w", err)
+	}
+
+	return items, report, nil
+}
+
+func parseLine(text string, line int, opts interchange.Options, report *interchange.Report) model.Todo {
+	fields := strings.Fields(text)
+	todo := model.Todo{}
+
+	if len(fields) > 0 && fields[0] == "x" {
+		todo.Completed = true
+		fields = fields[1:]
+	}
+
+	if len(fields) > 0 && priorityPattern.MatchString(fields[0]) {
+		report.Drop("line %d: priority %s", line, fields[0])
+		fields = fields[1:]
+	}
+
+	var dates []time.Time
+	for len(fields) > 0 && len(dates) < 2 {
+		d, err := time.ParseInLocation(dateLayout, fields[0], opts.Calendar.Location())
+		if err != nil {
+			break
+		}
+		dates = append(dates, d)
+		fields = fields[1:]
+	}
+
+	switch {
+	case todo.Completed && len(dates) == 2:
+		todo.CompletedAt = &dates[0]
+		todo.CreatedAt = dates[1]
+	case todo.Completed && len(dates) == 1:
+		todo.CompletedAt = &dates[0]
+	case len(dates) >= 1:
+		todo.CreatedAt = dates[0]
+		if len(dates) == 2 {
+			report.Drop("line %d: unexpected second date %s", line, dates[1].Format(dateLayout))
+		}
+	}
+
+	var words []string
+	for _, field := range fields {
+		switch {
+		case strings.HasPrefix(field, `\`):
+			words = append(words, field[1:])
+		case len(field) > 1 && field[0] == '+':
+			if todo.Project == "" {
+				todo.Project = field[1:]
+			} else {
+				report.Drop("line %d: additional project %s", line, field)
+			}
+		case len(field) > 1 && field[0] == '@':
+			todo.Tags = append(todo.Tags, field[1:])
+		default:
+			key, value, ok := keyValue(field)
+			if !ok {
+				words = append(words, field)
+				continue
+			}
+
+			switch key {
+			case "due":
+				due, err := time.ParseInLocation(dateLayout, value, opts.Calendar.Location())
+				if err != nil {
+					report.Drop("line %d: invalid due date %s", line, value)
+					continue
+				}
+				todo.DueDate = &due
+			case "id":
+				todo.ID = value
+			case "pri":
+				report.Drop("line %d: priority %s", line, value)
+			default:
+				report.Drop("line %d: %s", line, field)
+			}
+		}
+	}
+
+	todo.Title = strings.Join(words, " ")
+
+	if todo.CreatedAt.IsZero() {
+		todo.CreatedAt = opts.Now
+	}
+
+	if todo.ID == "" {
+		todo.ID = model.NewID(todo.CreatedAt)
+	}
+
+	return todo
+}
+
+// keyValue splits a key:value extension. URLs such as https://example.com
+// and times such as 09:30 are not treated as extensions, as keys must start
+// with a letter.
+func keyValue(field string) (string, string, bool) {
+	key, value, ok := strings.Cut(field, ":")
+	if !ok || key == "" || value == "" || strings.HasPrefix(value, "//") {
+		return "", "", false
+	}
+
+	if first := key[0]; (first < 'a' || first > 'z') && (first < 'A' || first > 'Z') {
+		return "", "", false
+	}
+
+	for _, r := range key {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '_' && r != '-' {
+			return "", "", false
+		}
+	}
+
+	return key, value, true
+}
+
+// Export writes every todo in the lists in todo.txt format. Descriptions have
+// no todo.txt equivalent and are dropped and reported. IDs are written as id:
+// extensions so that exported todos can be imported again without duplicates.
+// Words of a title that would be read back as something else, such as a
+// project, context, extension or date, are escaped with a backslash.
+func Export(w io.Writer, lists []interchange.List, opts interchange.Options) (interchange.Report, error) {
+	var report interchange.Report
+	bw := bufio.NewWriter(w)
+
+	for _, l := range lists {
+		for _, todo := range l.Todos {
+			if todo.Description != "" {
+				report.Drop("%s: description of %q", l.Definition.Name, todo.Title)
+			}
+
+			if _, err := fmt.Fprintln(bw, formatLine(todo, opts, &report)); err != nil {
+				return report, fmt.Errorf("write todo.txt: %w", err)
+			}
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return report, fmt.Errorf("write todo.txt: %w", err)
+	}
+
+	return report, nil
+}
+
+func formatLine(todo model.Todo, opts interchange.Options, report *interchange.Report) string {
+	loc := opts.Calendar.Location()
+	var parts []string
+
+	if todo.Completed {
+		parts = append(parts, "x")
+		if todo.CompletedAt != nil {
+			parts = append(parts, todo.CompletedAt.In(loc).Format(dateLayout))
+		}
+	}
+
+	if !todo.CreatedAt.IsZero() && (!todo.Completed || todo.CompletedAt != nil) {
+		parts = append(parts, todo.CreatedAt.In(loc).Format(dateLayout))
+	}
+
+	parts = append(parts, escapeTitle(todo.Title, opts))
+
+	if todo.Project != "" {
+		parts = append(parts, "+"+token(todo.Project, todo.Title, "project", report))
+	}
+
+	for _, tag := range todo.Tags {
+		parts = append(parts, "@"+token(tag, todo.Title, "tag", report))
+	}
+
+	if todo.DueDate != nil {
+		parts = append(parts, "due:"+opts.Calendar.Date(*todo.DueDate).Format(dateLayout))
+	}
+
+	if todo.ID != "" {
+		parts = append(parts, "id:"+todo.ID)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// escapeTitle prefixes the words of title that Import would not read as part
+// of a title with a backslash.
+func escapeTitle(title string, opts interchange.Options) string {
+	words := strings.Fields(title)
+	for i, word := range words {
+		if needsEscape(word, i == 0, opts) {
+			words[i] = `\` + word
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// needsEscape reports whether Import would read word as something other than
+// a word of a title. The first word follows any dates, so must not look like
+// a date, a priority or the completion marker either.
+func needsEscape(word string, first bool, opts interchange.Options) bool {
+	switch {
+	case strings.HasPrefix(word, `\`):
+		return true
+	case len(word) > 1 && (word[0] == '+' || word[0] == '@'):
+		return true
+	}
+
+	if _, _, ok := keyValue(word); ok {
+		return true
+	}
+
+	if !first {
+		return false
+	}
+
+	if word == "x" || priorityPattern.MatchString(word) {
+		return true
+	}
+
+	_, err := time.ParseInLocation(dateLayout, word, opts.Calendar.Location())
+
+	return err == nil
+}
+
+// token replaces whitespace, which todo.txt cannot represent inside projects
+// and contexts, with hyphens.
+func token(value, title, kind string, report *interchange.Report) string {
+	if !strings.ContainsAny(value, " \t") {
+		return value
+	}
+
+	replaced := strings.Join(strings.Fields(value), "-")
+	report.Drop("%s %q of %q written as %s", kind, value, title, replaced)
+
+	return replaced
+}
@@ -0,0 +1,209 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package todotxt
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unfunco/t/internal/calendar"
+	"github.com/unfunco/t/internal/interchange"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+)
+
+func testOptions(t *testing.T) interchange.Options {
+	t.Helper()
+
+	cal, err := calendar.New(0, time.UTC)
+	if err != nil {
+		t.Fatalf("calendar.New() error = %v", err)
+	}
+
+	return interchange.Options{
+		Calendar: cal,
+		Now:      time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func date(year int, month time.Month, day int) *time.Time {
+	t := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func TestImport(t *testing.T) {
+	input := strings.Join([]string{
+		"(A) 2025-01-01 Call Mum +family @phone due:2025-01-02",
+		"x 2025-01-02 2024-12-30 Pay rent +home +finance @online",
+		"2025-01-01 Plan trip @travel due:2025-01-03 rec:1y",
+		"Read https://example.com/article",
+		"",
+	}, "\n")
+
+	items, report, err := Import(strings.NewReader(input), testOptions(t))
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if len(items) != 4 {
+		t.Fatalf("expected 4 items, got %d", len(items))
+	}
+
+	call := items[0]
+	if call.List != list.TodayID || call.Todo.Title != "Call Mum" || call.Todo.Project != "family" {
+		t.Fatalf("unexpected first item: %+v", call)
+	}
+	if !reflect.DeepEqual(call.Todo.Tags, []string{"phone"}) {
+		t.Fatalf("expected phone tag, got %v", call.Todo.Tags)
+	}
+	if !call.Todo.CreatedAt.Equal(*date(2025, time.January, 1)) {
+		t.Fatalf("unexpected creation date %v", call.Todo.CreatedAt)
+	}
+
+	rent := items[1].Todo
+	if !rent.Completed || rent.CompletedAt == nil || !rent.CompletedAt.Equal(*date(2025, time.January, 2)) {
+		t.Fatalf("expected rent to be completed on 2 January, got %+v", rent)
+	}
+	if !rent.CreatedAt.Equal(*date(2024, time.December, 30)) {
+		t.Fatalf("unexpected creation date %v", rent.CreatedAt)
+	}
+
+	if items[2].List != list.TomorrowID {
+		t.Fatalf("expected todo due tomorrow in the Tomorrow list, got %s", items[2].List)
+	}
+
+	if items[3].List != list.TodosID || items[3].Todo.Title != "Read https://example.com/article" {
+		t.Fatalf("expected URL to stay in the title, got %+v", items[3])
+	}
+
+	want := []string{
+		"line 1: priority (A)",
+		"line 2: additional project +finance",
+		"line 3: rec:1y",
+	}
+	if !reflect.DeepEqual(report.Dropped, want) {
+		t.Fatalf("unexpected report, want %q got %q", want, report.Dropped)
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	opts := testOptions(t)
+	completed := time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)
+
+	lists := []interchange.List{
+		{
+			Definition: list.Today(),
+			Todos: []model.Todo{
+				{
+					ID:        "a",
+					Title:     "Call Mum",
+					CreatedAt: *date(2025, time.January, 1),
+					DueDate:   date(2025, time.January, 2),
+					Project:   "family",
+					Tags:      []string{"phone"},
+				},
+			},
+		},
+		{
+			Definition: list.Todos(),
+			Todos: []model.Todo{
+				{
+					ID:          "b",
+					Title:       "Pay rent",
+					Description: "Standing order failed",
+					Completed:   true,
+					CreatedAt:   *date(2024, time.December, 30),
+					CompletedAt: &completed,
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	report, err := Export(&buf, lists, opts)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	wantOutput := "2025-01-01 Call Mum +family @phone due:2025-01-02 id:a\n" +
+		"x 2025-01-02 2024-12-30 Pay rent id:b\n"
+	if buf.String() != wantOutput {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", buf.String(), wantOutput)
+	}
+
+	if len(report.Dropped) != 1 || !strings.Contains(report.Dropped[0], "description") {
+		t.Fatalf("expected the description to be reported, got %q", report.Dropped)
+	}
+
+	items, report, err := Import(&buf, opts)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if len(report.Dropped) != 0 {
+		t.Fatalf("expected nothing to be dropped on import, got %q", report.Dropped)
+	}
+
+	for i, item := range items {
+		want := lists[i].Todos[0]
+		want.Description = ""
+		if !reflect.DeepEqual(item.Todo, want) {
+			t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", item.Todo, want)
+		}
+	}
+}
+
+func TestExportRoundTripKeepsTitles(t *testing.T) {
+	opts := testOptions(t)
+
+	titles := []string{
+		"Standup at 09:30 with +1 from @home",
+		"2025-01-01 retro",
+		"x marks the spot",
+		"(B) plan",
+		`Escape \n and note:this`,
+	}
+
+	var todos []model.Todo
+	for i, title := range titles {
+		todos = append(todos, model.Todo{
+			ID:        string(rune('a' + i)),
+			Title:     title,
+			CreatedAt: *date(2025, time.January, 1),
+		})
+	}
+
+	var buf bytes.Buffer
+	if _, err := Export(&buf, []interchange.List{{Definition: list.Todos(), Todos: todos}}, opts); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	items, report, err := Import(&buf, opts)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(report.Dropped) != 0 {
+		t.Fatalf("expected nothing to be dropped on import, got %q", report.Dropped)
+	}
+
+	for i, item := range items {
+		if !reflect.DeepEqual(item.Todo, todos[i]) {
+			t.Errorf("round trip mismatch:\n got %+v\nwant %+v", item.Todo, todos[i])
+		}
+	}
+}
+
+func TestImportKeepsTimesInTitles(t *testing.T) {
+	items, report, err := Import(strings.NewReader("Standup at 09:30\n"), testOptions(t))
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if len(items) != 1 || items[0].Todo.Title != "Standup at 09:30" || len(report.Dropped) != 0 {
+		t.Fatalf("expected the time to stay in the title, got %+v, %q", items, report.Dropped)
+	}
+}
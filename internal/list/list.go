@@ -3,7 +3,11 @@
 
 package list
 
-import "time"
+import (
+	"time"
+
+	"github.com/unfunco/t/internal/calendar"
+)
 
 // ID identifies a todo list.
 type ID string
@@ -24,9 +28,6 @@ type Definition struct {
 	Filename string
 }
 
-// day is the number of hours in a full calendar day.
-const day = 24 * time.Hour
-
 var definitions = map[ID]Definition{
 	TodayID: {
 		ID:       TodayID,
@@ -58,6 +59,12 @@ func Default() []Definition {
 	return out
 }
 
+// Lookup returns the list definition with the given ID.
+func Lookup(id ID) (Definition, bool) {
+	def, ok := definitions[id]
+	return def, ok
+}
+
 // Today returns the default Today list definition.
 func Today() Definition {
 	return definitions[TodayID]
@@ -74,20 +81,17 @@ func Todos() Definition {
 }
 
 // DefaultDueDate returns the default due date for items added to the provided
-// list ID. Lists that do not have a due date return nil.
-func DefaultDueDate(id ID, now time.Time) *time.Time {
+// list ID, using the calendar to decide which day now belongs to. Lists that do
+// not have a due date return nil.
+func DefaultDueDate(cal calendar.Calendar, id ID, now time.Time) *time.Time {
 	switch id {
 	case TodayID:
-		t := startOfDay(now)
+		t := cal.Today(now)
 		return &t
 	case TomorrowID:
-		t := startOfDay(now).Add(day)
+		t := cal.AddDays(cal.Today(now), 1)
 		return &t
 	default:
 		return nil
 	}
 }
-
-func startOfDay(t time.Time) time.Time {
-	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
-}
@@ -0,0 +1,220 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package merge
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+)
+
+// listField is the key under which a todo records when it last changed list.
+const listField = "list"
+
+// modifiedField is the JSON name of the field holding the modification times,
+// which is merged separately from the fields it describes.
+const modifiedField = "modified"
+
+// Tombstones records when todos were deleted, by ID.
+type Tombstones map[string]time.Time
+
+// Report describes how a merge changed ours, by todo title.
+type Report struct {
+	// Added lists the todos taken from theirs.
+	Added []string
+	// Updated lists the todos changed by theirs.
+	Updated []string
+	// Deleted lists the todos removed because they were deleted elsewhere.
+	Deleted []string
+}
+
+// Empty reports whether the merge changed nothing.
+func (r Report) Empty() bool {
+	return len(r.Added) == 0 && len(r.Updated) == 0 && len(r.Deleted) == 0
+}
+
+// LastWriterWins merges ours and theirs, two copies of the lists changed
+// independently with no record of where they diverged. Each field of a todo,
+// and the list holding it, takes the value that was changed most recently
+// according to the todo's modification times, with ties going to ours. A todo
+// missing from one copy is kept unless it was deleted after its last change.
+func LastWriterWins(ours, theirs Lists, deleted Tombstones) (Lists, Report) {
+	ourTodos := index(ours)
+	theirTodos := index(theirs)
+
+	var report Report
+	merged := make(Lists)
+	for _, def := range list.Default() {
+		merged[def.ID] = &model.TodoList{Name: def.Name, Todos: []model.Todo{}}
+	}
+
+	for _, id := range order(ours, theirs) {
+		o, inOurs := ourTodos[id]
+		t, inTheirs := theirTodos[id]
+
+		var result placed
+		switch {
+		case inOurs && inTheirs:
+			result = newest(o, t)
+			if !equal(result, o) {
+				report.Updated = append(report.Updated, result.todo.Title)
+			}
+		case inOurs:
+			if wasDeleted(o.todo, deleted) {
+				report.Deleted = append(report.Deleted, o.todo.Title)
+				continue
+			}
+			result = o
+		case inTheirs:
+			if wasDeleted(t.todo, deleted) {
+				continue
+			}
+			result = t
+			report.Added = append(report.Added, t.todo.Title)
+		}
+
+		l := merged[result.list]
+		if l == nil {
+			l = &model.TodoList{Todos: []model.Todo{}}
+			if def, ok := list.Lookup(result.list); ok {
+				l.Name = def.Name
+			}
+			merged[result.list] = l
+		}
+		l.Todos = append(l.Todos, result.todo)
+	}
+
+	return merged, report
+}
+
+// newest merges two copies of a todo field by field, taking the most recently
+// changed value of each.
+func newest(ours, theirs placed) placed {
+	o := fields(ours.todo)
+	t := fields(theirs.todo)
+
+	result := make(map[string]json.RawMessage, len(o))
+	modified := make(map[string]time.Time)
+	for _, m := range []map[string]json.RawMessage{o, t} {
+		for key := range m {
+			value := o[key]
+			if theirs.todo.ModifiedAt(key).After(ours.todo.ModifiedAt(key)) {
+				value = t[key]
+			}
+			if value != nil {
+				result[key] = value
+			}
+			latest(modified, key, ours.todo, theirs.todo)
+		}
+	}
+
+	merged := placed{list: ours.list}
+	if theirs.todo.ModifiedAt(listField).After(ours.todo.ModifiedAt(listField)) {
+		merged.list = theirs.list
+	}
+	latest(modified, listField, ours.todo, theirs.todo)
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return ours
+	}
+	if err := json.Unmarshal(data, &merged.todo); err != nil {
+		return ours
+	}
+
+	merged.todo.Modified = nil
+	if len(modified) > 0 {
+		merged.todo.Modified = modified
+	}
+
+	return merged
+}
+
+// latest records the later of the times either copy recorded for a field.
+func latest(modified map[string]time.Time, key string, copies ...model.Todo) {
+	for _, todo := range copies {
+		if at, ok := todo.Modified[key]; ok && at.After(modified[key]) {
+			modified[key] = at
+		}
+	}
+}
+
+// wasDeleted reports whether the todo was deleted after its last change.
+func wasDeleted(todo model.Todo, deleted Tombstones) bool {
+	at, ok := deleted[todo.ID]
+	if !ok {
+		return false
+	}
+
+	last := todo.CreatedAt
+	for _, changed := range todo.Modified {
+		if changed.After(last) {
+			last = changed
+		}
+	}
+
+	return !last.After(at)
+}
+
+// Stamp records the time now against every field of the todos in current
+// that differs from their copy in previous, including the list holding them,
+// and keeps the times recorded for the fields that did not change. Times
+// recorded in current are ignored. It returns the IDs of the todos in
+// previous that are no longer in any list.
+func Stamp(previous, current Lists, now time.Time) []string {
+	before := index(previous)
+	after := index(current)
+
+	for _, listID := range listIDs(current) {
+		todos := current[listID].Todos
+		for i := range todos {
+			todo := &todos[i]
+
+			prev, existed := before[todo.ID]
+			if !existed {
+				// A todo restored by undo may be older than its deletion.
+				todo.Modified = nil
+				if now.After(todo.CreatedAt) {
+					todo.Modified = map[string]time.Time{listField: now}
+				}
+				continue
+			}
+
+			modified := make(map[string]time.Time)
+			for key, at := range prev.todo.Modified {
+				modified[key] = at
+			}
+
+			p := fields(prev.todo)
+			c := fields(*todo)
+			for _, m := range []map[string]json.RawMessage{p, c} {
+				for key := range m {
+					if !bytes.Equal(p[key], c[key]) {
+						modified[key] = now
+					}
+				}
+			}
+			if prev.list != listID {
+				modified[listField] = now
+			}
+
+			todo.Modified = nil
+			if len(modified) > 0 {
+				todo.Modified = modified
+			}
+		}
+	}
+
+	var deleted []string
+	for _, id := range order(previous, nil) {
+		if _, kept := after[id]; !kept {
+			deleted = append(deleted, id)
+		}
+	}
+
+	return deleted
+}
@@ -0,0 +1,224 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+// Package merge reconciles copies of the todo lists that were changed
+// independently, matching todos across every list by ID.
+package merge
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+)
+
+// Lists holds a copy of the todo lists, keyed by list ID. Missing lists are
+// treated as empty.
+type Lists map[list.ID]*model.TodoList
+
+// placed is a todo along with the list holding it.
+type placed struct {
+	list list.ID
+	todo model.Todo
+}
+
+// ThreeWay merges ours and theirs, two copies of the lists changed
+// independently since base. Changes made on only one side are kept, and so
+// are todos added on either side. A todo deleted on one side is deleted unless
+// the other side changed it. When both sides changed the same field of a todo,
+// including the list it is in, ours wins.
+func ThreeWay(base, ours, theirs Lists) Lists {
+	baseTodos := index(base)
+	ourTodos := index(ours)
+	theirTodos := index(theirs)
+
+	merged := make(Lists)
+	for _, def := range list.Default() {
+		merged[def.ID] = &model.TodoList{Name: def.Name, Todos: []model.Todo{}}
+	}
+
+	for _, id := range order(ours, theirs) {
+		b, inBase := baseTodos[id]
+		o, inOurs := ourTodos[id]
+		t, inTheirs := theirTodos[id]
+
+		var result placed
+		switch {
+		case inOurs && inTheirs && !inBase:
+			result = o
+		case inOurs && inTheirs:
+			result = placed{
+				list: pick(b.list, o.list, t.list),
+				todo: mergeTodo(b.todo, o.todo, t.todo),
+			}
+		case inOurs:
+			if inBase && equal(b, o) {
+				continue
+			}
+			result = o
+		case inTheirs:
+			if inBase && equal(b, t) {
+				continue
+			}
+			result = t
+		}
+
+		l := merged[result.list]
+		if l == nil {
+			l = &model.TodoList{Todos: []model.Todo{}}
+			if def, ok := list.Lookup(result.list); ok {
+				l.Name = def.Name
+			}
+			merged[result.list] = l
+		}
+		l.Todos = append(l.Todos, result.todo)
+	}
+
+	return merged
+}
+
+// index returns every todo in the lists by ID. When an ID appears in more
+// than one list, as when a sync service kept both sides of a move, the copy
+// moved most recently wins, and otherwise the first in list order.
+func index(lists Lists) map[string]placed {
+	todos := make(map[string]placed)
+	for _, id := range listIDs(lists) {
+		for _, todo := range lists[id].Todos {
+			seen, ok := todos[todo.ID]
+			if !ok || todo.ModifiedAt(listField).After(seen.todo.ModifiedAt(listField)) {
+				todos[todo.ID] = placed{list: id, todo: todo}
+			}
+		}
+	}
+
+	return todos
+}
+
+// order returns the IDs of every todo in ours, in list order, followed by
+// those only in theirs.
+func order(ours, theirs Lists) []string {
+	var ids []string
+	seen := make(map[string]bool)
+
+	for _, lists := range []Lists{ours, theirs} {
+		for _, id := range listIDs(lists) {
+			for _, todo := range lists[id].Todos {
+				if !seen[todo.ID] {
+					seen[todo.ID] = true
+					ids = append(ids, todo.ID)
+				}
+			}
+		}
+	}
+
+	return ids
+}
+
+// listIDs returns the IDs of the lists present, with the default lists first
+// in UI order and any others after them in a stable order.
+func listIDs(lists Lists) []list.ID {
+	var ids, others []list.ID
+	for _, def := range list.Default() {
+		if lists[def.ID] != nil {
+			ids = append(ids, def.ID)
+		}
+	}
+	for id, l := range lists {
+		if _, ok := list.Lookup(id); !ok && l != nil {
+			others = append(others, id)
+		}
+	}
+	slices.Sort(others)
+
+	return append(ids, others...)
+}
+
+// mergeTodo merges the fields of a todo changed on both sides, taking each
+// field from theirs unless ours changed it.
+func mergeTodo(base, ours, theirs model.Todo) model.Todo {
+	b := fields(base)
+	o := fields(ours)
+	t := fields(theirs)
+
+	result := make(map[string]json.RawMessage, len(o))
+	for _, m := range []map[string]json.RawMessage{o, t} {
+		for key := range m {
+			value := o[key]
+			if bytes.Equal(o[key], b[key]) {
+				value = t[key]
+			}
+			if value != nil {
+				result[key] = value
+			}
+		}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return ours
+	}
+
+	var merged model.Todo
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return ours
+	}
+
+	modified := make(map[string]time.Time)
+	for key := range ours.Modified {
+		latest(modified, key, ours, theirs)
+	}
+	for key := range theirs.Modified {
+		latest(modified, key, ours, theirs)
+	}
+	if len(modified) > 0 {
+		merged.Modified = modified
+	}
+
+	return merged
+}
+
+// pick returns the value changed on only one side, preferring ours when both
+// changed it.
+func pick[T comparable](base, ours, theirs T) T {
+	if ours == base {
+		return theirs
+	}
+
+	return ours
+}
+
+// equal reports whether two todos are in the same list with the same fields,
+// regardless of when those fields were changed.
+func equal(a, b placed) bool {
+	if a.list != b.list {
+		return false
+	}
+
+	fa := fields(a.todo)
+	fb := fields(b.todo)
+	if len(fa) != len(fb) {
+		return false
+	}
+	for key, value := range fa {
+		if !bytes.Equal(value, fb[key]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fields returns the JSON encoding of each field of the todo, other than its
+// modification times.
+func fields(todo model.Todo) map[string]json.RawMessage {
+	data, _ := json.Marshal(todo)
+
+	var out map[string]json.RawMessage
+	_ = json.Unmarshal(data, &out)
+	delete(out, modifiedField)
+
+	return out
+}
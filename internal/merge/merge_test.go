@@ -0,0 +1,182 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package merge
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+)
+
+func lists(today, tomorrow []model.Todo) Lists {
+	return Lists{
+		list.TodayID:    {Name: "Today", Todos: today},
+		list.TomorrowID: {Name: "Tomorrow", Todos: tomorrow},
+	}
+}
+
+func todo(id, title string) model.Todo {
+	return model.Todo{ID: id, Title: title, CreatedAt: time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC)}
+}
+
+func titles(l *model.TodoList) []string {
+	var out []string
+	for _, t := range l.Todos {
+		out = append(out, t.ID+":"+t.Title)
+	}
+	return out
+}
+
+func TestThreeWayKeepsChangesFromBothSides(t *testing.T) {
+	milk := todo("1", "Buy milk")
+	call := todo("2", "Call Mum")
+	walk := todo("3", "Walk the dog")
+	base := lists([]model.Todo{milk, call, walk}, nil)
+
+	// Ours renames milk, completes call and deletes walk.
+	ourMilk := milk
+	ourMilk.Title = "Buy oat milk"
+	ourCall := call
+	ourCall.ToggleCompleted(time.Date(2025, time.January, 2, 10, 0, 0, 0, time.UTC))
+	ours := lists([]model.Todo{ourMilk, ourCall, todo("4", "Ours")}, nil)
+
+	// Theirs describes milk, renames call, moves walk to tomorrow, and adds one.
+	theirMilk := milk
+	theirMilk.Description = "From the corner shop"
+	theirCall := call
+	theirCall.Title = "Call Mum and Dad"
+	theirs := lists([]model.Todo{theirMilk, theirCall, todo("5", "Theirs")}, []model.Todo{walk})
+
+	merged := ThreeWay(base, ours, theirs)
+
+	today := merged[list.TodayID]
+	if got, want := titles(today), []string{"1:Buy oat milk", "2:Call Mum and Dad", "4:Ours", "5:Theirs"}; !slices.Equal(got, want) {
+		t.Fatalf("unexpected today list %v, want %v", got, want)
+	}
+	if today.Todos[0].Description != "From the corner shop" {
+		t.Fatalf("expected their description to be kept, got %q", today.Todos[0].Description)
+	}
+	if !today.Todos[1].Completed || today.Todos[1].CompletedAt == nil {
+		t.Fatalf("expected our completion to be kept, got %+v", today.Todos[1])
+	}
+
+	// Walk was deleted by ours but moved by theirs, so the change wins.
+	if got, want := titles(merged[list.TomorrowID]), []string{"3:Walk the dog"}; !slices.Equal(got, want) {
+		t.Fatalf("unexpected tomorrow list %v, want %v", got, want)
+	}
+}
+
+func TestThreeWayDeletesUnchangedTodos(t *testing.T) {
+	milk := todo("1", "Buy milk")
+	call := todo("2", "Call Mum")
+	base := lists([]model.Todo{milk, call}, nil)
+
+	ours := lists([]model.Todo{call}, nil)
+	theirs := lists([]model.Todo{milk}, nil)
+
+	if got := titles(ThreeWay(base, ours, theirs)[list.TodayID]); len(got) != 0 {
+		t.Fatalf("expected both deletions to be kept, got %v", got)
+	}
+}
+
+func TestThreeWayPrefersOursOnConflict(t *testing.T) {
+	milk := todo("1", "Buy milk")
+	base := lists([]model.Todo{milk}, nil)
+
+	ourMilk := milk
+	ourMilk.Title = "Buy oat milk"
+	theirMilk := milk
+	theirMilk.Title = "Buy soy milk"
+
+	merged := ThreeWay(base, lists(nil, []model.Todo{ourMilk}), lists([]model.Todo{theirMilk}, nil))
+
+	if got, want := titles(merged[list.TomorrowID]), []string{"1:Buy oat milk"}; !slices.Equal(got, want) {
+		t.Fatalf("unexpected tomorrow list %v, want %v", got, want)
+	}
+	if got := titles(merged[list.TodayID]); len(got) != 0 {
+		t.Fatalf("expected the todo to leave today, got %v", got)
+	}
+}
+
+func TestLastWriterWinsTakesTheNewestFields(t *testing.T) {
+	at := func(minutes int) time.Time {
+		return time.Date(2025, time.January, 2, 9, minutes, 0, 0, time.UTC)
+	}
+
+	milk := todo("1", "Buy milk")
+	call := todo("2", "Call Mum")
+	gone := todo("3", "Cancelled")
+	base := lists([]model.Todo{milk, call, gone}, nil)
+
+	ours := lists([]model.Todo{milk, call, gone}, nil)
+	ours[list.TodayID].Todos[0].Title = "Buy oat milk"
+	ours[list.TodayID].Todos[1].ToggleCompleted(at(20))
+	ours[list.TodayID].Todos = ours[list.TodayID].Todos[:2]
+	Stamp(base, ours, at(20))
+
+	theirs := lists([]model.Todo{milk, call, gone}, nil)
+	theirs[list.TodayID].Todos[0].Title = "Buy soy milk"
+	theirs[list.TodayID].Todos[0].Description = "From the corner shop"
+	theirs[list.TodayID].Todos[1].Title = "Call Mum and Dad"
+	theirs[list.TomorrowID].Todos = []model.Todo{todo("4", "Theirs")}
+	Stamp(base, theirs, at(10))
+
+	merged, report := LastWriterWins(ours, theirs, Tombstones{"3": at(20)})
+
+	if got, want := titles(merged[list.TodayID]), []string{"1:Buy oat milk", "2:Call Mum and Dad"}; !slices.Equal(got, want) {
+		t.Fatalf("unexpected today list %v, want %v", got, want)
+	}
+	if got := merged[list.TodayID].Todos[0].Description; got != "From the corner shop" {
+		t.Fatalf("expected their description, got %q", got)
+	}
+	if !merged[list.TodayID].Todos[1].Completed {
+		t.Fatalf("expected our completion to be kept")
+	}
+	if got, want := titles(merged[list.TomorrowID]), []string{"4:Theirs"}; !slices.Equal(got, want) {
+		t.Fatalf("unexpected tomorrow list %v, want %v", got, want)
+	}
+
+	if !slices.Equal(report.Added, []string{"Theirs"}) || !slices.Equal(report.Updated, []string{"Buy oat milk", "Call Mum and Dad"}) {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	// The deleted todo stays deleted, but one changed after its deletion is
+	// brought back.
+	theirs[list.TodayID].Todos[2].Title = "Not cancelled after all"
+	Stamp(base, theirs, at(30))
+	merged, _ = LastWriterWins(ours, theirs, Tombstones{"3": at(20)})
+	if got := titles(merged[list.TodayID]); len(got) != 3 || got[2] != "3:Not cancelled after all" {
+		t.Fatalf("expected the todo changed after its deletion, got %v", got)
+	}
+}
+
+func TestStampRecordsChangedFieldsAndDeletions(t *testing.T) {
+	created := time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC)
+	now := created.Add(time.Hour)
+
+	milk := todo("1", "Buy milk")
+	call := todo("2", "Call Mum")
+	previous := lists([]model.Todo{milk, call}, nil)
+
+	renamed := milk
+	renamed.Title = "Buy oat milk"
+	current := lists(nil, []model.Todo{renamed})
+
+	deleted := Stamp(previous, current, now)
+
+	if !slices.Equal(deleted, []string{"2"}) {
+		t.Fatalf("expected the missing todo to be deleted, got %v", deleted)
+	}
+
+	got := current[list.TomorrowID].Todos[0]
+	if !got.ModifiedAt("title").Equal(now) || !got.ModifiedAt(listField).Equal(now) {
+		t.Fatalf("expected the title and list to be stamped, got %v", got.Modified)
+	}
+	if !got.ModifiedAt("description").Equal(created) {
+		t.Fatalf("expected the description to be unchanged, got %v", got.Modified)
+	}
+}
@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package model
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+)
+
+// NewID returns a UUIDv7 for a todo created at the provided time. The leading
+// 48 bits hold the Unix millisecond timestamp so IDs sort by creation time,
+// and the remaining 74 bits are random so IDs generated within the same
+// millisecond, or by separate processes, do not collide.
+//
+// Todos loaded from disk may still carry legacy timestamp-based IDs; any
+// non-empty string is accepted as an ID.
+func NewID(now time.Time) string {
+	var uuid [16]byte
+
+	_, _ = rand.Read(uuid[6:])
+
+	ms := uint64(now.UnixMilli())
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], ms)
+	copy(uuid[:6], ts[2:])
+
+	uuid[6] = (uuid[6] & 0x0f) | 0x70 // version 7
+	uuid[8] = (uuid[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], uuid[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], uuid[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], uuid[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], uuid[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], uuid[10:])
+
+	return string(buf[:])
+}
@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package model
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+var uuidv7Pattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNewIDIsUUIDv7(t *testing.T) {
+	id := NewID(time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC))
+
+	if !uuidv7Pattern.MatchString(id) {
+		t.Fatalf("expected a UUIDv7, got %q", id)
+	}
+}
+
+func TestNewIDIsUniqueWithinTheSameInstant(t *testing.T) {
+	now := time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC)
+	seen := make(map[string]struct{})
+
+	for range 10000 {
+		id := NewID(now)
+		if _, dup := seen[id]; dup {
+			t.Fatalf("duplicate ID generated: %s", id)
+		}
+		seen[id] = struct{}{}
+	}
+}
+
+func TestNewIDSortsByCreationTime(t *testing.T) {
+	earlier := NewID(time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC))
+	later := NewID(time.Date(2025, time.January, 2, 9, 0, 1, 0, time.UTC))
+
+	if earlier >= later {
+		t.Fatalf("expected %s to sort before %s", earlier, later)
+	}
+}
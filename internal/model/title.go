@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// TitleCharLimit is the most characters a todo title may have, wherever it is
+// entered.
+const TitleCharLimit = 100
+
+var (
+	// ErrEmptyTitle is returned when a todo title is blank.
+	ErrEmptyTitle = errors.New("todo title cannot be blank")
+	// ErrTitleTooLong is returned when a todo title has more than
+	// TitleCharLimit characters.
+	ErrTitleTooLong = fmt.Errorf("todo title must be %d characters or fewer", TitleCharLimit)
+)
+
+// ValidateTitle reports whether title, with any surrounding space already
+// trimmed, can be used for a todo.
+func ValidateTitle(title string) error {
+	if title == "" {
+		return ErrEmptyTitle
+	}
+
+	if utf8.RuneCountInString(title) > TitleCharLimit {
+		return ErrTitleTooLong
+	}
+
+	return nil
+}
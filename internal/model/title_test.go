@@ -0,0 +1,28 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package model
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateTitle(t *testing.T) {
+	cases := []struct {
+		title string
+		want  error
+	}{
+		{"Call Mum", nil},
+		{"", ErrEmptyTitle},
+		{strings.Repeat("é", TitleCharLimit), nil},
+		{strings.Repeat("a", TitleCharLimit+1), ErrTitleTooLong},
+	}
+
+	for _, tc := range cases {
+		if err := ValidateTitle(tc.title); !errors.Is(err, tc.want) {
+			t.Fatalf("ValidateTitle(%q) = %v, want %v", tc.title, err, tc.want)
+		}
+	}
+}
@@ -3,7 +3,11 @@
 
 package model
 
-import "time"
+import (
+	"time"
+
+	"github.com/unfunco/t/internal/calendar"
+)
 
 // Todo represents a single todo item.
 type Todo struct {
@@ -14,6 +18,12 @@ type Todo struct {
 	CreatedAt   time.Time  `json:"created_at"`
 	CompletedAt *time.Time `json:"completed_at"`
 	DueDate     *time.Time `json:"due_date"`
+	Project     string     `json:"project,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	// Modified records when each field was last changed, keyed by its JSON
+	// name, with "list" for the list holding the todo. It lets copies of the
+	// todo edited on different devices be merged field by field.
+	Modified map[string]time.Time `json:"modified,omitempty"`
 }
 
 // TodoList represents a collection of todos with a name.
@@ -23,11 +33,10 @@ type TodoList struct {
 }
 
 // NewTodo creates a new todo item with the given title, description, and
-// optional due date.
-func NewTodo(title, description string, dueDate *time.Time) Todo {
-	now := time.Now()
+// optional due date, created at the provided time.
+func NewTodo(title, description string, dueDate *time.Time, now time.Time) Todo {
 	return Todo{
-		ID:          now.Format("20060102150405.000000"),
+		ID:          NewID(now),
 		Title:       title,
 		Description: description,
 		Completed:   false,
@@ -36,11 +45,11 @@ func NewTodo(title, description string, dueDate *time.Time) Todo {
 	}
 }
 
-// ToggleCompleted toggles the completion status of a Todo.
-func (t *Todo) ToggleCompleted() {
+// ToggleCompleted toggles the completion status of a Todo, recording the
+// provided time as the completion time.
+func (t *Todo) ToggleCompleted(now time.Time) {
 	t.Completed = !t.Completed
 	if t.Completed {
-		now := time.Now()
 		t.CompletedAt = &now
 	} else {
 		t.CompletedAt = nil
@@ -52,18 +61,29 @@ func (t *Todo) SetDueDate(dueDate *time.Time) {
 	t.DueDate = cloneTimePtr(dueDate)
 }
 
-// IsOverdue reports whether the todo is overdue relative to the provided time.
-func (t *Todo) IsOverdue(reference time.Time) bool {
+// IsOverdue reports whether the todo is overdue relative to the provided time,
+// using the calendar to decide which day the reference falls in.
+func (t *Todo) IsOverdue(cal calendar.Calendar, reference time.Time) bool {
 	if t.Completed || t.DueDate == nil {
 		return false
 	}
 
-	due := startOfDay(*t.DueDate)
-	ref := startOfDay(reference)
+	due := cal.Date(*t.DueDate)
+	ref := cal.Today(reference)
 
 	return due.Before(ref)
 }
 
+// ModifiedAt returns when the named field was last changed, which is when the
+// todo was created unless a later change was recorded.
+func (t *Todo) ModifiedAt(field string) time.Time {
+	if at, ok := t.Modified[field]; ok && at.After(t.CreatedAt) {
+		return at
+	}
+
+	return t.CreatedAt
+}
+
 func cloneTimePtr(in *time.Time) *time.Time {
 	if in == nil {
 		return nil
@@ -72,7 +92,3 @@ func cloneTimePtr(in *time.Time) *time.Time {
 	clone := *in
 	return &clone
 }
-
-func startOfDay(t time.Time) time.Time {
-	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
-}
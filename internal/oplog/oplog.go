@@ -0,0 +1,236 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+// Package oplog records an append-only history of changes made to todos, and
+// uses it to undo operations.
+package oplog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/storage"
+)
+
+// Filename is the name of the log file inside the data directory.
+const Filename = "events.jsonl"
+
+// Kind describes what happened to a todo.
+type Kind string
+
+const (
+	// KindCreated records a todo being added to a list.
+	KindCreated Kind = "created"
+	// KindEdited records a change to the content or due date of a todo.
+	KindEdited Kind = "edited"
+	// KindCompleted records a todo being marked as done.
+	KindCompleted Kind = "completed"
+	// KindReopened records a completed todo being marked as not done.
+	KindReopened Kind = "reopened"
+	// KindMoved records a todo being moved between lists by the user.
+	KindMoved Kind = "moved"
+	// KindDeleted records a todo being removed from every list.
+	KindDeleted Kind = "deleted"
+	// KindAutomationMoved records a todo being moved by a scheduled automation.
+	KindAutomationMoved Kind = "automation-moved"
+)
+
+// Source identifies what initiated an operation.
+type Source string
+
+const (
+	// SourceCLI marks operations run from the command line.
+	SourceCLI Source = "cli"
+	// SourceTUI marks operations saved from the interactive interface.
+	SourceTUI Source = "tui"
+	// SourceAPI marks operations requested through the HTTP API.
+	SourceAPI Source = "api"
+	// SourceAutomation marks operations performed by scheduled automations.
+	SourceAutomation Source = "automation"
+	// SourceUndo marks operations that revert an earlier operation.
+	SourceUndo Source = "undo"
+	// SourceReconcile marks conflicting copies of the lists, left by a sync
+	// service, merged back into them.
+	SourceReconcile Source = "reconcile"
+)
+
+// Event is a single entry in the operation log. Events written together share
+// an operation ID, which is the unit reverted by undo.
+type Event struct {
+	Op     string      `json:"op"`
+	Source Source      `json:"source"`
+	Time   time.Time   `json:"time"`
+	Kind   Kind        `json:"kind"`
+	TodoID string      `json:"todo_id"`
+	From   list.ID     `json:"from,omitempty"`
+	To     list.ID     `json:"to,omitempty"`
+	Before *model.Todo `json:"before,omitempty"`
+	After  *model.Todo `json:"after,omitempty"`
+	Undoes string      `json:"undoes,omitempty"`
+}
+
+// Title returns the most recent title known for the event's todo.
+func (e Event) Title() string {
+	if e.After != nil {
+		return e.After.Title
+	}
+	if e.Before != nil {
+		return e.Before.Title
+	}
+	return ""
+}
+
+// Read returns every event in the log inside dataDir, oldest first, decrypting
+// them with c. A missing log yields no events.
+func Read(dataDir string, c storage.Cipher) ([]Event, error) {
+	f, err := os.Open(filepath.Join(dataDir, Filename))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("open %s: %w", Filename, err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		e, err := decodeEvent(data, c)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s line %d: %w", Filename, line, err)
+		}
+		events = append(events, e)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", Filename, err)
+	}
+
+	return events, nil
+}
+
+// Rewrite replaces the log inside dataDir with a copy whose events are
+// decrypted with read and encrypted with write.
+func Rewrite(dataDir string, read, write storage.Cipher) error {
+	events, err := Read(dataDir, read)
+	if err != nil || len(events) == 0 {
+		return err
+	}
+
+	data, err := encodeEvents(events, write)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dataDir, Filename)
+	tmp, err := os.CreateTemp(dataDir, Filename+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file for %s: %w", Filename, err)
+	}
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write %s: %w", Filename, err)
+	}
+
+	return nil
+}
+
+// appendEvents writes the events to the log inside dataDir in a single write,
+// so an operation is never partially recorded.
+func appendEvents(dataDir string, events []Event, c storage.Cipher) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	data, err := encodeEvents(events, c)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dataDir, 0o700); err != nil {
+		return fmt.Errorf("create data directory: %w", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(dataDir, Filename), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", Filename, err)
+	}
+
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write %s: %w", Filename, err)
+	}
+
+	return f.Close()
+}
+
+// encodeEvents returns the log lines recording the events. When encrypted,
+// each event is encrypted on its own and written as a line of base64, so
+// that events can still be appended without rewriting the log.
+func encodeEvents(events []Event, c storage.Cipher) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, e := range events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return nil, fmt.Errorf("encode event: %w", err)
+		}
+
+		if c != storage.Plaintext {
+			encrypted, err := c.Encrypt(data)
+			if err != nil {
+				return nil, fmt.Errorf("encrypt event: %w", err)
+			}
+			data = []byte(base64.StdEncoding.EncodeToString(encrypted))
+		}
+
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decodeEvent parses a log line written by encodeEvents.
+func decodeEvent(data []byte, c storage.Cipher) (Event, error) {
+	var e Event
+
+	if data[0] != '{' {
+		encrypted, err := base64.StdEncoding.DecodeString(string(data))
+		if err != nil {
+			return e, err
+		}
+		if data, err = c.Decrypt(encrypted); err != nil {
+			return e, err
+		}
+	}
+
+	err := json.Unmarshal(data, &e)
+
+	return e, err
+}
@@ -0,0 +1,227 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package oplog
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/unfunco/t/internal/clock"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/storage"
+)
+
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+
+	dataDir := t.TempDir()
+	file, err := storage.NewFileStorageWithDir(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create file storage: %v", err)
+	}
+
+	now := time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC)
+
+	return NewStorage(file, dataDir, clock.Fixed(now))
+}
+
+func load(t *testing.T, s *Storage, def list.Definition) *model.TodoList {
+	t.Helper()
+
+	l, err := s.LoadList(def)
+	if err != nil {
+		t.Fatalf("LoadList() error = %v", err)
+	}
+
+	return l
+}
+
+func save(t *testing.T, s *Storage, def list.Definition, l *model.TodoList) {
+	t.Helper()
+
+	if err := s.SaveList(def, l); err != nil {
+		t.Fatalf("SaveList() error = %v", err)
+	}
+}
+
+func kinds(events []Event) []Kind {
+	out := make([]Kind, len(events))
+	for i, e := range events {
+		out[i] = e.Kind
+	}
+	return out
+}
+
+func TestStorageRecordsLifecycle(t *testing.T) {
+	s := newTestStorage(t)
+
+	save(t, s, list.Todos(), &model.TodoList{Todos: []model.Todo{{ID: "1", Title: "Write report"}}})
+
+	todos := load(t, s, list.Todos())
+	todos.Todos[0].Title = "Write the report"
+	todos.Todos[0].Completed = true
+	save(t, s, list.Todos(), todos)
+
+	save(t, s, list.Todos(), &model.TodoList{})
+
+	events, err := Read(s.DataDir(), s.Cipher())
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	got := kinds(events)
+	want := []Kind{KindCreated, KindCompleted, KindEdited, KindDeleted}
+	if len(got) != len(want) {
+		t.Fatalf("expected events %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected events %v, got %v", want, got)
+		}
+	}
+
+	if events[0].Op == events[1].Op || events[1].Op != events[2].Op {
+		t.Fatalf("expected events to be grouped by operation, got %+v", events)
+	}
+}
+
+func TestStorageRecordsMovesWithinAnOperation(t *testing.T) {
+	s := newTestStorage(t)
+
+	save(t, s, list.Today(), &model.TodoList{Todos: []model.Todo{{ID: "1", Title: "Move me"}}})
+
+	for _, source := range []Source{SourceTUI, SourceAutomation} {
+		from, to := list.Today(), list.Todos()
+		if source == SourceAutomation {
+			from, to = to, from
+		}
+
+		fromList := load(t, s, from)
+		toList := load(t, s, to)
+		toList.Todos = append(toList.Todos, fromList.Todos[0])
+		fromList.Todos = nil
+
+		// Save the source list first so that the todo briefly exists in
+		// neither file on disk.
+		s.Begin(source)
+		save(t, s, from, fromList)
+		save(t, s, to, toList)
+		if err := s.Commit(); err != nil {
+			t.Fatalf("Commit() error = %v", err)
+		}
+	}
+
+	events, err := Read(s.DataDir(), s.Cipher())
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	got := kinds(events)
+	want := []Kind{KindCreated, KindMoved, KindAutomationMoved}
+	if len(got) != len(want) {
+		t.Fatalf("expected events %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected events %v, got %v", want, got)
+		}
+	}
+
+	if events[1].From != list.TodayID || events[1].To != list.TodosID {
+		t.Fatalf("unexpected move: %+v", events[1])
+	}
+}
+
+func TestStorageCheckCancelsOperation(t *testing.T) {
+	s := newTestStorage(t)
+	veto := errors.New("vetoed")
+	s.Check(func([]Event) error { return veto })
+
+	s.Begin(SourceCLI)
+	save(t, s, list.Today(), &model.TodoList{Todos: []model.Todo{{ID: "1", Title: "Pending"}}})
+	if got := load(t, s, list.Today()); len(got.Todos) != 1 {
+		t.Fatalf("expected the pending save to be loaded, got %+v", got.Todos)
+	}
+
+	if err := s.Commit(); !errors.Is(err, ErrCancelled) || !errors.Is(err, veto) {
+		t.Fatalf("Commit() error = %v, want cancelled", err)
+	}
+
+	if got := load(t, s, list.Today()); len(got.Todos) != 0 {
+		t.Fatalf("expected nothing to be saved, got %+v", got.Todos)
+	}
+
+	events, err := Read(s.DataDir(), s.Cipher())
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected nothing to be recorded, got %+v", events)
+	}
+}
+
+func TestUndoRevertsLatestUserOperation(t *testing.T) {
+	s := newTestStorage(t)
+	defs := list.Default()
+
+	save(t, s, list.Today(), &model.TodoList{Todos: []model.Todo{{ID: "1", Title: "Keep me"}}})
+	save(t, s, list.Today(), &model.TodoList{Todos: []model.Todo{
+		{ID: "1", Title: "Keep me", Completed: true},
+		{ID: "2", Title: "Added later"},
+	}})
+
+	reverted, err := Undo(s, defs)
+	if err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+
+	if len(reverted) != 2 {
+		t.Fatalf("expected 2 reverted events, got %+v", reverted)
+	}
+
+	today := load(t, s, list.Today())
+	if len(today.Todos) != 1 || today.Todos[0].Completed {
+		t.Fatalf("expected the second operation to be reverted, got %+v", today.Todos)
+	}
+
+	if _, err := Undo(s, defs); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+
+	if today := load(t, s, list.Today()); len(today.Todos) != 0 {
+		t.Fatalf("expected the first operation to be reverted, got %+v", today.Todos)
+	}
+
+	if _, err := Undo(s, defs); !errors.Is(err, ErrNothingToUndo) {
+		t.Fatalf("expected ErrNothingToUndo, got %v", err)
+	}
+}
+
+func TestUndoRestoresMovedTodo(t *testing.T) {
+	s := newTestStorage(t)
+
+	save(t, s, list.Today(), &model.TodoList{Todos: []model.Todo{{ID: "1", Title: "Original"}}})
+
+	s.Begin(SourceTUI)
+	save(t, s, list.Today(), &model.TodoList{})
+	save(t, s, list.Tomorrow(), &model.TodoList{Todos: []model.Todo{{ID: "1", Title: "Renamed"}}})
+	if err := s.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	if _, err := Undo(s, list.Default()); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+
+	today := load(t, s, list.Today())
+	if len(today.Todos) != 1 || today.Todos[0].Title != "Original" {
+		t.Fatalf("expected todo back in today with its original title, got %+v", today.Todos)
+	}
+
+	if tomorrow := load(t, s, list.Tomorrow()); len(tomorrow.Todos) != 0 {
+		t.Fatalf("expected tomorrow to be empty, got %+v", tomorrow.Todos)
+	}
+}
@@ -0,0 +1,313 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package oplog
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/unfunco/t/internal/clock"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/storage"
+)
+
+// Storage wraps another storage backend and records the changes made by each
+// save in the operation log.
+//
+// Saves made between Begin and Commit form a single operation, which lets a
+// todo removed from one list and added to another be recorded as a move.
+// Saves made outside an operation are recorded as an operation of their own.
+// The lists saved in an operation are only written to the wrapped backend
+// when it is committed, so that the operation can still be cancelled.
+type Storage struct {
+	inner   storage.Storage
+	dataDir string
+	cipher  storage.Cipher
+	clock   clock.Clock
+	current *operation
+	check   func([]Event) error
+	notify  func([]Event) error
+}
+
+// ErrCancelled is returned by Commit when the function set by Check rejects
+// an operation, in which case none of its lists are saved.
+var ErrCancelled = errors.New("change cancelled")
+
+// operation accumulates the state of every list saved while it is open.
+type operation struct {
+	source Source
+	undoes string
+	order  []list.ID
+	before map[list.ID][]model.Todo
+	after  map[list.ID][]model.Todo
+	defs   map[list.ID]list.Definition
+	saved  map[list.ID]*model.TodoList
+}
+
+// operationStorage is storage that needs to know when the lists of an
+// operation are about to be saved, such as *backup.Storage.
+type operationStorage interface {
+	BeginOperation()
+}
+
+var _ storage.Storage = (*Storage)(nil)
+
+// NewStorage wraps inner so that saves are recorded in the log in dataDir.
+func NewStorage(inner storage.Storage, dataDir string, clk clock.Clock) *Storage {
+	return NewStorageWithCipher(inner, dataDir, storage.Plaintext, clk)
+}
+
+// NewStorageWithCipher wraps inner so that saves are recorded in the log in
+// dataDir, encrypted with c.
+func NewStorageWithCipher(inner storage.Storage, dataDir string, c storage.Cipher, clk clock.Clock) *Storage {
+	return &Storage{
+		inner:   inner,
+		dataDir: dataDir,
+		cipher:  c,
+		clock:   clk,
+	}
+}
+
+// DataDir returns the directory holding the log.
+func (s *Storage) DataDir() string {
+	return s.dataDir
+}
+
+// Cipher returns the cipher the log is encrypted with.
+func (s *Storage) Cipher() storage.Cipher {
+	return s.cipher
+}
+
+// Check sets a function called with the events of each operation before any
+// of its lists are saved, such as to run hooks that may veto it. An error it
+// returns cancels the operation and is returned by Commit.
+func (s *Storage) Check(fn func([]Event) error) {
+	s.check = fn
+}
+
+// Notify sets a function called with the events of each operation once they
+// are recorded, such as to run hooks. An error it returns is returned by
+// Commit, after the events have been recorded.
+func (s *Storage) Notify(fn func([]Event) error) {
+	s.notify = fn
+}
+
+// Begin starts an operation initiated by source. Any operation already in
+// progress is discarded without being recorded.
+func (s *Storage) Begin(source Source) {
+	s.current = &operation{
+		source: source,
+		before: make(map[list.ID][]model.Todo),
+		after:  make(map[list.ID][]model.Todo),
+		defs:   make(map[list.ID]list.Definition),
+		saved:  make(map[list.ID]*model.TodoList),
+	}
+}
+
+// beginUndo starts an operation that reverts the operation with the given ID.
+func (s *Storage) beginUndo(op string) {
+	s.Begin(SourceUndo)
+	s.current.undoes = op
+}
+
+// Commit saves the lists saved since Begin, records the changes made to them
+// and closes the operation.
+func (s *Storage) Commit() error {
+	op := s.current
+	s.current = nil
+
+	if op == nil {
+		return nil
+	}
+
+	now := s.clock.Now()
+	events := op.events(model.NewID(now), now)
+
+	if s.check != nil && len(events) > 0 {
+		if err := s.check(events); err != nil {
+			return fmt.Errorf("%w: %w", ErrCancelled, err)
+		}
+	}
+
+	if inner, ok := s.inner.(operationStorage); ok {
+		inner.BeginOperation()
+	}
+
+	for _, id := range op.order {
+		if err := s.inner.SaveList(op.defs[id], op.saved[id]); err != nil {
+			return err
+		}
+	}
+
+	if err := appendEvents(s.dataDir, events, s.cipher); err != nil {
+		return err
+	}
+
+	if s.notify == nil || len(events) == 0 {
+		return nil
+	}
+
+	return s.notify(events)
+}
+
+// LoadList implements storage.Storage. A list saved in the operation in
+// progress is returned as it was saved, even though it is not yet written.
+func (s *Storage) LoadList(def list.Definition) (*model.TodoList, error) {
+	if s.current != nil {
+		if saved, ok := s.current.saved[def.ID]; ok {
+			return &model.TodoList{Name: saved.Name, Todos: slices.Clone(saved.Todos)}, nil
+		}
+	}
+
+	return s.inner.LoadList(def)
+}
+
+// SaveList implements storage.Storage, saving the list and recording the
+// change once the operation it belongs to is committed.
+func (s *Storage) SaveList(def list.Definition, todoList *model.TodoList) error {
+	implicit := s.current == nil
+	if implicit {
+		s.Begin(SourceCLI)
+	}
+
+	op := s.current
+	if _, seen := op.before[def.ID]; !seen {
+		previous, err := s.inner.LoadList(def)
+		if err != nil {
+			if implicit {
+				s.current = nil
+			}
+			return fmt.Errorf("load %s list before saving: %w", def.Name, err)
+		}
+		op.order = append(op.order, def.ID)
+		op.before[def.ID] = slices.Clone(previous.Todos)
+	}
+
+	op.defs[def.ID] = def
+	op.saved[def.ID] = &model.TodoList{Name: todoList.Name, Todos: slices.Clone(todoList.Todos)}
+	op.after[def.ID] = slices.Clone(todoList.Todos)
+
+	if implicit {
+		return s.Commit()
+	}
+
+	return nil
+}
+
+// located is a todo along with the list it belongs to.
+type located struct {
+	list list.ID
+	todo model.Todo
+}
+
+// events computes the log entries describing the operation. Todos are matched
+// across every list saved in the operation, so a todo that leaves one list and
+// joins another is recorded as a move rather than a deletion and a creation.
+func (op *operation) events(id string, now time.Time) []Event {
+	before := op.index(op.before)
+	after := op.index(op.after)
+
+	base := Event{
+		Op:     id,
+		Source: op.source,
+		Time:   now,
+		Undoes: op.undoes,
+	}
+
+	var events []Event
+	add := func(kind Kind, todoID string, from, to list.ID, b, a *model.Todo) {
+		e := base
+		e.Kind = kind
+		e.TodoID = todoID
+		e.From = from
+		e.To = to
+		e.Before = b
+		e.After = a
+		events = append(events, e)
+	}
+
+	for _, listID := range op.order {
+		for _, todo := range op.after[listID] {
+			prev, existed := before[todo.ID]
+			cur := todo
+
+			if !existed {
+				add(KindCreated, todo.ID, "", listID, nil, &cur)
+				continue
+			}
+
+			prevTodo := prev.todo
+			if prev.list != listID {
+				kind := KindMoved
+				if op.source == SourceAutomation {
+					kind = KindAutomationMoved
+				}
+				add(kind, todo.ID, prev.list, listID, &prevTodo, &cur)
+			}
+
+			switch {
+			case !prevTodo.Completed && cur.Completed:
+				add(KindCompleted, todo.ID, listID, listID, &prevTodo, &cur)
+			case prevTodo.Completed && !cur.Completed:
+				add(KindReopened, todo.ID, listID, listID, &prevTodo, &cur)
+			}
+
+			if edited(prevTodo, cur) {
+				add(KindEdited, todo.ID, listID, listID, &prevTodo, &cur)
+			}
+		}
+	}
+
+	for _, listID := range op.order {
+		for _, todo := range op.before[listID] {
+			if _, kept := after[todo.ID]; kept {
+				continue
+			}
+
+			prev := todo
+			add(KindDeleted, todo.ID, listID, "", &prev, nil)
+		}
+	}
+
+	return events
+}
+
+// index maps todo IDs to their location. Lists that were saved in the
+// operation replace their previous contents, while lists that were not saved
+// keep their previous contents.
+func (op *operation) index(lists map[list.ID][]model.Todo) map[string]located {
+	out := make(map[string]located)
+
+	for _, listID := range op.order {
+		todos, ok := lists[listID]
+		if !ok {
+			todos = op.before[listID]
+		}
+		for _, todo := range todos {
+			out[todo.ID] = located{list: listID, todo: todo}
+		}
+	}
+
+	return out
+}
+
+// edited reports whether the user-visible content of a todo changed.
+func edited(before, after model.Todo) bool {
+	return before.Title != after.Title ||
+		before.Description != after.Description ||
+		before.Project != after.Project ||
+		!slices.Equal(before.Tags, after.Tags) ||
+		!sameTime(before.DueDate, after.DueDate)
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package oplog
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+)
+
+// ErrNothingToUndo is returned when the log holds no operation to revert.
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// Undo reverts the most recent command line or interface operation that has
+// not already been undone. The reverted lists are saved through s, so the undo
+// is itself recorded in the log. It returns the events that were reverted.
+func Undo(s *Storage, defs []list.Definition) ([]Event, error) {
+	events, err := Read(s.dataDir, s.cipher)
+	if err != nil {
+		return nil, err
+	}
+
+	target := lastUndoable(events)
+	if len(target) == 0 {
+		return nil, ErrNothingToUndo
+	}
+
+	lists := make(map[list.ID]*model.TodoList, len(defs))
+	for _, def := range defs {
+		l, err := s.LoadList(def)
+		if err != nil {
+			return nil, fmt.Errorf("load %s list: %w", def.Name, err)
+		}
+		lists[def.ID] = l
+	}
+
+	for i := len(target) - 1; i >= 0; i-- {
+		revert(lists, target[i])
+	}
+
+	s.beginUndo(target[0].Op)
+	for _, def := range defs {
+		if err := s.SaveList(def, lists[def.ID]); err != nil {
+			s.current = nil
+			return nil, fmt.Errorf("save %s list: %w", def.Name, err)
+		}
+	}
+
+	if err := s.Commit(); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// lastUndoable returns the events of the newest operation started from the
+// command line, the interface or the API that has not been undone.
+func lastUndoable(events []Event) []Event {
+	undone := make(map[string]bool)
+	for _, e := range events {
+		if e.Undoes != "" {
+			undone[e.Undoes] = true
+		}
+	}
+
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
+		if undone[e.Op] || (e.Source != SourceCLI && e.Source != SourceTUI && e.Source != SourceAPI) {
+			continue
+		}
+
+		start := i
+		for start > 0 && events[start-1].Op == e.Op {
+			start--
+		}
+
+		return events[start : i+1]
+	}
+
+	return nil
+}
+
+// revert applies the inverse of a single event to the lists.
+func revert(lists map[list.ID]*model.TodoList, e Event) {
+	switch e.Kind {
+	case KindCreated:
+		remove(lists, e.TodoID)
+	case KindDeleted, KindMoved, KindAutomationMoved:
+		if e.Before == nil {
+			return
+		}
+		remove(lists, e.TodoID)
+		if l := lists[e.From]; l != nil {
+			l.Todos = append(l.Todos, *e.Before)
+		}
+	case KindEdited, KindCompleted, KindReopened:
+		if e.Before == nil {
+			return
+		}
+		if l, i := find(lists, e.TodoID); l != nil {
+			l.Todos[i] = *e.Before
+		}
+	}
+}
+
+func find(lists map[list.ID]*model.TodoList, id string) (*model.TodoList, int) {
+	for _, l := range lists {
+		if l == nil {
+			continue
+		}
+		for i := range l.Todos {
+			if l.Todos[i].ID == id {
+				return l, i
+			}
+		}
+	}
+
+	return nil, -1
+}
+
+func remove(lists map[list.ID]*model.TodoList, id string) {
+	if l, i := find(lists, id); l != nil {
+		l.Todos = append(l.Todos[:i], l.Todos[i+1:]...)
+	}
+}
@@ -12,29 +12,65 @@ import (
 
 const app = "t"
 
+// LocalDirName is the name of a directory holding todo lists for a project,
+// which is used instead of the default data directory beneath it.
+const LocalDirName = ".t"
+
+// profilesDir is the directory beneath the data directory holding the data of
+// each named profile.
+const profilesDir = "profiles"
+
 // DefaultDataDir returns the standard directory used for mutable data.
 func DefaultDataDir() (string, error) {
-	return resolveDir("XDG_DATA_HOME", func(home string) string {
+	return ProfileDataDir("")
+}
+
+// ProfileDataDir returns the standard directory used for the mutable data of
+// the named profile, or DefaultDataDir when the name is empty.
+func ProfileDataDir(profile string) (string, error) {
+	return resolveDir("XDG_DATA_HOME", profile, func(home string) string {
 		return filepath.Join(home, ".local", "share")
 	})
 }
 
 // DefaultConfigDir returns the standard directory used for configuration.
 func DefaultConfigDir() (string, error) {
-	return resolveDir("XDG_CONFIG_HOME", func(home string) string {
+	return resolveDir("XDG_CONFIG_HOME", "", func(home string) string {
 		return filepath.Join(home, ".config")
 	})
 }
 
-func resolveDir(envVar string, fallback func(home string) string) (string, error) {
-	if dir := os.Getenv(envVar); dir != "" {
-		return filepath.Join(dir, app), nil
+// FindLocalDataDir returns the nearest project data directory, looking for a
+// directory named LocalDirName in start and each of its parents in turn.
+func FindLocalDataDir(start string) (string, bool) {
+	dir := filepath.Clean(start)
+	for {
+		candidate := filepath.Join(dir, LocalDirName)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+func resolveDir(envVar, profile string, fallback func(home string) string) (string, error) {
+	base := os.Getenv(envVar)
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get user home directory: %w", err)
+		}
+		base = fallback(home)
 	}
 
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	if profile != "" {
+		return filepath.Join(base, app, profilesDir, profile), nil
 	}
 
-	return filepath.Join(fallback(home), app), nil
+	return filepath.Join(base, app), nil
 }
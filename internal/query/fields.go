@@ -0,0 +1,226 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package query
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unfunco/t/internal/list"
+)
+
+// fields lists the fields that can be compared in a query.
+var fields = []string{"list", "title", "description", "text", "tag", "project", "id", "due", "created", "completed"}
+
+// parseComparison parses a comparison of field with the value that follows.
+func (p *parser) parseComparison(field token) (node, error) {
+	op := p.next()
+
+	value := p.next()
+	if value.kind != tokenWord && value.kind != tokenString {
+		return nil, p.errorf(value, "expected a value after %s%s", field.text, op.text)
+	}
+
+	name := strings.ToLower(field.text)
+	switch name {
+	case "list":
+		return p.listComparison(op, value)
+	case "title", "description", "text", "project", "id":
+		return p.textComparison(name, op, value)
+	case "tag":
+		return p.tagComparison(op, value)
+	case "due", "created", "completed":
+		return p.dateComparison(name, op, value)
+	default:
+		return nil, p.errorf(field, "unknown field %q, expected one of %s", field.text, strings.Join(fields, ", "))
+	}
+}
+
+func (p *parser) unsupported(field string, op token) error {
+	return p.errorf(op, "%s cannot be compared with %s", field, op.text)
+}
+
+func (p *parser) listComparison(op, value token) (node, error) {
+	var id list.ID
+	for _, def := range list.Default() {
+		if strings.EqualFold(value.text, string(def.ID)) || strings.EqualFold(value.text, def.Name) {
+			id = def.ID
+		}
+	}
+	if id == "" {
+		return nil, p.errorf(value, "unknown list %q", value.text)
+	}
+
+	is := predicate(func(s subject) bool { return s.list == id })
+
+	switch op.text {
+	case ":", "=":
+		return is, nil
+	case "!=":
+		return notNode{is}, nil
+	default:
+		return nil, p.unsupported("list", op)
+	}
+}
+
+func (p *parser) textComparison(field string, op, value token) (node, error) {
+	get := func(s subject) []string {
+		switch field {
+		case "title":
+			return []string{s.todo.Title}
+		case "description":
+			return []string{s.todo.Description}
+		case "project":
+			return []string{s.todo.Project}
+		case "id":
+			return []string{s.todo.ID}
+		default:
+			return []string{s.todo.Title, s.todo.Description}
+		}
+	}
+
+	// Fields are matched by substring, except IDs, which match by prefix.
+	contains := containsFold
+	if field == "id" {
+		contains = func(s, prefix string) bool { return strings.HasPrefix(s, prefix) }
+	}
+
+	equal := func(v string) bool { return strings.EqualFold(v, value.text) }
+
+	var match func(string) bool
+	switch {
+	case op.text == "~" || (op.text == ":" && field != "project"):
+		match = func(v string) bool { return contains(v, value.text) }
+	case op.text == "=" || op.text == ":":
+		match = equal
+	case op.text == "!=":
+		is := predicate(func(s subject) bool { return slices.ContainsFunc(get(s), equal) })
+		return notNode{is}, nil
+	default:
+		return nil, p.unsupported(field, op)
+	}
+
+	return predicate(func(s subject) bool { return slices.ContainsFunc(get(s), match) }), nil
+}
+
+func (p *parser) tagComparison(op, value token) (node, error) {
+	var match func(string) bool
+	switch op.text {
+	case ":", "=", "!=":
+		match = func(tag string) bool { return strings.EqualFold(tag, value.text) }
+	case "~":
+		match = func(tag string) bool { return containsFold(tag, value.text) }
+	default:
+		return nil, p.unsupported("tag", op)
+	}
+
+	has := predicate(func(s subject) bool { return slices.ContainsFunc(s.todo.Tags, match) })
+	if op.text == "!=" {
+		return notNode{has}, nil
+	}
+
+	return has, nil
+}
+
+func (p *parser) dateComparison(field string, op, value token) (node, error) {
+	day := func(s subject) *time.Time {
+		switch field {
+		case "due":
+			if s.todo.DueDate == nil {
+				return nil
+			}
+			d := p.cal.Date(*s.todo.DueDate)
+			return &d
+		case "created":
+			d := p.cal.Today(s.todo.CreatedAt)
+			return &d
+		default:
+			if s.todo.CompletedAt == nil {
+				return nil
+			}
+			d := p.cal.Today(*s.todo.CompletedAt)
+			return &d
+		}
+	}
+
+	if strings.EqualFold(value.text, "none") {
+		missing := predicate(func(s subject) bool { return day(s) == nil })
+		switch op.text {
+		case ":", "=":
+			return missing, nil
+		case "!=":
+			return notNode{missing}, nil
+		default:
+			return nil, p.unsupported(field+" none", op)
+		}
+	}
+
+	target, ok := p.date(value.text)
+	if !ok {
+		return nil, p.errorf(value, "invalid date %q, expected YYYY-MM-DD, today, tomorrow, yesterday or a relative date such as +3d", value.text)
+	}
+
+	var compare func(int) bool
+	switch op.text {
+	case ":", "=":
+		compare = func(c int) bool { return c == 0 }
+	case "!=":
+		compare = func(c int) bool { return c != 0 }
+	case "<":
+		compare = func(c int) bool { return c < 0 }
+	case "<=":
+		compare = func(c int) bool { return c <= 0 }
+	case ">":
+		compare = func(c int) bool { return c > 0 }
+	case ">=":
+		compare = func(c int) bool { return c >= 0 }
+	default:
+		return nil, p.unsupported(field, op)
+	}
+
+	return predicate(func(s subject) bool {
+		d := day(s)
+		return d != nil && compare(d.Compare(target))
+	}), nil
+}
+
+// date resolves a date written in a query to the start of that day.
+func (p *parser) date(value string) (time.Time, bool) {
+	switch strings.ToLower(value) {
+	case "today":
+		return p.today, true
+	case "tomorrow":
+		return p.cal.AddDays(p.today, 1), true
+	case "yesterday":
+		return p.cal.AddDays(p.today, -1), true
+	}
+
+	if d, err := time.ParseInLocation(time.DateOnly, value, p.cal.Location()); err == nil {
+		return d, true
+	}
+
+	if len(value) < 3 || (value[0] != '+' && value[0] != '-') {
+		return time.Time{}, false
+	}
+
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	switch value[len(value)-1] {
+	case 'd':
+		return p.cal.AddDays(p.today, n), true
+	case 'w':
+		return p.cal.AddDays(p.today, 7*n), true
+	case 'm':
+		return p.cal.Date(p.today.AddDate(0, n, 0)), true
+	case 'y':
+		return p.cal.Date(p.today.AddDate(n, 0, 0)), true
+	default:
+		return time.Time{}, false
+	}
+}
@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOperator
+	tokenOpen
+	tokenClose
+)
+
+// token is a lexical element of a query along with its position in the input.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// operators lists the comparison operators, longest first so that <= is not
+// read as < followed by =.
+var operators = []string{"<=", ">=", "!=", "<", ">", "=", ":", "~"}
+
+// lex splits a query into tokens.
+func lex(input string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(input); {
+		c := input[i]
+
+		switch {
+		case isSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenOpen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenClose, text: ")", pos: i})
+			i++
+		case c == '"' || c == '\'':
+			text, end, err := lexString(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, pos: i})
+			i = end
+		default:
+			if op := operatorAt(input, i); op != "" {
+				tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+				i += len(op)
+				continue
+			}
+
+			start := i
+			for i < len(input) && !endsWord(input, i) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: input[start:i], pos: start})
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+// lexString reads a quoted string starting at start, returning its unescaped
+// contents and the index after the closing quote.
+func lexString(input string, start int) (string, int, error) {
+	quote := input[start]
+	var b strings.Builder
+
+	for i := start + 1; i < len(input); i++ {
+		switch c := input[i]; {
+		case c == '\\' && i+1 < len(input):
+			i++
+			b.WriteByte(input[i])
+		case c == quote:
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated string at position %d", start+1)
+}
+
+func operatorAt(input string, i int) string {
+	for _, op := range operators {
+		if strings.HasPrefix(input[i:], op) {
+			return op
+		}
+	}
+
+	return ""
+}
+
+// endsWord reports whether the word being read ends at i. Quotes only start a
+// string at the beginning of a word, so apostrophes can be used within words.
+func endsWord(input string, i int) bool {
+	c := input[i]
+	return isSpace(c) || c == '(' || c == ')' || operatorAt(input, i) != ""
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,260 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+// Package query parses and evaluates filter expressions over todos, such as
+// "due < +3d and not done and tag:work".
+//
+// A query is made of terms combined with and, or and not, and grouped with
+// parentheses. Terms next to each other without an operator must all match.
+// A term is one of:
+//
+//   - a field comparison such as list:today, tag:work, title~standup or
+//     due <= 2025-01-31
+//   - the keywords done, open and overdue
+//   - any other word or quoted phrase, which matches todos whose title or
+//     description contains it
+//
+// Dates are written as YYYY-MM-DD, as today, tomorrow or yesterday, or
+// relative to today as a signed number of days, weeks, months or years such
+// as +3d or -1w. They are resolved once, when the query is parsed.
+package query
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/unfunco/t/internal/calendar"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+)
+
+// Query is a parsed filter expression.
+type Query struct {
+	input string
+	root  node
+}
+
+// subject is the todo being matched along with the list it belongs to.
+type subject struct {
+	todo *model.Todo
+	list list.ID
+}
+
+// node is an element of a parsed query.
+type node interface {
+	match(s subject) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) match(s subject) bool { return n.left.match(s) && n.right.match(s) }
+
+type orNode struct{ left, right node }
+
+func (n orNode) match(s subject) bool { return n.left.match(s) || n.right.match(s) }
+
+type notNode struct{ inner node }
+
+func (n notNode) match(s subject) bool { return !n.inner.match(s) }
+
+// predicate is a term of a query.
+type predicate func(s subject) bool
+
+func (p predicate) match(s subject) bool { return p(s) }
+
+// Parse parses a query. The calendar decides which day todos are due on and
+// which day now belongs to, which is used to resolve relative dates and to
+// decide which todos are overdue. An empty query matches every todo.
+func Parse(input string, cal calendar.Calendar, now time.Time) (*Query, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{
+		tokens: tokens,
+		cal:    cal,
+		now:    now,
+		today:  cal.Today(now),
+	}
+
+	q := &Query{input: strings.TrimSpace(input)}
+	if p.peek().kind == tokenEOF {
+		return q, nil
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	}
+
+	q.root = root
+	return q, nil
+}
+
+// Match reports whether the todo, which belongs to the given list, matches
+// the query.
+func (q *Query) Match(todo model.Todo, listID list.ID) bool {
+	if q == nil || q.root == nil {
+		return true
+	}
+
+	return q.root.match(subject{todo: &todo, list: listID})
+}
+
+// String returns the query as it was written.
+func (q *Query) String() string {
+	if q == nil {
+		return ""
+	}
+
+	return q.input
+}
+
+// Empty reports whether the query matches every todo because it has no terms.
+func (q *Query) Empty() bool {
+	return q == nil || q.root == nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	cal    calendar.Calendar
+	now    time.Time
+	today  time.Time
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return fmt.Errorf("invalid query at position %d: %s", t.pos+1, fmt.Sprintf(format, args...))
+}
+
+// isKeyword reports whether t is the given keyword, ignoring case.
+func isKeyword(t token, keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for isKeyword(p.peek(), "or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		switch {
+		case isKeyword(t, "and"):
+			p.next()
+		case t.kind == tokenWord && !isKeyword(t, "or"), t.kind == tokenString, t.kind == tokenOpen:
+			// Terms next to each other must all match.
+		default:
+			return left, nil
+		}
+
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+}
+
+func (p *parser) parseNot() (node, error) {
+	if isKeyword(p.peek(), "not") {
+		p.next()
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner}, nil
+	}
+
+	return p.parseTerm()
+}
+
+func (p *parser) parseTerm() (node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenOpen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenClose {
+			return nil, p.errorf(closing, "expected )")
+		}
+		return inner, nil
+	case tokenString:
+		return textMatch(t.text), nil
+	case tokenWord:
+		if p.peek().kind == tokenOperator {
+			return p.parseComparison(t)
+		}
+		return p.keyword(t), nil
+	case tokenEOF:
+		return nil, p.errorf(t, "unexpected end of query")
+	default:
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	}
+}
+
+// keyword returns the predicate for a bare word.
+func (p *parser) keyword(t token) node {
+	switch strings.ToLower(t.text) {
+	case "done", "completed":
+		return predicate(func(s subject) bool { return s.todo.Completed })
+	case "open":
+		return predicate(func(s subject) bool { return !s.todo.Completed })
+	case "overdue":
+		return predicate(func(s subject) bool { return s.todo.IsOverdue(p.cal, p.now) })
+	default:
+		return textMatch(t.text)
+	}
+}
+
+// textMatch matches todos whose title or description contains text.
+func textMatch(text string) node {
+	return predicate(func(s subject) bool {
+		return containsFold(s.todo.Title, text) || containsFold(s.todo.Description, text)
+	})
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
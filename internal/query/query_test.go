@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package query
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unfunco/t/internal/calendar"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+)
+
+var now = time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC)
+
+func date(year int, month time.Month, day int) *time.Time {
+	d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &d
+}
+
+type listedTodo struct {
+	list list.ID
+	todo model.Todo
+}
+
+func fixtures() []listedTodo {
+	completedAt := now.Add(-time.Hour)
+
+	return []listedTodo{
+		{list.TodayID, model.Todo{ID: "standup", Title: "Daily standup", Tags: []string{"work"}, CreatedAt: now, DueDate: date(2025, time.January, 2)}},
+		{list.TodayID, model.Todo{ID: "report", Title: "Write report", Description: "Quarterly numbers", Project: "Finance", Tags: []string{"work", "writing"}, CreatedAt: now.AddDate(0, 0, -3), DueDate: date(2024, time.December, 30)}},
+		{list.TomorrowID, model.Todo{ID: "milk", Title: "Buy milk", Tags: []string{"shop"}, CreatedAt: now, DueDate: date(2025, time.January, 3)}},
+		{list.TodosID, model.Todo{ID: "holiday", Title: "Plan holiday", Project: "Travel", CreatedAt: now.AddDate(0, 0, -10), DueDate: date(2025, time.January, 20)}},
+		{list.TodosID, model.Todo{ID: "someday", Title: "Learn Go's generics", CreatedAt: now}},
+		{list.TodayID, model.Todo{ID: "emails", Title: "Answer emails", Tags: []string{"work"}, CreatedAt: now, DueDate: date(2025, time.January, 2), Completed: true, CompletedAt: &completedAt}},
+	}
+}
+
+func TestParseMatches(t *testing.T) {
+	cal, err := calendar.New(0, time.UTC)
+	if err != nil {
+		t.Fatalf("calendar.New returned error: %v", err)
+	}
+
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"standup", "report", "milk", "holiday", "someday", "emails"}},
+		{"due < +3d and not done and tag:work", []string{"standup", "report"}},
+		{"list:today and title~standup", []string{"standup"}},
+		{"list=Tomorrow", []string{"milk"}},
+		{"list!=today", []string{"milk", "holiday", "someday"}},
+		{"overdue", []string{"report"}},
+		{"done", []string{"emails"}},
+		{"open tag:work", []string{"standup", "report"}},
+		{"tag:shop or project:travel", []string{"milk", "holiday"}},
+		{"tag~writ", []string{"report"}},
+		{"tag!=work and list:today", nil},
+		{"project~fin", []string{"report"}},
+		{"not (list:today or list:tomorrow)", []string{"holiday", "someday"}},
+		{"due:none", []string{"someday"}},
+		{"due = tomorrow", []string{"milk"}},
+		{"due >= 2025-01-03", []string{"milk", "holiday"}},
+		{"due > +1w", []string{"holiday"}},
+		{"created < -1w", []string{"holiday"}},
+		{"completed:today", []string{"emails"}},
+		{"id:s", []string{"standup", "someday"}},
+		{"quarterly", []string{"report"}},
+		{`"buy milk"`, []string{"milk"}},
+		{"go's", []string{"someday"}},
+		{"description:numbers", []string{"report"}},
+		{"text~holiday", []string{"holiday"}},
+	}
+
+	for _, tc := range cases {
+		q, err := Parse(tc.query, cal, now)
+		if err != nil {
+			t.Fatalf("Parse(%q) returned error: %v", tc.query, err)
+		}
+
+		var got []string
+		for _, f := range fixtures() {
+			if q.Match(f.todo, f.list) {
+				got = append(got, f.todo.ID)
+			}
+		}
+
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Fatalf("Parse(%q) matched %v, want %v", tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestParseRejectsInvalidQueries(t *testing.T) {
+	cal, err := calendar.New(0, time.UTC)
+	if err != nil {
+		t.Fatalf("calendar.New returned error: %v", err)
+	}
+
+	cases := map[string]string{
+		"(tag:work":       "expected )",
+		"tag:work)":       `unexpected ")"`,
+		"colour:red":      "unknown field",
+		"list:someday":    "unknown list",
+		"due < soon":      "invalid date",
+		"tag < work":      "tag cannot be compared with <",
+		"title:":          "expected a value",
+		"not":             "unexpected end of query",
+		`title:"unclosed`: "unterminated string",
+		"tag:work and":    "unexpected end of query",
+	}
+
+	for input, want := range cases {
+		_, err := Parse(input, cal, now)
+		if err == nil {
+			t.Fatalf("Parse(%q) expected an error", input)
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("Parse(%q) error = %q, want it to contain %q", input, err, want)
+		}
+	}
+}
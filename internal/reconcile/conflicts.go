@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package reconcile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/merge"
+	"github.com/unfunco/t/internal/storage"
+)
+
+// Reconciled describes a conflict copy merged into the lists.
+type Reconciled struct {
+	// File is the name of the conflict copy, which has been removed.
+	File string
+	merge.Report
+}
+
+// Conflicts merges every conflict copy of the list files in dataDir into the
+// lists held by store, then removes the copies and reports what each one
+// changed. Store should save the lists as given, keeping the modification
+// times the merge worked out. Copies are decrypted with c.
+func Conflicts(store storage.Storage, dataDir string, c storage.Cipher, now time.Time) ([]Reconciled, error) {
+	entries, err := os.ReadDir(dataDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read data directory: %w", err)
+	}
+
+	var deletedCopies []string
+	listCopies := make(map[list.ID][]string)
+	found := false
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		name := entry.Name()
+		if isConflictCopy(name, DeletedFilename) {
+			deletedCopies = append(deletedCopies, name)
+			found = true
+			continue
+		}
+		for _, def := range list.Default() {
+			if isConflictCopy(name, def.Filename) {
+				listCopies[def.ID] = append(listCopies[def.ID], name)
+				found = true
+			}
+		}
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	// Deletions only ever accumulate, so every copy's are kept.
+	tombstones, err := readDeleted(filepath.Join(dataDir, DeletedFilename))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range deletedCopies {
+		copied, err := readDeleted(filepath.Join(dataDir, name))
+		if err != nil {
+			return nil, err
+		}
+		for id, at := range copied {
+			if at.After(tombstones[id]) {
+				tombstones[id] = at
+			}
+		}
+	}
+
+	lists, err := loadLists(store)
+	if err != nil {
+		return nil, err
+	}
+
+	var reconciled []Reconciled
+	for _, def := range list.Default() {
+		for _, name := range listCopies[def.ID] {
+			data, err := os.ReadFile(filepath.Join(dataDir, name))
+			if err != nil {
+				return nil, fmt.Errorf("read %s: %w", name, err)
+			}
+
+			data, err = c.Decrypt(data)
+			if err != nil {
+				return nil, fmt.Errorf("decrypt %s: %w", name, err)
+			}
+
+			copied, err := storage.DecodeList(def, data)
+			if err != nil {
+				return nil, fmt.Errorf("parse %s: %w", name, err)
+			}
+
+			// The copy stands in for this list on the other device, which is
+			// assumed to have held the same todos in the other lists.
+			theirs := make(merge.Lists, len(lists))
+			for id, l := range lists {
+				theirs[id] = l
+			}
+			theirs[def.ID] = copied
+
+			var report merge.Report
+			lists, report = merge.LastWriterWins(lists, theirs, tombstones)
+			reconciled = append(reconciled, Reconciled{File: name, Report: report})
+		}
+	}
+
+	for _, def := range list.Default() {
+		if err := store.SaveList(def, lists[def.ID]); err != nil {
+			return nil, fmt.Errorf("save %s list: %w", def.Name, err)
+		}
+	}
+
+	err = updateDeleted(dataDir, now, func(existing merge.Tombstones) {
+		for id, at := range tombstones {
+			existing[id] = at
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range deletedCopies {
+		if err := os.Remove(filepath.Join(dataDir, name)); err != nil {
+			return nil, fmt.Errorf("remove %s: %w", name, err)
+		}
+	}
+	for _, r := range reconciled {
+		if err := os.Remove(filepath.Join(dataDir, r.File)); err != nil {
+			return nil, fmt.Errorf("remove %s: %w", r.File, err)
+		}
+	}
+
+	return reconciled, nil
+}
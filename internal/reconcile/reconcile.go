@@ -0,0 +1,205 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+// Package reconcile keeps a data directory shared by file synchronisation
+// services, such as Syncthing and Dropbox, consistent across devices.
+//
+// Every save records when each field of a todo changed and when todos were
+// deleted. When two devices change a list file at the same time, the service
+// keeps one version and leaves the other alongside it as a conflict copy;
+// Conflicts merges those copies back into the lists, field by field, using
+// the most recent change to each.
+package reconcile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/unfunco/t/internal/clock"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/merge"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/storage"
+)
+
+// DeletedFilename is the name of the file recording when todos were deleted.
+const DeletedFilename = "deleted.json"
+
+// retention is how long deletions are remembered. A conflict copy older than
+// this could bring a deleted todo back.
+const retention = 90 * 24 * time.Hour
+
+// Storage wraps another storage backend and records when the fields of each
+// saved todo changed, and when todos were deleted, so that conflicting copies
+// of the lists can be merged.
+type Storage struct {
+	inner   storage.Storage
+	dataDir string
+	clock   clock.Clock
+}
+
+var _ storage.Storage = (*Storage)(nil)
+
+// NewStorage wraps inner, which stores its lists in dataDir.
+func NewStorage(inner storage.Storage, dataDir string, clk clock.Clock) *Storage {
+	return &Storage{inner: inner, dataDir: dataDir, clock: clk}
+}
+
+// LoadList implements storage.Storage.
+func (s *Storage) LoadList(def list.Definition) (*model.TodoList, error) {
+	return s.inner.LoadList(def)
+}
+
+// SaveList implements storage.Storage, recording the time against every field
+// that changed since the list was last saved.
+func (s *Storage) SaveList(def list.Definition, todoList *model.TodoList) error {
+	previous, err := loadLists(s.inner)
+	if err != nil {
+		return err
+	}
+
+	current := make(merge.Lists, len(previous))
+	for id, l := range previous {
+		current[id] = l
+	}
+	stamped := &model.TodoList{Name: todoList.Name, Todos: slices.Clone(todoList.Todos)}
+	current[def.ID] = stamped
+
+	now := s.clock.Now()
+	deleted := merge.Stamp(previous, current, now)
+
+	if err := s.inner.SaveList(def, stamped); err != nil {
+		return err
+	}
+
+	// Todos saved to this list are no longer deleted, as happens when a
+	// deletion is undone or a todo moves between lists.
+	return updateDeleted(s.dataDir, now, func(tombstones merge.Tombstones) {
+		for _, id := range deleted {
+			tombstones[id] = now
+		}
+		for _, todo := range stamped.Todos {
+			delete(tombstones, todo.ID)
+		}
+	})
+}
+
+// loadLists loads every default list.
+func loadLists(store storage.Storage) (merge.Lists, error) {
+	lists := make(merge.Lists)
+	for _, def := range list.Default() {
+		l, err := store.LoadList(def)
+		if err != nil {
+			return nil, fmt.Errorf("load %s list: %w", def.Name, err)
+		}
+		lists[def.ID] = l
+	}
+
+	return lists, nil
+}
+
+// deletedDocument is the on-disk representation of the deleted todos.
+type deletedDocument struct {
+	Deleted merge.Tombstones `json:"deleted"`
+}
+
+// readDeleted reads the deletions recorded in the named file.
+func readDeleted(path string) (merge.Tombstones, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return merge.Tombstones{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", filepath.Base(path), err)
+	}
+
+	var doc deletedDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+	if doc.Deleted == nil {
+		doc.Deleted = merge.Tombstones{}
+	}
+
+	return doc.Deleted, nil
+}
+
+// updateDeleted applies fn to the recorded deletions and saves them, dropping
+// those older than the retention period.
+func updateDeleted(dataDir string, now time.Time, fn func(merge.Tombstones)) error {
+	path := filepath.Join(dataDir, DeletedFilename)
+
+	tombstones, err := readDeleted(path)
+	if err != nil {
+		return err
+	}
+
+	before := len(tombstones)
+	fn(tombstones)
+	for id, at := range tombstones {
+		if now.Sub(at) > retention {
+			delete(tombstones, id)
+		}
+	}
+
+	if before == 0 && len(tombstones) == 0 {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(deletedDocument{Deleted: tombstones}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", DeletedFilename, err)
+	}
+
+	return writeFile(path, data)
+}
+
+// writeFile atomically replaces the file at path.
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file for %s: %w", filepath.Base(path), err)
+	}
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write %s: %w", filepath.Base(path), err)
+	}
+
+	return nil
+}
+
+// isConflictCopy reports whether name is a conflict copy of the named file,
+// as left by Syncthing (today.sync-conflict-20250102-090000-ABCDEFG.json),
+// Dropbox (today (Daniel's conflicted copy 2025-01-02).json) or Nextcloud
+// (today (conflicted copy 2025-01-02 090000).json).
+func isConflictCopy(name, of string) bool {
+	ext := filepath.Ext(of)
+	stem := strings.TrimSuffix(of, ext)
+
+	if name == of || !strings.HasPrefix(name, stem) || !strings.HasSuffix(name, ext) {
+		return false
+	}
+
+	marker := strings.TrimSuffix(strings.TrimPrefix(name, stem), ext)
+	if strings.HasPrefix(marker, ".sync-conflict-") {
+		return true
+	}
+
+	return strings.HasPrefix(marker, " (") &&
+		strings.HasSuffix(marker, ")") &&
+		strings.Contains(strings.ToLower(marker), "conflict")
+}
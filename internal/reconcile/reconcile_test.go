@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package reconcile
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/unfunco/t/internal/clock"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/storage"
+)
+
+var start = time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC)
+
+// device is a copy of the data directory on one machine.
+type device struct {
+	t   *testing.T
+	dir string
+	now time.Time
+}
+
+func newDevice(t *testing.T) *device {
+	return &device{t: t, dir: t.TempDir(), now: start}
+}
+
+func (d *device) store() *Storage {
+	file, err := storage.NewFileStorageWithDir(d.dir)
+	if err != nil {
+		d.t.Fatalf("failed to create storage: %v", err)
+	}
+
+	return NewStorage(file, d.dir, clock.Func(func() time.Time { return d.now }))
+}
+
+// edit changes today's list at the given number of minutes past the start.
+func (d *device) edit(minutes int, fn func(l *model.TodoList)) {
+	d.t.Helper()
+	d.now = start.Add(time.Duration(minutes) * time.Minute)
+
+	s := d.store()
+	l, err := s.LoadList(list.Today())
+	if err != nil {
+		d.t.Fatalf("LoadList() error = %v", err)
+	}
+
+	fn(l)
+
+	if err := s.SaveList(list.Today(), l); err != nil {
+		d.t.Fatalf("SaveList() error = %v", err)
+	}
+}
+
+// copyTo copies a file from the device's data directory to another.
+func (d *device) copyTo(other *device, from, to string) {
+	d.t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(d.dir, from))
+	if err != nil {
+		d.t.Fatalf("failed to read %s: %v", from, err)
+	}
+	if err := os.WriteFile(filepath.Join(other.dir, to), data, 0o600); err != nil {
+		d.t.Fatalf("failed to write %s: %v", to, err)
+	}
+}
+
+func titles(l *model.TodoList) []string {
+	var out []string
+	for _, todo := range l.Todos {
+		out = append(out, todo.Title)
+	}
+	return out
+}
+
+func TestConflictsMergesConflictCopies(t *testing.T) {
+	laptop := newDevice(t)
+	desktop := newDevice(t)
+
+	laptop.edit(0, func(l *model.TodoList) {
+		l.Todos = append(l.Todos,
+			model.NewTodo("Buy milk", "", nil, start),
+			model.NewTodo("Call Mum", "", nil, start.Add(time.Second)),
+			model.NewTodo("Cancelled", "", nil, start.Add(2*time.Second)),
+		)
+	})
+	laptop.copyTo(desktop, "today.json", "today.json")
+
+	// Both devices change today's list before syncing again.
+	desktop.edit(10, func(l *model.TodoList) {
+		l.Todos[0].Title = "Buy oat milk"
+		l.Todos = append(l.Todos, model.NewTodo("Walk the dog", "", nil, start.Add(10*time.Minute)))
+	})
+	laptop.edit(20, func(l *model.TodoList) {
+		l.Todos[0].ToggleCompleted(start.Add(20 * time.Minute))
+		l.Todos = l.Todos[:2]
+	})
+
+	conflict := "today.sync-conflict-20250102-091000-ABCDEFG.json"
+	desktop.copyTo(laptop, "today.json", conflict)
+
+	file, err := storage.NewFileStorageWithDir(laptop.dir)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+
+	reconciled, err := Conflicts(file, laptop.dir, storage.Plaintext, start.Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("Conflicts() error = %v", err)
+	}
+
+	if len(reconciled) != 1 || reconciled[0].File != conflict {
+		t.Fatalf("expected the conflict copy to be reconciled, got %+v", reconciled)
+	}
+	if r := reconciled[0]; !slices.Equal(r.Added, []string{"Walk the dog"}) || !slices.Equal(r.Updated, []string{"Buy oat milk"}) {
+		t.Fatalf("unexpected report %+v", r.Report)
+	}
+
+	today, err := file.LoadList(list.Today())
+	if err != nil {
+		t.Fatalf("LoadList() error = %v", err)
+	}
+
+	// The deletion made on the laptop after the desktop's copy was taken wins.
+	if got, want := titles(today), []string{"Buy oat milk", "Call Mum", "Walk the dog"}; !slices.Equal(got, want) {
+		t.Fatalf("unexpected todos %v, want %v", got, want)
+	}
+	if !today.Todos[0].Completed {
+		t.Fatalf("expected the completion made on the laptop to be kept")
+	}
+
+	if _, err := os.Stat(filepath.Join(laptop.dir, conflict)); !os.IsNotExist(err) {
+		t.Fatalf("expected the conflict copy to be removed, got %v", err)
+	}
+
+	if reconciled, err := Conflicts(file, laptop.dir, storage.Plaintext, start.Add(time.Hour)); err != nil || len(reconciled) != 0 {
+		t.Fatalf("expected nothing left to reconcile, got %+v, %v", reconciled, err)
+	}
+}
+
+func TestIsConflictCopy(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"today.json", false},
+		{"today.sync-conflict-20250102-091000-ABCDEFG.json", true},
+		{"today (Daniel's conflicted copy 2025-01-02).json", true},
+		{"today (conflicted copy 2025-01-02 091000).json", true},
+		{"today (copy).json", false},
+		{"today.json.tmp-123", false},
+		{"todo.sync-conflict-20250102-091000-ABCDEFG.json", false},
+	}
+
+	for _, tt := range tests {
+		if got := isConflictCopy(tt.name, "today.json"); got != tt.want {
+			t.Errorf("isConflictCopy(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+// Package rpc serves tools over JSON-RPC 2.0, one message per line, as used by
+// the stdio transport of the Model Context Protocol.
+//
+// Each tool can be called directly as a method of its own, with its arguments
+// as the params, or through the MCP methods initialize, tools/list and
+// tools/call.
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// Version is the JSON-RPC version spoken by the server.
+const Version = "2.0"
+
+// protocolVersions lists the MCP revisions the server supports, newest first.
+var protocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	// CodeToolError is returned when a tool called directly fails.
+	CodeToolError = -32000
+)
+
+// Tool is an operation offered by the server.
+type Tool struct {
+	// Name is the name of the tool and of the method that calls it.
+	Name string
+	// Description tells clients what the tool does.
+	Description string
+	// InputSchema is the JSON Schema of the tool's arguments.
+	InputSchema json.RawMessage
+	// Call runs the tool with the given arguments. Strings are returned to MCP
+	// clients as they are, and other results as JSON.
+	Call func(ctx context.Context, args json.RawMessage) (any, error)
+}
+
+// Server answers requests read from a stream.
+type Server struct {
+	name    string
+	version string
+	tools   []Tool
+}
+
+// NewServer returns a server offering the given tools, which describes itself
+// to MCP clients with name and version.
+func NewServer(name, version string, tools []Tool) *Server {
+	return &Server{name: name, version: version, tools: tools}
+}
+
+// Error is a JSON-RPC error.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// InvalidParams returns an error reported with CodeInvalidParams.
+func InvalidParams(format string, args ...any) error {
+	return &Error{Code: CodeInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+// DecodeArgs decodes the arguments of a tool into v, rejecting unknown
+// fields.
+func DecodeArgs(args json.RawMessage, v any) error {
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage("{}")
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(args))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		return InvalidParams("invalid arguments: %v", err)
+	}
+
+	return nil
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Serve answers the requests read from r, one per line, until r is exhausted
+// or ctx is cancelled. Responses are written to w, one per line.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	in := bufio.NewReader(r)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	for ctx.Err() == nil {
+		line, err := in.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			if reply := s.handleMessage(ctx, line); reply != nil {
+				if err := enc.Encode(reply); err != nil {
+					return fmt.Errorf("write response: %w", err)
+				}
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read request: %w", err)
+		}
+	}
+
+	return ctx.Err()
+}
+
+// handleMessage answers a single request or a batch, returning nil when
+// nothing needs to be sent back.
+func (s *Server) handleMessage(ctx context.Context, msg []byte) any {
+	if msg[0] != '[' {
+		var req request
+		if err := json.Unmarshal(msg, &req); err != nil {
+			return errorResponse(nil, &Error{Code: CodeParseError, Message: err.Error()})
+		}
+		if reply := s.handle(ctx, req); reply != nil {
+			return reply
+		}
+		return nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(msg, &batch); err != nil {
+		return errorResponse(nil, &Error{Code: CodeParseError, Message: err.Error()})
+	}
+	if len(batch) == 0 {
+		return errorResponse(nil, &Error{Code: CodeInvalidRequest, Message: "empty batch"})
+	}
+
+	var replies []*response
+	for _, raw := range batch {
+		var req request
+		if err := json.Unmarshal(raw, &req); err != nil {
+			replies = append(replies, errorResponse(nil, &Error{Code: CodeInvalidRequest, Message: err.Error()}))
+			continue
+		}
+		if reply := s.handle(ctx, req); reply != nil {
+			replies = append(replies, reply)
+		}
+	}
+
+	if len(replies) == 0 {
+		return nil
+	}
+
+	return replies
+}
+
+// handle answers a request, returning nil for notifications.
+func (s *Server) handle(ctx context.Context, req request) *response {
+	notification := len(req.ID) == 0
+
+	if req.JSONRPC != Version || req.Method == "" {
+		if notification {
+			return nil
+		}
+		return errorResponse(req.ID, &Error{Code: CodeInvalidRequest, Message: `requests must set jsonrpc to "2.0" and a method`})
+	}
+
+	result, err := s.call(ctx, req.Method, req.Params)
+	if notification {
+		return nil
+	}
+
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeToolError, Message: err.Error()}
+		}
+		return errorResponse(req.ID, rpcErr)
+	}
+
+	// A successful response must carry a result, even when there is nothing
+	// to report.
+	if result == nil {
+		result = struct{}{}
+	}
+
+	return &response{JSONRPC: Version, ID: req.ID, Result: result}
+}
+
+func errorResponse(id json.RawMessage, err *Error) *response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+
+	return &response{JSONRPC: Version, ID: id, Error: err}
+}
+
+// call runs a method.
+func (s *Server) call(ctx context.Context, method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "initialize":
+		return s.initialize(params)
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return s.listTools(), nil
+	case "tools/call":
+		return s.callTool(ctx, params)
+	case "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+	}
+
+	if tool, ok := s.tool(method); ok {
+		return tool.Call(ctx, params)
+	}
+
+	return nil, &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("method %q not found", method)}
+}
+
+func (s *Server) tool(name string) (Tool, bool) {
+	i := slices.IndexFunc(s.tools, func(t Tool) bool { return t.Name == name })
+	if i < 0 {
+		return Tool{}, false
+	}
+
+	return s.tools[i], true
+}
+
+// initialize answers the MCP handshake, agreeing to the protocol revision the
+// client asks for when it is supported.
+func (s *Server) initialize(params json.RawMessage) (any, error) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, InvalidParams("invalid initialize params: %v", err)
+		}
+	}
+
+	version := protocolVersions[0]
+	if slices.Contains(protocolVersions, p.ProtocolVersion) {
+		version = p.ProtocolVersion
+	}
+
+	return map[string]any{
+		"protocolVersion": version,
+		"capabilities": map[string]any{
+			"tools": map[string]any{"listChanged": false},
+		},
+		"serverInfo": map[string]string{
+			"name":    s.name,
+			"version": s.version,
+		},
+	}, nil
+}
+
+type toolDescription struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+}
+
+func (s *Server) listTools() any {
+	tools := make([]toolDescription, 0, len(s.tools))
+	for _, t := range s.tools {
+		tools = append(tools, toolDescription{Name: t.Name, Description: t.Description, InputSchema: t.InputSchema})
+	}
+
+	return map[string]any{"tools": tools}
+}
+
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type toolResult struct {
+	Content []content `json:"content"`
+	IsError bool      `json:"isError"`
+}
+
+// callTool runs a tool for tools/call. Failures of the tool itself are
+// reported in the result, as MCP expects, rather than as JSON-RPC errors.
+func (s *Server) callTool(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, InvalidParams("invalid tools/call params: %v", err)
+	}
+
+	tool, ok := s.tool(p.Name)
+	if !ok {
+		return nil, InvalidParams("unknown tool %q", p.Name)
+	}
+
+	result, err := tool.Call(ctx, p.Arguments)
+	if err != nil {
+		return toolResult{Content: []content{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+
+	text, ok := result.(string)
+	if !ok {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("encode result: %w", err)
+		}
+		text = string(data)
+	}
+
+	return toolResult{Content: []content{{Type: "text", Text: text}}}, nil
+}
@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTestServer() *Server {
+	return NewServer("t", "v1.0.0", []Tool{
+		{
+			Name:        "echo",
+			Description: "Echo the text back.",
+			InputSchema: json.RawMessage(`{"type":"object"}`),
+			Call: func(_ context.Context, raw json.RawMessage) (any, error) {
+				var args struct {
+					Text string `json:"text"`
+				}
+				if err := DecodeArgs(raw, &args); err != nil {
+					return nil, err
+				}
+				if args.Text == "" {
+					return nil, errors.New("nothing to echo")
+				}
+				return args.Text, nil
+			},
+		},
+	})
+}
+
+func serve(t *testing.T, lines ...string) []map[string]any {
+	t.Helper()
+
+	var out bytes.Buffer
+	if err := newTestServer().Serve(context.Background(), strings.NewReader(strings.Join(lines, "\n")), &out); err != nil {
+		t.Fatalf("Serve returned error: %v", err)
+	}
+
+	var replies []map[string]any
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var reply map[string]any
+		if err := dec.Decode(&reply); err != nil {
+			t.Fatalf("failed to decode reply: %v", err)
+		}
+		replies = append(replies, reply)
+	}
+
+	return replies
+}
+
+func TestServeAnswersMCPRequests(t *testing.T) {
+	replies := serve(t,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hello"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"echo","arguments":{}}}`,
+	)
+
+	if len(replies) != 4 {
+		t.Fatalf("expected 4 replies, the notification unanswered, got %d", len(replies))
+	}
+
+	init := replies[0]["result"].(map[string]any)
+	if init["protocolVersion"] != "2024-11-05" || init["serverInfo"].(map[string]any)["name"] != "t" {
+		t.Fatalf("unexpected initialize result %v", init)
+	}
+
+	tools := replies[1]["result"].(map[string]any)["tools"].([]any)
+	if len(tools) != 1 || tools[0].(map[string]any)["name"] != "echo" {
+		t.Fatalf("unexpected tools %v", tools)
+	}
+
+	ok := replies[2]["result"].(map[string]any)
+	if text := ok["content"].([]any)[0].(map[string]any)["text"]; text != "hello" || ok["isError"] != false {
+		t.Fatalf("unexpected tools/call result %v", ok)
+	}
+
+	failed := replies[3]["result"].(map[string]any)
+	if text := failed["content"].([]any)[0].(map[string]any)["text"]; text != "nothing to echo" || failed["isError"] != true {
+		t.Fatalf("expected the tool error in the result, got %v", failed)
+	}
+}
+
+func TestServeAnswersDirectCallsAndErrors(t *testing.T) {
+	replies := serve(t,
+		`{"jsonrpc":"2.0","id":1,"method":"echo","params":{"text":"hi"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"echo","params":{"text":""}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"echo","params":{"colour":"red"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"missing"}`,
+		`{"jsonrpc":"1.0","id":5,"method":"echo"}`,
+		`not json`,
+	)
+
+	if len(replies) != 6 {
+		t.Fatalf("expected 6 replies, got %d", len(replies))
+	}
+
+	if replies[0]["result"] != "hi" {
+		t.Fatalf("unexpected direct result %v", replies[0])
+	}
+
+	wantCodes := []float64{CodeToolError, CodeInvalidParams, CodeMethodNotFound, CodeInvalidRequest, CodeParseError}
+	for i, want := range wantCodes {
+		rpcErr, ok := replies[i+1]["error"].(map[string]any)
+		if !ok || rpcErr["code"] != want {
+			t.Fatalf("reply %d: expected error code %v, got %v", i+1, want, replies[i+1])
+		}
+		if _, hasResult := replies[i+1]["result"]; hasResult {
+			t.Fatalf("reply %d: errors must not carry a result", i+1)
+		}
+	}
+}
+
+func TestServeAnswersBatches(t *testing.T) {
+	var out bytes.Buffer
+	input := `[{"jsonrpc":"2.0","id":1,"method":"ping"},{"jsonrpc":"2.0","method":"ping"},{"jsonrpc":"2.0","id":2,"method":"echo","params":{"text":"x"}}]`
+	if err := newTestServer().Serve(context.Background(), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("Serve returned error: %v", err)
+	}
+
+	var replies []map[string]any
+	if err := json.Unmarshal(out.Bytes(), &replies); err != nil {
+		t.Fatalf("expected a batch reply, got %q: %v", out.String(), err)
+	}
+
+	if len(replies) != 2 || replies[1]["result"] != "x" {
+		t.Fatalf("unexpected batch reply %v", replies)
+	}
+}
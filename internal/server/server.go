@@ -0,0 +1,245 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+// Package server exposes the todo lists over a local HTTP/JSON API.
+//
+// Every request other than the OpenAPI description must carry the configured
+// token as a bearer token. Responses describing a list carry an ETag, and
+// requests that change a list may send it back in If-Match so that they fail
+// with 412 Precondition Failed rather than overwrite a change made since.
+package server
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/unfunco/t/internal/automation"
+	"github.com/unfunco/t/internal/calendar"
+	"github.com/unfunco/t/internal/clock"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/oplog"
+	"github.com/unfunco/t/internal/storage"
+)
+
+//go:embed openapi.json
+var openAPI []byte
+
+// Store is the storage served by the API. The changes made by each request
+// are recorded as a single operation.
+type Store interface {
+	storage.Storage
+	Begin(oplog.Source)
+	Commit() error
+}
+
+// Config holds the dependencies of the API.
+type Config struct {
+	// Open opens the storage holding the todo lists. It is called for every
+	// request, so that each one is snapshotted and committed as a command
+	// would be.
+	Open func() (Store, error)
+	// Lists applies scheduled automations to the lists in a store, and merges
+	// any conflicting copies of them, before returning every list, as t does
+	// before running a command. Defaults to applying automations alone.
+	Lists func(Store) (map[list.ID]*model.TodoList, error)
+	// Calendar decides which day todos are scheduled for.
+	Calendar calendar.Calendar
+	// Clock provides the current time.
+	Clock clock.Clock
+	// Token is the bearer token that requests must present.
+	Token string
+}
+
+// Server handles API requests. Requests are served one at a time so that the
+// lists cannot change between checking a precondition and saving.
+type Server struct {
+	cfg Config
+	mux *http.ServeMux
+	mu  sync.Mutex
+}
+
+var _ http.Handler = (*Server)(nil)
+
+// New returns a server for the given configuration.
+func New(cfg Config) (*Server, error) {
+	if cfg.Open == nil {
+		return nil, errors.New("server requires a store")
+	}
+	if cfg.Token == "" {
+		return nil, errors.New("server requires a token")
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.System()
+	}
+	if cfg.Lists == nil {
+		cfg.Lists = func(store Store) (map[list.ID]*model.TodoList, error) {
+			return syncLists(store, cfg.Calendar, cfg.Clock)
+		}
+	}
+
+	s := &Server{cfg: cfg, mux: http.NewServeMux()}
+
+	s.mux.HandleFunc("GET /openapi.json", s.handleOpenAPI)
+	s.mux.Handle("GET /lists", s.authorised(s.handleLists))
+	s.mux.Handle("GET /lists/{list}", s.authorised(s.handleList))
+	s.mux.Handle("POST /lists/{list}/todos", s.authorised(s.handleCreateTodo))
+	s.mux.Handle("GET /lists/{list}/todos/{id}", s.authorised(s.handleTodo))
+	s.mux.Handle("PATCH /lists/{list}/todos/{id}", s.authorised(s.handleUpdateTodo))
+	s.mux.Handle("DELETE /lists/{list}/todos/{id}", s.authorised(s.handleDeleteTodo))
+
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handlerFunc is an API handler, given the storage opened for the request.
+// Returning an error writes it as the response.
+type handlerFunc func(w http.ResponseWriter, r *http.Request, store Store) error
+
+// authorised wraps h so that it only runs for requests carrying the token,
+// and one at a time.
+func (s *Server) authorised(h handlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="t"`)
+			writeError(w, &apiError{http.StatusUnauthorized, "a valid bearer token is required"})
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		store, err := s.cfg.Open()
+		if err != nil {
+			writeError(w, fmt.Errorf("failed to open storage: %w", err))
+			return
+		}
+
+		if err := h(w, r, store); err != nil {
+			writeError(w, err)
+		}
+	})
+}
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(openAPI)
+}
+
+// apiError is an error with the HTTP status it is reported with.
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func errorf(status int, format string, args ...any) error {
+	return &apiError{status, fmt.Sprintf(format, args...)}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		status = apiErr.status
+	}
+
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// listResponse is the representation of a list.
+type listResponse struct {
+	ID    list.ID      `json:"id"`
+	Name  string       `json:"name"`
+	ETag  string       `json:"etag"`
+	Todos []model.Todo `json:"todos"`
+}
+
+func newListResponse(def list.Definition, l *model.TodoList) listResponse {
+	todos := l.Todos
+	if todos == nil {
+		todos = []model.Todo{}
+	}
+
+	return listResponse{ID: def.ID, Name: def.Name, ETag: etag(l), Todos: todos}
+}
+
+// etag returns the entity tag of a list, which changes whenever its todos do.
+func etag(l *model.TodoList) string {
+	data, _ := json.Marshal(l.Todos)
+	sum := sha256.Sum256(data)
+
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// checkPrecondition fails unless the If-Match header of r, if any, names the
+// current entity tag of the list.
+func checkPrecondition(r *http.Request, l *model.TodoList) error {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return nil
+	}
+
+	current := etag(l)
+	for tag := range strings.SplitSeq(header, ",") {
+		if tag = strings.TrimSpace(tag); tag == "*" || tag == current {
+			return nil
+		}
+	}
+
+	return errorf(http.StatusPreconditionFailed, "the list has changed, its current ETag is %s", current)
+}
+
+// lists returns every list in store, once scheduled automations are applied.
+func (s *Server) lists(store Store) (map[list.ID]*model.TodoList, error) {
+	return s.cfg.Lists(store)
+}
+
+// syncLists applies scheduled automations and returns every list. Changes
+// made by automations are logged as an operation of their own.
+func syncLists(store Store, cal calendar.Calendar, clk clock.Clock) (map[list.ID]*model.TodoList, error) {
+	store.Begin(oplog.SourceAutomation)
+
+	lists, err := automation.Sync(store, cal, clk.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare lists: %w", err)
+	}
+
+	if err := store.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to record automations: %w", err)
+	}
+
+	return lists, nil
+}
+
+// lookupList returns the definition of the list named in the request path.
+func lookupList(r *http.Request) (list.Definition, error) {
+	def, ok := list.Lookup(list.ID(r.PathValue("list")))
+	if !ok {
+		return list.Definition{}, errorf(http.StatusNotFound, "no list with ID %q", r.PathValue("list"))
+	}
+
+	return def, nil
+}
@@ -0,0 +1,251 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unfunco/t/internal/calendar"
+	"github.com/unfunco/t/internal/clock"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/oplog"
+	"github.com/unfunco/t/internal/storage"
+)
+
+const token = "secret"
+
+func newTestServer(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+
+	dataDir := t.TempDir()
+	file, err := storage.NewFileStorageWithDir(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+
+	cal, err := calendar.New(0, time.UTC)
+	if err != nil {
+		t.Fatalf("calendar.New returned error: %v", err)
+	}
+
+	clk := clock.Fixed(time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC))
+
+	s, err := New(Config{
+		Open: func() (Store, error) {
+			return oplog.NewStorage(file, dataDir, clk), nil
+		},
+		Calendar: cal,
+		Clock:    clk,
+		Token:    token,
+	})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+
+	return ts, dataDir
+}
+
+func do(t *testing.T, ts *httptest.Server, method, path, body string, header map[string]string) *http.Response {
+	t.Helper()
+
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, ts.URL+path, r)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	return resp
+}
+
+func decodeBody[T any](t *testing.T, resp *http.Response) T {
+	t.Helper()
+
+	var v T
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	return v
+}
+
+func TestServerRequiresToken(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	resp := do(t, ts, http.MethodGet, "/lists", "", map[string]string{"Authorization": "Bearer wrong"})
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a wrong token, got %d", resp.StatusCode)
+	}
+
+	resp, err := ts.Client().Get(ts.URL + "/openapi.json")
+	if err != nil {
+		t.Fatalf("GET /openapi.json failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	doc := decodeBody[map[string]any](t, resp)
+	if resp.StatusCode != http.StatusOK || doc["openapi"] != "3.1.0" {
+		t.Fatalf("expected the OpenAPI description without a token, got %d %v", resp.StatusCode, doc["openapi"])
+	}
+}
+
+func TestServerCreatesUpdatesAndDeletesTodos(t *testing.T) {
+	ts, dataDir := newTestServer(t)
+
+	resp := do(t, ts, http.MethodPost, "/lists/today/todos", `{"title":"  Call Mum ","tags":["phone"]}`, nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+	created := decodeBody[model.Todo](t, resp)
+	if created.Title != "Call Mum" || created.DueDate == nil || !created.DueDate.Equal(time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected created todo %+v", created)
+	}
+	if loc := resp.Header.Get("Location"); loc != "/lists/today/todos/"+created.ID {
+		t.Fatalf("unexpected Location %q", loc)
+	}
+
+	resp = do(t, ts, http.MethodGet, "/lists/today", "", nil)
+	today := decodeBody[listResponse](t, resp)
+	if len(today.Todos) != 1 || today.ETag != resp.Header.Get("ETag") {
+		t.Fatalf("unexpected list %+v with ETag %q", today, resp.Header.Get("ETag"))
+	}
+
+	path := "/lists/today/todos/" + created.ID
+	resp = do(t, ts, http.MethodPatch, path, `{"completed":true}`, map[string]string{"If-Match": today.ETag})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if updated := decodeBody[model.Todo](t, resp); !updated.Completed || updated.CompletedAt == nil {
+		t.Fatalf("expected the todo to be completed, got %+v", updated)
+	}
+
+	resp = do(t, ts, http.MethodPatch, path, `{"title":"Stale"}`, map[string]string{"If-Match": today.ETag})
+	if resp.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412 for a stale ETag, got %d", resp.StatusCode)
+	}
+
+	resp = do(t, ts, http.MethodPatch, path, `{"list":"todos"}`, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 when moving, got %d", resp.StatusCode)
+	}
+	if moved := decodeBody[model.Todo](t, resp); moved.DueDate != nil {
+		t.Fatalf("expected the moved todo to take the due date of its new list, got %v", moved.DueDate)
+	}
+	if loc := resp.Header.Get("Content-Location"); loc != "/lists/todos/todos/"+created.ID {
+		t.Fatalf("unexpected Content-Location %q", loc)
+	}
+
+	events, err := oplog.Read(dataDir, storage.Plaintext)
+	if err != nil {
+		t.Fatalf("oplog.Read returned error: %v", err)
+	}
+	last := events[len(events)-1]
+	moved := slices.ContainsFunc(events, func(e oplog.Event) bool {
+		return e.Op == last.Op && e.Kind == oplog.KindMoved
+	})
+	if !moved || last.Source != oplog.SourceAPI {
+		t.Fatalf("expected the move to be logged from the API, got %+v", events)
+	}
+
+	if resp := do(t, ts, http.MethodDelete, path, "", nil); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for the old path, got %d", resp.StatusCode)
+	}
+
+	if resp := do(t, ts, http.MethodDelete, "/lists/todos/todos/"+created.ID, "", nil); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", resp.StatusCode)
+	}
+
+	store, err := storage.NewFileStorageWithDir(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	todos, err := store.LoadList(list.Todos())
+	if err != nil {
+		t.Fatalf("LoadList returned error: %v", err)
+	}
+	if len(todos.Todos) != 0 {
+		t.Fatalf("expected the todo to be deleted, got %+v", todos.Todos)
+	}
+}
+
+func TestServerRejectsInvalidRequests(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	cases := []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodGet, "/lists/someday", "", http.StatusNotFound},
+		{http.MethodPost, "/lists/today/todos", `{"title":" "}`, http.StatusUnprocessableEntity},
+		{http.MethodPost, "/lists/today/todos", `{"title":"` + strings.Repeat("a", model.TitleCharLimit+1) + `"}`, http.StatusUnprocessableEntity},
+		{http.MethodPost, "/lists/today/todos", `{"title":"Go","due_date":"soon"}`, http.StatusUnprocessableEntity},
+		{http.MethodPost, "/lists/today/todos", `{"title":"Go","priority":1}`, http.StatusBadRequest},
+		{http.MethodPatch, "/lists/today/todos/missing", `{}`, http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		resp := do(t, ts, tc.method, tc.path, tc.body, nil)
+		if resp.StatusCode != tc.want {
+			t.Fatalf("%s %s %s: expected %d, got %d", tc.method, tc.path, tc.body, tc.want, resp.StatusCode)
+		}
+		if body := decodeBody[map[string]string](t, resp); body["error"] == "" {
+			t.Fatalf("%s %s: expected an error message", tc.method, tc.path)
+		}
+	}
+}
+
+func TestServerOpensStorageForEachRequest(t *testing.T) {
+	dataDir := t.TempDir()
+	file, err := storage.NewFileStorageWithDir(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+
+	opened := 0
+	s, err := New(Config{
+		Open: func() (Store, error) {
+			opened++
+			return oplog.NewStorage(file, dataDir, clock.System()), nil
+		},
+		Token: token,
+	})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+
+	do(t, ts, http.MethodPost, "/lists/todos/todos", `{"title":"Call Mum"}`, nil)
+	do(t, ts, http.MethodPost, "/lists/todos/todos", `{"title":"Buy milk"}`, nil)
+
+	if opened != 2 {
+		t.Fatalf("expected the storage to be opened for each request, got %d", opened)
+	}
+}
@@ -0,0 +1,323 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/oplog"
+)
+
+// maxBodyBytes limits the size of request bodies.
+const maxBodyBytes = 1 << 20
+
+func (s *Server) handleLists(w http.ResponseWriter, _ *http.Request, store Store) error {
+	lists, err := s.lists(store)
+	if err != nil {
+		return err
+	}
+
+	resp := struct {
+		Lists []listResponse `json:"lists"`
+	}{}
+	for _, def := range list.Default() {
+		resp.Lists = append(resp.Lists, newListResponse(def, lists[def.ID]))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+	return nil
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request, store Store) error {
+	def, err := lookupList(r)
+	if err != nil {
+		return err
+	}
+
+	lists, err := s.lists(store)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("ETag", etag(lists[def.ID]))
+	writeJSON(w, http.StatusOK, newListResponse(def, lists[def.ID]))
+	return nil
+}
+
+func (s *Server) handleTodo(w http.ResponseWriter, r *http.Request, store Store) error {
+	lists, def, i, err := s.findTodo(r, store)
+	if err != nil {
+		return err
+	}
+
+	l := lists[def.ID]
+	w.Header().Set("ETag", etag(l))
+	w.Header().Set("Content-Location", todoPath(def.ID, l.Todos[i].ID))
+	writeJSON(w, http.StatusOK, l.Todos[i])
+	return nil
+}
+
+// createRequest is the body of a request to create a todo.
+type createRequest struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	DueDate     *string  `json:"due_date"`
+	Project     string   `json:"project"`
+	Tags        []string `json:"tags"`
+}
+
+func (s *Server) handleCreateTodo(w http.ResponseWriter, r *http.Request, store Store) error {
+	def, err := lookupList(r)
+	if err != nil {
+		return err
+	}
+
+	var req createRequest
+	if err := decode(w, r, &req); err != nil {
+		return err
+	}
+
+	title, err := validateTitle(req.Title)
+	if err != nil {
+		return err
+	}
+
+	now := s.cfg.Clock.Now()
+	due := list.DefaultDueDate(s.cfg.Calendar, def.ID, now)
+	if req.DueDate != nil {
+		if due, err = s.parseDueDate(*req.DueDate); err != nil {
+			return err
+		}
+	}
+
+	lists, err := s.lists(store)
+	if err != nil {
+		return err
+	}
+
+	l := lists[def.ID]
+	if err := checkPrecondition(r, l); err != nil {
+		return err
+	}
+
+	todo := model.NewTodo(title, strings.TrimSpace(req.Description), due, now)
+	todo.Project = strings.TrimSpace(req.Project)
+	todo.Tags = req.Tags
+	l.Todos = append(l.Todos, todo)
+
+	if err := s.save(store, lists, def); err != nil {
+		return err
+	}
+
+	w.Header().Set("ETag", etag(l))
+	w.Header().Set("Location", todoPath(def.ID, todo.ID))
+	writeJSON(w, http.StatusCreated, todo)
+	return nil
+}
+
+// updateRequest is the body of a request to change a todo. Fields that are
+// left out are not changed.
+type updateRequest struct {
+	Title       *string   `json:"title"`
+	Description *string   `json:"description"`
+	Completed   *bool     `json:"completed"`
+	DueDate     *string   `json:"due_date"`
+	Project     *string   `json:"project"`
+	Tags        *[]string `json:"tags"`
+	List        *list.ID  `json:"list"`
+}
+
+func (s *Server) handleUpdateTodo(w http.ResponseWriter, r *http.Request, store Store) error {
+	var req updateRequest
+	if err := decode(w, r, &req); err != nil {
+		return err
+	}
+
+	lists, def, i, err := s.findTodo(r, store)
+	if err != nil {
+		return err
+	}
+
+	l := lists[def.ID]
+	if err := checkPrecondition(r, l); err != nil {
+		return err
+	}
+
+	todo := l.Todos[i]
+	now := s.cfg.Clock.Now()
+
+	if req.Title != nil {
+		title, err := validateTitle(*req.Title)
+		if err != nil {
+			return err
+		}
+		todo.Title = title
+	}
+	if req.Description != nil {
+		todo.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Completed != nil && *req.Completed != todo.Completed {
+		todo.ToggleCompleted(now)
+	}
+	if req.DueDate != nil {
+		due, err := s.parseDueDate(*req.DueDate)
+		if err != nil {
+			return err
+		}
+		todo.SetDueDate(due)
+	}
+	if req.Project != nil {
+		todo.Project = strings.TrimSpace(*req.Project)
+	}
+	if req.Tags != nil {
+		todo.Tags = *req.Tags
+	}
+
+	target, targetList := def, l
+	if req.List != nil && *req.List != def.ID {
+		var ok bool
+		if target, ok = list.Lookup(*req.List); !ok {
+			return errorf(http.StatusBadRequest, "no list with ID %q", *req.List)
+		}
+		targetList = lists[target.ID]
+
+		// Todos moved without a due date take the one implied by their new list.
+		if req.DueDate == nil {
+			todo.SetDueDate(list.DefaultDueDate(s.cfg.Calendar, target.ID, now))
+		}
+	}
+
+	changed := []list.Definition{def}
+	if target.ID == def.ID {
+		l.Todos[i] = todo
+	} else {
+		l.Todos = slices.Delete(l.Todos, i, i+1)
+		targetList.Todos = append(targetList.Todos, todo)
+		changed = append(changed, target)
+	}
+
+	if err := s.save(store, lists, changed...); err != nil {
+		return err
+	}
+
+	w.Header().Set("ETag", etag(targetList))
+	w.Header().Set("Content-Location", todoPath(target.ID, todo.ID))
+	writeJSON(w, http.StatusOK, todo)
+	return nil
+}
+
+func (s *Server) handleDeleteTodo(w http.ResponseWriter, r *http.Request, store Store) error {
+	lists, def, i, err := s.findTodo(r, store)
+	if err != nil {
+		return err
+	}
+
+	l := lists[def.ID]
+	if err := checkPrecondition(r, l); err != nil {
+		return err
+	}
+
+	l.Todos = slices.Delete(l.Todos, i, i+1)
+	if err := s.save(store, lists, def); err != nil {
+		return err
+	}
+
+	w.Header().Set("ETag", etag(l))
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// findTodo returns every list along with the definition of the list named
+// in the request path and the index of the todo named in it.
+func (s *Server) findTodo(r *http.Request, store Store) (map[list.ID]*model.TodoList, list.Definition, int, error) {
+	def, err := lookupList(r)
+	if err != nil {
+		return nil, list.Definition{}, 0, err
+	}
+
+	lists, err := s.lists(store)
+	if err != nil {
+		return nil, list.Definition{}, 0, err
+	}
+
+	id := r.PathValue("id")
+	i := slices.IndexFunc(lists[def.ID].Todos, func(todo model.Todo) bool { return todo.ID == id })
+	if i < 0 {
+		return nil, list.Definition{}, 0, errorf(http.StatusNotFound, "no todo with ID %q in the %s list", id, def.Name)
+	}
+
+	return lists, def, i, nil
+}
+
+// save saves the given lists as a single operation.
+func (s *Server) save(store Store, lists map[list.ID]*model.TodoList, defs ...list.Definition) error {
+	store.Begin(oplog.SourceAPI)
+
+	for _, def := range defs {
+		if err := store.SaveList(def, lists[def.ID]); err != nil {
+			return fmt.Errorf("failed to save %s list: %w", def.Name, err)
+		}
+	}
+
+	err := store.Commit()
+	if errors.Is(err, oplog.ErrCancelled) {
+		return errorf(http.StatusConflict, "%v", err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record changes: %w", err)
+	}
+
+	return nil
+}
+
+// decode reads the JSON body of r into v, rejecting unknown fields.
+func decode(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		return errorf(http.StatusBadRequest, "invalid request body: %v", err)
+	}
+
+	return nil
+}
+
+// validateTitle trims the space around title and checks it against the rules
+// for todo titles, which the API reports as 422 Unprocessable Entity.
+func validateTitle(title string) (string, error) {
+	title = strings.TrimSpace(title)
+
+	if err := model.ValidateTitle(title); err != nil {
+		return "", errorf(http.StatusUnprocessableEntity, "%v", err)
+	}
+
+	return title, nil
+}
+
+// parseDueDate parses a due date written as YYYY-MM-DD. An empty value
+// removes the due date.
+func (s *Server) parseDueDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	due, err := time.ParseInLocation(time.DateOnly, value, s.cfg.Calendar.Location())
+	if err != nil {
+		return nil, errorf(http.StatusUnprocessableEntity, "invalid due date %q, expected YYYY-MM-DD", value)
+	}
+
+	return &due, nil
+}
+
+func todoPath(listID list.ID, todoID string) string {
+	return fmt.Sprintf("/lists/%s/todos/%s", listID, todoID)
+}
@@ -0,0 +1,27 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package storage
+
+// Cipher encrypts the contents of the files in the data directory.
+type Cipher interface {
+	// Encrypt returns the encrypted form of plaintext.
+	Encrypt(plaintext []byte) ([]byte, error)
+	// Decrypt returns the plaintext of data. Data that is not encrypted is
+	// returned unchanged, so that files written before encryption was turned
+	// on can still be read.
+	Decrypt(data []byte) ([]byte, error)
+}
+
+// Plaintext is the Cipher used when encryption is off. It stores data as is.
+var Plaintext Cipher = plaintext{}
+
+type plaintext struct{}
+
+func (plaintext) Encrypt(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+func (plaintext) Decrypt(data []byte) ([]byte, error) {
+	return data, nil
+}
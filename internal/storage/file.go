@@ -4,7 +4,8 @@
 package storage
 
 import (
-	"encoding/json"
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -17,6 +18,7 @@ import (
 // File persists todos on disk.
 type File struct {
 	dataDir string
+	cipher  Cipher
 }
 
 var _ Storage = (*File)(nil)
@@ -38,7 +40,19 @@ func NewFileStorageWithDir(dataDir string) (*File, error) {
 		return nil, fmt.Errorf("data directory cannot be empty")
 	}
 
-	return &File{dataDir}, nil
+	return &File{dataDir: dataDir, cipher: Plaintext}, nil
+}
+
+// NewFileStorageWithCipher creates a File rooted at the provided directory
+// that encrypts the files it writes with c.
+func NewFileStorageWithCipher(dataDir string, c Cipher) (*File, error) {
+	s, err := NewFileStorageWithDir(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	s.cipher = c
+
+	return s, nil
 }
 
 // ensureDataDir creates the data directory if it doesn't exist.
@@ -54,7 +68,9 @@ func (s *File) ensureDataDir() error {
 	return nil
 }
 
-// LoadList loads the todo list represented by the provided definition.
+// LoadList loads the todo list represented by the provided definition. Files
+// written with an older schema are upgraded in memory, and only rewritten when
+// the list is next saved.
 func (s *File) LoadList(def list.Definition) (*model.TodoList, error) {
 	filePath := filepath.Join(s.dataDir, def.Filename)
 
@@ -65,40 +81,108 @@ func (s *File) LoadList(def list.Definition) (*model.TodoList, error) {
 		}, nil
 	}
 
-	data, err := os.ReadFile(filePath)
+	data, err := s.readFile(def.Filename)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read %s: %w", def.Filename, err)
+		return nil, err
 	}
 
-	var todos []model.Todo
-	if len(data) > 0 {
-		if err := json.Unmarshal(data, &todos); err != nil {
-			return nil, fmt.Errorf("failed to parse %s: %w", def.Filename, err)
-		}
+	if len(data) == 0 {
+		return &model.TodoList{
+			Name:  def.Name,
+			Todos: []model.Todo{},
+		}, nil
+	}
+
+	todoList, err := decodeList(def, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", def.Filename, err)
 	}
 
-	return &model.TodoList{
-		Name:  def.Name,
-		Todos: todos,
-	}, nil
+	return todoList, nil
 }
 
-// SaveList saves the provided todo list using the supplied definition.
+// SaveList saves the provided todo list using the supplied definition. It
+// refuses to overwrite a file written with a newer schema than this binary
+// understands, and keeps a backup of one written with an older schema
+// alongside it before upgrading it.
 func (s *File) SaveList(def list.Definition, list *model.TodoList) error {
-	if err := s.ensureDataDir(); err != nil {
+	if err := s.checkSchema(def); err != nil {
 		return err
 	}
 
-	filePath := filepath.Join(s.dataDir, def.Filename)
+	data, err := EncodeList(def, list)
+	if err != nil {
+		return err
+	}
+
+	return s.writeFile(def.Filename, data)
+}
+
+// checkSchema returns ErrSchemaTooNew if the existing file for the definition
+// was written with a newer schema version, and backs up one written with an
+// older schema version as name.vN.bak before it is replaced.
+func (s *File) checkSchema(def list.Definition) error {
+	data, err := s.readFile(def.Filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
 
-	data, err := json.MarshalIndent(list.Todos, "", "  ")
+	version, err := schemaVersion(data)
 	if err != nil {
-		return fmt.Errorf("failed to marshal todos: %w", err)
+		// Unreadable files are replaced rather than preserved.
+		return nil
 	}
 
-	tmpFile, err := os.CreateTemp(s.dataDir, def.Filename+".tmp-*")
+	if version > SchemaVersion {
+		return fmt.Errorf("%s uses schema version %d, newest supported is %d: %w",
+			def.Filename, version, SchemaVersion, ErrSchemaTooNew)
+	}
+
+	if version < SchemaVersion && len(bytes.TrimSpace(data)) > 0 {
+		backupName := fmt.Sprintf("%s.v%d.bak", def.Filename, version)
+		if err := s.writeFile(backupName, data); err != nil {
+			return fmt.Errorf("failed to back up %s before upgrading: %w", def.Filename, err)
+		}
+	}
+
+	return nil
+}
+
+// readFile reads and decrypts the named file in the data directory.
+func (s *File) readFile(name string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(s.dataDir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	data, err = s.cipher.Decrypt(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", name, err)
+	}
+
+	return data, nil
+}
+
+// writeFile encrypts data and atomically replaces the named file in the data
+// directory with it.
+func (s *File) writeFile(name string, data []byte) error {
+	if err := s.ensureDataDir(); err != nil {
+		return err
+	}
+
+	data, err := s.cipher.Encrypt(data)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt %s: %w", name, err)
+	}
+
+	filePath := filepath.Join(s.dataDir, name)
+
+	tmpFile, err := os.CreateTemp(s.dataDir, name+".tmp-*")
 	if err != nil {
-		return fmt.Errorf("failed to create temp file for %s: %w", def.Filename, err)
+		return fmt.Errorf("failed to create temp file for %s: %w", name, err)
 	}
 
 	tmpPath := tmpFile.Name()
@@ -135,7 +219,7 @@ func (s *File) SaveList(def list.Definition, list *model.TodoList) error {
 	tmpFile = nil
 
 	if err := os.Rename(tmpPath, filePath); err != nil {
-		writeErr = fmt.Errorf("failed to replace %s: %w", def.Filename, err)
+		writeErr = fmt.Errorf("failed to replace %s: %w", name, err)
 		return writeErr
 	}
 
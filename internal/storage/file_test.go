@@ -4,6 +4,8 @@
 package storage
 
 import (
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -75,3 +77,141 @@ func TestFileSaveListPrivateAtomic(t *testing.T) {
 		t.Fatalf("temporary files leaked: %v", tmpFiles)
 	}
 }
+
+func TestFileLoadListLeavesFileUnchanged(t *testing.T) {
+	dataDir := t.TempDir()
+
+	content := []byte(`{"schema_version": 2, "list": {"id": "today"}, "todos": [
+  {"id": "20250102090000.000000", "title": "first"},
+  {"id": "20250102090000.000000", "title": "second"}
+]}`)
+
+	def := list.Today()
+	path := filepath.Join(dataDir, def.Filename)
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("failed to write list file: %v", err)
+	}
+
+	store, err := NewFileStorageWithDir(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create file storage: %v", err)
+	}
+
+	loaded, err := store.LoadList(def)
+	if err != nil {
+		t.Fatalf("LoadList() returned error: %v", err)
+	}
+
+	if len(loaded.Todos) != 2 || loaded.Todos[1].ID != "20250102090000.000000" {
+		t.Fatalf("expected the todos as stored, got %+v", loaded.Todos)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read list file: %v", err)
+	}
+	if string(data) != string(content) {
+		t.Fatalf("expected loading to leave the file unchanged, got %s", data)
+	}
+}
+
+func TestFileMigratesLegacyFilesOnSave(t *testing.T) {
+	dataDir := t.TempDir()
+	legacy := []byte(`[{"id": "1", "title": "legacy"}]`)
+
+	def := list.Today()
+	if err := os.WriteFile(filepath.Join(dataDir, def.Filename), legacy, 0o600); err != nil {
+		t.Fatalf("failed to write list file: %v", err)
+	}
+
+	store, err := NewFileStorageWithDir(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create file storage: %v", err)
+	}
+
+	loaded, err := store.LoadList(def)
+	if err != nil {
+		t.Fatalf("LoadList() returned error: %v", err)
+	}
+
+	if len(loaded.Todos) != 1 || loaded.Todos[0].Title != "legacy" {
+		t.Fatalf("unexpected todos after migration: %+v", loaded.Todos)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dataDir, def.Filename)); err != nil || string(data) != string(legacy) {
+		t.Fatalf("expected loading to leave the legacy file as it was, got %s, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, def.Filename+".v1.bak")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected no backup before the list is saved, got %v", err)
+	}
+
+	if err := store.SaveList(def, loaded); err != nil {
+		t.Fatalf("SaveList() returned error: %v", err)
+	}
+
+	backup, err := os.ReadFile(filepath.Join(dataDir, def.Filename+".v1.bak"))
+	if err != nil {
+		t.Fatalf("expected backup of legacy file: %v", err)
+	}
+
+	if string(backup) != string(legacy) {
+		t.Fatalf("backup does not match original, got %s", backup)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dataDir, def.Filename))
+	if err != nil {
+		t.Fatalf("failed to read upgraded file: %v", err)
+	}
+
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("upgraded file is not a document: %v", err)
+	}
+
+	if doc.SchemaVersion != SchemaVersion {
+		t.Fatalf("expected schema version %d, got %d", SchemaVersion, doc.SchemaVersion)
+	}
+
+	if doc.List.ID != def.ID || doc.List.Name != def.Name || doc.List.Order != 0 {
+		t.Fatalf("unexpected list metadata: %+v", doc.List)
+	}
+}
+
+func TestFileSaveListRefusesNewerSchema(t *testing.T) {
+	dataDir := t.TempDir()
+	future := []byte(`{"schema_version": 99, "list": {"id": "today"}, "todos": [{"id": "1", "title": "from the future"}]}`)
+
+	def := list.Today()
+	path := filepath.Join(dataDir, def.Filename)
+	if err := os.WriteFile(path, future, 0o600); err != nil {
+		t.Fatalf("failed to write list file: %v", err)
+	}
+
+	store, err := NewFileStorageWithDir(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create file storage: %v", err)
+	}
+
+	loaded, err := store.LoadList(def)
+	if err != nil {
+		t.Fatalf("LoadList() returned error: %v", err)
+	}
+
+	if len(loaded.Todos) != 1 {
+		t.Fatalf("expected newer file to be readable, got %+v", loaded.Todos)
+	}
+
+	err = store.SaveList(def, loaded)
+	if !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("expected ErrSchemaTooNew, got %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read list file: %v", err)
+	}
+
+	if string(data) != string(future) {
+		t.Fatalf("newer file was modified: %s", data)
+	}
+}
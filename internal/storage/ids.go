@@ -0,0 +1,43 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package storage
+
+import "github.com/unfunco/t/internal/model"
+
+// EnsureUniqueIDs assigns fresh IDs to todos whose ID is empty or already used
+// by an earlier todo in the provided lists. The first occurrence of an ID keeps
+// it, so references to existing todos remain valid. It reports whether any ID
+// was changed.
+func EnsureUniqueIDs(lists ...*model.TodoList) bool {
+	seen := make(map[string]struct{})
+	changed := false
+
+	for _, l := range lists {
+		if l == nil {
+			continue
+		}
+
+		for i := range l.Todos {
+			todo := &l.Todos[i]
+
+			if _, dup := seen[todo.ID]; dup || todo.ID == "" {
+				todo.ID = newUniqueID(todo, seen)
+				changed = true
+			}
+
+			seen[todo.ID] = struct{}{}
+		}
+	}
+
+	return changed
+}
+
+func newUniqueID(todo *model.Todo, seen map[string]struct{}) string {
+	for {
+		id := model.NewID(todo.CreatedAt)
+		if _, dup := seen[id]; !dup {
+			return id
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+)
+
+// SchemaVersion is the newest list file schema understood by this binary.
+const SchemaVersion = 2
+
+// ErrSchemaTooNew is returned when refusing to overwrite a list file written
+// by a newer version of t.
+var ErrSchemaTooNew = errors.New("list file was written by a newer version of t")
+
+// document is the on-disk representation of a list file.
+type document struct {
+	SchemaVersion int          `json:"schema_version"`
+	List          listMetadata `json:"list"`
+	Todos         []model.Todo `json:"todos"`
+}
+
+// listMetadata describes the list stored in a document.
+type listMetadata struct {
+	ID    list.ID `json:"id"`
+	Name  string  `json:"name"`
+	Order int     `json:"order"`
+}
+
+// migration upgrades a raw list file from one schema version to the next.
+type migration struct {
+	from    int
+	migrate func(data []byte, def list.Definition) ([]byte, error)
+}
+
+// migrations lists every upgrade step in order. Each step upgrades a file
+// from version `from` to `from+1`.
+var migrations = []migration{
+	{from: 1, migrate: migrateBareArray},
+}
+
+// newDocument wraps the todos for the provided definition in a document using
+// the current schema version.
+func newDocument(def list.Definition, todos []model.Todo) document {
+	if todos == nil {
+		todos = []model.Todo{}
+	}
+
+	return document{
+		SchemaVersion: SchemaVersion,
+		List: listMetadata{
+			ID:    def.ID,
+			Name:  def.Name,
+			Order: listOrder(def),
+		},
+		Todos: todos,
+	}
+}
+
+// DecodeList parses the contents of a list file written with any supported
+// schema version.
+func DecodeList(def list.Definition, data []byte) (*model.TodoList, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return &model.TodoList{Name: def.Name, Todos: []model.Todo{}}, nil
+	}
+
+	return decodeList(def, data)
+}
+
+// EncodeList returns the contents of the list file for the todo list, using
+// the current schema version.
+func EncodeList(def list.Definition, todoList *model.TodoList) ([]byte, error) {
+	data, err := json.MarshalIndent(newDocument(def, todoList.Todos), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal todos: %w", err)
+	}
+
+	return data, nil
+}
+
+// decodeList parses the contents of a list file, upgrading them in memory if
+// they were written with an older schema version.
+func decodeList(def list.Definition, data []byte) (*model.TodoList, error) {
+	migrated, err := migrate(data, def)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc document
+	if err := json.Unmarshal(migrated, &doc); err != nil {
+		return nil, err
+	}
+
+	if doc.Todos == nil {
+		doc.Todos = []model.Todo{}
+	}
+
+	return &model.TodoList{Name: def.Name, Todos: doc.Todos}, nil
+}
+
+// schemaVersion reports the schema version of raw list file contents. Files
+// written before versioning was introduced hold a bare JSON array and are
+// treated as version 1.
+func schemaVersion(data []byte) (int, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] == '[' {
+		return 1, nil
+	}
+
+	var header struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(trimmed, &header); err != nil {
+		return 0, err
+	}
+
+	if header.SchemaVersion < 1 {
+		return 0, fmt.Errorf("missing schema_version")
+	}
+
+	return header.SchemaVersion, nil
+}
+
+// migrate upgrades raw list file contents to the current schema version.
+func migrate(data []byte, def list.Definition) ([]byte, error) {
+	version, err := schemaVersion(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range migrations {
+		if m.from != version {
+			continue
+		}
+
+		data, err = m.migrate(data, def)
+		if err != nil {
+			return nil, fmt.Errorf("migrate from schema version %d: %w", m.from, err)
+		}
+
+		version++
+	}
+
+	return data, nil
+}
+
+// migrateBareArray wraps a version 1 bare array of todos in a document.
+func migrateBareArray(data []byte, def list.Definition) ([]byte, error) {
+	var todos []model.Todo
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &todos); err != nil {
+			return nil, err
+		}
+	}
+
+	doc := newDocument(def, todos)
+	doc.SchemaVersion = 2
+
+	return json.Marshal(doc)
+}
+
+// listOrder returns the position of the list in the default UI order. Lists
+// that are not part of the defaults sort after them.
+func listOrder(def list.Definition) int {
+	defaults := list.Default()
+	for i, d := range defaults {
+		if d.ID == def.ID {
+			return i
+		}
+	}
+
+	return len(defaults)
+}
@@ -0,0 +1,177 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/merge"
+	"github.com/unfunco/t/internal/model"
+)
+
+// noticeDuration is how long a notice stays in the status line.
+const noticeDuration = 3 * time.Second
+
+// ListsChangedMsg reports that the lists were saved outside the TUI, such as
+// by a script or another terminal.
+type ListsChangedMsg struct {
+	// Lists holds the lists as they are now stored.
+	Lists map[list.ID]*model.TodoList
+	// Err is set when the lists changed but could not be loaded.
+	Err error
+}
+
+// clearNoticeMsg removes the notice with the given ID from the status line,
+// unless another has replaced it.
+type clearNoticeMsg struct {
+	id int
+}
+
+// WithWatch sets the command that waits for the lists to be saved outside the
+// TUI and returns a ListsChangedMsg. It runs again after every change, which
+// is merged into the lists shown, keeping the changes made in the TUI.
+func WithWatch(wait tea.Cmd) Option {
+	return func(m *Model) {
+		m.watch = wait
+	}
+}
+
+// listsChanged merges lists saved elsewhere and waits for the next change.
+// Changes arriving while a form is open are held until it closes, so that
+// the todo being edited stays where the form expects it.
+func (m *Model) listsChanged(msg ListsChangedMsg) tea.Cmd {
+	if msg.Err != nil {
+		return tea.Batch(m.watch, m.flash("Failed to load changes saved elsewhere: "+msg.Err.Error(), true))
+	}
+
+	if m.formMode != FormModeNone {
+		m.pending = msg.Lists
+		return m.watch
+	}
+
+	if !m.mergeStored(msg.Lists) {
+		return m.watch
+	}
+
+	return tea.Batch(m.watch, m.flash("Merged changes saved elsewhere", false))
+}
+
+// applyPending merges the changes held while a form was open, and applies
+// the automations for a new day that began while it was.
+func (m *Model) applyPending() tea.Cmd {
+	if m.formMode != FormModeNone {
+		return nil
+	}
+
+	stored := m.pending
+	m.pending = nil
+	merged := stored != nil && m.mergeStored(stored)
+
+	if m.rolloverDue {
+		m.applyAutomations()
+	}
+
+	if !merged {
+		return nil
+	}
+
+	return m.flash("Merged changes saved elsewhere", false)
+}
+
+// mergeStored merges the lists as now stored into those shown, keeping the
+// changes made since they were last loaded, and reports whether anything was
+// saved elsewhere.
+func (m *Model) mergeStored(stored merge.Lists) bool {
+	if sameLists(stored, m.stored) {
+		return false
+	}
+
+	ours := merge.Lists{
+		list.TodayID:    m.todayList,
+		list.TomorrowID: m.tomorrowList,
+		list.TodosID:    m.todoList,
+	}
+	merged := merge.ThreeWay(m.stored, ours, stored)
+
+	m.todayList = merged[list.TodayID]
+	m.tomorrowList = merged[list.TomorrowID]
+	m.todoList = merged[list.TodosID]
+	m.stored = cloneLists(stored)
+	m.clampCursor()
+
+	return true
+}
+
+// flash shows a notice in the status line for a few seconds.
+func (m *Model) flash(notice string, worry bool) tea.Cmd {
+	m.noticeID++
+	m.notice = notice
+	m.noticeWorry = worry
+
+	id := m.noticeID
+	return tea.Tick(noticeDuration, func(time.Time) tea.Msg {
+		return clearNoticeMsg{id: id}
+	})
+}
+
+// renderNotice renders the notice in the status line, if any.
+func (m *Model) renderNotice() string {
+	if m.notice == "" {
+		return ""
+	}
+
+	if m.noticeWorry {
+		return m.theme.WorryStyle().Render(m.notice)
+	}
+
+	return m.theme.SuccessStyle().Render(m.notice)
+}
+
+// cloneLists copies the lists so that later changes to the todos shown do not
+// change the copy.
+func cloneLists(lists merge.Lists) merge.Lists {
+	cloned := make(merge.Lists, len(lists))
+	for id, l := range lists {
+		if l == nil {
+			continue
+		}
+		cloned[id] = &model.TodoList{Name: l.Name, Todos: slices.Clone(l.Todos)}
+	}
+
+	return cloned
+}
+
+// sameLists reports whether two copies of the lists hold the same todos.
+func sameLists(a, b merge.Lists) bool {
+	for _, def := range list.Default() {
+		if !sameTodos(a[def.ID], b[def.ID]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sameTodos(a, b *model.TodoList) bool {
+	var todosA, todosB []model.Todo
+	if a != nil {
+		todosA = a.Todos
+	}
+	if b != nil {
+		todosB = b.Todos
+	}
+	if len(todosA) != len(todosB) {
+		return false
+	}
+
+	dataA, errA := json.Marshal(todosA)
+	dataB, errB := json.Marshal(todosB)
+
+	return errA == nil && errB == nil && bytes.Equal(dataA, dataB)
+}
@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/unfunco/t/internal/automation"
+	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/model"
+)
+
+// maxRolloverWait caps how long to wait for the next day, as timers do not
+// count time spent asleep, so that a computer waking after the day changed
+// catches up soon after.
+const maxRolloverWait = 15 * time.Minute
+
+// rolloverMsg is sent when the next calendar day may have begun.
+type rolloverMsg struct{}
+
+// RolloverMsg reports the lists as stored once the automations run when a new
+// calendar day begins have been applied to them and saved.
+type RolloverMsg struct {
+	// Lists holds the lists as they are now stored.
+	Lists map[list.ID]*model.TodoList
+	// Err is set when the automations could not be applied or saved.
+	Err error
+}
+
+// WithRollover sets the command that applies the automations to the stored
+// lists when a new calendar day begins, saving them as a change of their own,
+// and returns a RolloverMsg. The result is merged into the lists shown, and
+// the automations are then applied to the todos not saved yet. Without it,
+// the automations are applied to the lists shown alone, and saved with them.
+func WithRollover(save tea.Cmd) Option {
+	return func(m *Model) {
+		m.rolloverSave = save
+	}
+}
+
+// scheduleRollover waits for the next calendar day to begin.
+func (m *Model) scheduleRollover() tea.Cmd {
+	now := m.clock.Now()
+	wait := min(m.calendar.NextDayStart(now).Sub(now), maxRolloverWait)
+
+	return tea.Tick(max(wait, time.Second), func(time.Time) tea.Msg {
+		return rolloverMsg{}
+	})
+}
+
+// rollover applies the automations run at startup once a new calendar day has
+// begun, such as moving todos due today from the Tomorrow tab, so that a TUI
+// left open overnight shows what a fresh launch would.
+func (m *Model) rollover() tea.Cmd {
+	// Moving todos would change the list the form is editing, so check
+	// again once it has had time to close.
+	if m.formMode != FormModeNone {
+		return tea.Tick(time.Minute, func(time.Time) tea.Msg {
+			return rolloverMsg{}
+		})
+	}
+
+	today := m.calendar.Today(m.clock.Now())
+	if today.Equal(m.today) {
+		return m.scheduleRollover()
+	}
+
+	m.today = today
+	if m.rolloverSave != nil {
+		return tea.Batch(m.rolloverSave, m.scheduleRollover())
+	}
+
+	m.applyAutomations()
+
+	return m.scheduleRollover()
+}
+
+// rolledOver merges the lists saved by the automations for the new day, then
+// applies them to the todos not saved yet. Both wait for an open form to
+// close.
+func (m *Model) rolledOver(msg RolloverMsg) tea.Cmd {
+	var cmd tea.Cmd
+	switch {
+	case msg.Err != nil:
+		cmd = m.flash("Failed to save the automations for the new day: "+msg.Err.Error(), true)
+	case m.formMode != FormModeNone:
+		m.pending = msg.Lists
+	default:
+		m.mergeStored(msg.Lists)
+	}
+
+	if m.formMode != FormModeNone {
+		m.rolloverDue = true
+		return cmd
+	}
+
+	m.applyAutomations()
+
+	return cmd
+}
+
+// applyAutomations applies the automations run at startup to the lists shown.
+func (m *Model) applyAutomations() {
+	m.rolloverDue = false
+	automation.Apply(map[list.ID]*model.TodoList{
+		list.TodayID:    m.todayList,
+		list.TomorrowID: m.tomorrowList,
+		list.TodosID:    m.todoList,
+	}, m.calendar, m.clock.Now())
+	m.clampCursor()
+}
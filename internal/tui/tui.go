@@ -13,8 +13,12 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/unfunco/t/internal/calendar"
+	"github.com/unfunco/t/internal/clock"
 	"github.com/unfunco/t/internal/list"
+	"github.com/unfunco/t/internal/merge"
 	"github.com/unfunco/t/internal/model"
+	"github.com/unfunco/t/internal/query"
 	"github.com/unfunco/t/internal/theme"
 )
 
@@ -75,6 +79,7 @@ type KeyMap struct {
 	Submit   key.Binding
 	Add      key.Binding
 	Edit     key.Binding
+	Filter   key.Binding
 }
 
 // DefaultKeyMap returns the default key bindings.
@@ -128,6 +133,10 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("e"),
 			key.WithHelp("e", "edit todo"),
 		),
+		Filter: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "filter todos"),
+		),
 	}
 }
 
@@ -144,6 +153,10 @@ type Model struct {
 	submitted    bool
 	exited       bool
 	theme        theme.Theme
+	calendar     calendar.Calendar
+	clock        clock.Clock
+	store        string
+	today        time.Time
 
 	// Form state
 	formMode         FormMode
@@ -152,13 +165,55 @@ type Model struct {
 	descriptionInput textarea.Model
 	formTargetList   Tab
 	editingIndex     int
+
+	// Filter state
+	filterInput textinput.Model
+	filtering   bool
+	filter      *query.Query
+	filterErr   error
+
+	// Reload state
+	watch       tea.Cmd
+	stored      merge.Lists
+	pending     merge.Lists
+	notice      string
+	noticeWorry bool
+	noticeID    int
+
+	// Rollover state
+	rolloverSave tea.Cmd
+	rolloverDue  bool
+}
+
+// Option configures optional behaviour of the TUI model.
+type Option func(*Model)
+
+// WithCalendar sets the calendar used to compute due dates and overdue labels.
+func WithCalendar(cal calendar.Calendar) Option {
+	return func(m *Model) {
+		m.calendar = cal
+	}
+}
+
+// WithClock sets the clock used to timestamp changes and evaluate due dates.
+func WithClock(c clock.Clock) Option {
+	return func(m *Model) {
+		m.clock = c
+	}
+}
+
+// WithStore names the store holding the lists, which is shown in the header.
+func WithStore(name string) Option {
+	return func(m *Model) {
+		m.store = name
+	}
 }
 
 // New creates a new TUI model with the provided todo lists and theme.
-func New(th theme.Theme, todayList, tomorrowList, todoList *model.TodoList) Model {
+func New(th theme.Theme, todayList, tomorrowList, todoList *model.TodoList, opts ...Option) Model {
 	ti := textinput.New()
 	ti.Placeholder = "Todo title"
-	ti.CharLimit = 100
+	ti.CharLimit = model.TitleCharLimit
 	ti.Width = 50
 
 	ta := textarea.New()
@@ -167,11 +222,17 @@ func New(th theme.Theme, todayList, tomorrowList, todoList *model.TodoList) Mode
 	ta.SetWidth(50)
 	ta.SetHeight(3)
 
-	return Model{
+	fi := textinput.New()
+	fi.Placeholder = "due < +3d and not done and tag:work"
+	fi.Prompt = "/ "
+	fi.Width = 50
+
+	m := Model{
 		keys:             DefaultKeyMap(),
 		activeTab:        TabToday,
 		cursor:           0,
 		theme:            th,
+		clock:            clock.System(),
 		todayList:        todayList,
 		tomorrowList:     tomorrowList,
 		todoList:         todoList,
@@ -179,12 +240,26 @@ func New(th theme.Theme, todayList, tomorrowList, todoList *model.TodoList) Mode
 		formField:        FormFieldTitle,
 		titleInput:       ti,
 		descriptionInput: ta,
+		filterInput:      fi,
+	}
+
+	for _, opt := range opts {
+		opt(&m)
 	}
+
+	m.today = m.calendar.Today(m.clock.Now())
+	m.stored = cloneLists(merge.Lists{
+		list.TodayID:    todayList,
+		list.TomorrowID: tomorrowList,
+		list.TodosID:    todoList,
+	})
+
+	return m
 }
 
 // Init initialises the model.
 func (m *Model) Init() tea.Cmd {
-	return nil
+	return tea.Batch(m.watch, m.scheduleRollover())
 }
 
 // Update handles messages and updates the model.
@@ -192,16 +267,30 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
+	switch msg := msg.(type) {
+	case ListsChangedMsg:
+		return m, m.listsChanged(msg)
+	case rolloverMsg:
+		return m, m.rollover()
+	case RolloverMsg:
+		return m, m.rolledOver(msg)
+	case clearNoticeMsg:
+		if msg.id == m.noticeID {
+			m.notice = ""
+		}
+		return m, nil
+	}
+
 	if m.formMode == FormModeAdd || m.formMode == FormModeEdit {
 		switch msg := msg.(type) {
 		case tea.KeyMsg:
 			switch msg.String() {
 			case "esc":
 				m.closeForm()
-				return m, nil
+				return m, m.applyPending()
 			case "ctrl+s":
 				m.submitForm()
-				return m, nil
+				return m, m.applyPending()
 			case "tab", "down":
 				cmd = m.nextFormField()
 				cmds = append(cmds, cmd)
@@ -238,9 +327,28 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(cmds...)
 	}
 
+	if m.filtering {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch msg.String() {
+			case "esc":
+				m.closeFilter()
+				return m, nil
+			case "enter":
+				m.applyFilter()
+				return m, nil
+			}
+		}
+
+		m.filterInput, cmd = m.filterInput.Update(msg)
+		return m, cmd
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch {
+		case m.filter != nil && msg.String() == "esc":
+			// Esc clears an active filter before it cancels.
+			m.clearFilter()
 		case key.Matches(msg, m.keys.Quit):
 			m.exited = true
 			return m, tea.Quit
@@ -253,6 +361,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, m.keys.Edit):
 			cmd = m.openEditForm()
 			return m, cmd
+		case key.Matches(msg, m.keys.Filter):
+			cmd = m.openFilter()
+			return m, cmd
 		case key.Matches(msg, m.keys.Left), key.Matches(msg, m.keys.ShiftTab):
 			// Only allow tab navigation if there are todos.
 			if m.hasAnyTodos() {
@@ -297,13 +408,29 @@ func (m *Model) View() string {
 
 	var b strings.Builder
 
+	if m.store != "" {
+		b.WriteString(m.theme.HelpStyle().Render(m.store))
+		b.WriteString("\n\n")
+	}
+
 	if m.hasAnyTodos() {
 		b.WriteString(m.renderTabs())
 		b.WriteString("\n\n")
 	}
 
+	if filter := m.renderFilter(); filter != "" {
+		b.WriteString(filter)
+		b.WriteString("\n\n")
+	}
+
 	b.WriteString(m.renderList())
 	b.WriteString("\n\n")
+
+	if notice := m.renderNotice(); notice != "" {
+		b.WriteString(notice)
+		b.WriteString("\n\n")
+	}
+
 	b.WriteString(m.renderHelp())
 
 	return m.theme.ContainerStyle().Render(b.String())
@@ -338,9 +465,15 @@ func (m *Model) renderList() string {
 		return "No todos yet."
 	}
 
-	now := time.Now()
+	visible := m.visibleIndices()
+	if len(visible) == 0 {
+		return "No todos match the filter."
+	}
+
+	now := m.clock.Now()
 	var items []string
-	for i, todo := range l.Todos {
+	for i, index := range visible {
+		todo := l.Todos[index]
 		var checkbox string
 		if todo.Completed {
 			greenCheck := m.theme.SuccessStyle().Render("✓")
@@ -382,7 +515,7 @@ func (m *Model) renderList() string {
 			titleStyle.Render(todo.Title),
 		)
 
-		if todo.IsOverdue(now) {
+		if todo.IsOverdue(m.calendar, now) {
 			overdueLabel := m.theme.WorryStyle().Render("! Overdue")
 			item += " " + overdueLabel
 		}
@@ -403,18 +536,22 @@ func (m *Model) renderHelp() string {
 
 	helpItems = append(helpItems, "A to add")
 
-	l := m.getCurrentList()
-	if l != nil && len(l.Todos) > 0 {
+	if len(m.visibleIndices()) > 0 {
 		helpItems = append(helpItems, "E to edit")
 		helpItems = append(helpItems, "Enter to select")
 	}
 
 	if m.hasAnyTodos() {
+		helpItems = append(helpItems, "/ to filter")
 		helpItems = append(helpItems, "Tab/Arrow keys to navigate")
 		helpItems = append(helpItems, "Ctrl+S to submit")
 	}
 
-	helpItems = append(helpItems, "Esc to cancel")
+	if m.filter != nil {
+		helpItems = append(helpItems, "Esc to clear filter")
+	} else {
+		helpItems = append(helpItems, "Esc to cancel")
+	}
 
 	return m.theme.HelpStyle().Render(strings.Join(helpItems, " · "))
 }
@@ -449,12 +586,49 @@ func (m *Model) cursorUp() {
 
 // cursorDown moves the cursor down.
 func (m *Model) cursorDown() {
-	l := m.getCurrentList()
-	if l != nil && m.cursor < len(l.Todos)-1 {
+	if m.cursor < len(m.visibleIndices())-1 {
 		m.cursor++
 	}
 }
 
+// clampCursor keeps the cursor on a visible todo after todos are hidden.
+func (m *Model) clampCursor() {
+	if last := len(m.visibleIndices()) - 1; m.cursor > last {
+		m.cursor = max(last, 0)
+	}
+}
+
+// visibleIndices returns the indices of the todos in the current list that
+// match the filter, in order.
+func (m *Model) visibleIndices() []int {
+	l := m.getCurrentList()
+	if l == nil {
+		return nil
+	}
+
+	id := listIDForTab(m.activeTab)
+
+	indices := make([]int, 0, len(l.Todos))
+	for i, todo := range l.Todos {
+		if m.filter.Match(todo, id) {
+			indices = append(indices, i)
+		}
+	}
+
+	return indices
+}
+
+// currentIndex returns the index in the current list of the todo under the
+// cursor, or -1 if no todo is shown.
+func (m *Model) currentIndex() int {
+	visible := m.visibleIndices()
+	if m.cursor < 0 || m.cursor >= len(visible) {
+		return -1
+	}
+
+	return visible[m.cursor]
+}
+
 // nextTab moves to the next tab.
 func (m *Model) nextTab() {
 	m.activeTab = (m.activeTab + 1) % TabCount
@@ -468,8 +642,9 @@ func (m *Model) previousTab() {
 // toggleCurrent toggles the completion status of the current todo.
 func (m *Model) toggleCurrent() {
 	l := m.getCurrentList()
-	if l != nil && m.cursor < len(l.Todos) {
-		l.Todos[m.cursor].ToggleCompleted()
+	if i := m.currentIndex(); l != nil && i >= 0 {
+		l.Todos[i].ToggleCompleted(m.clock.Now())
+		m.clampCursor()
 	}
 }
 
@@ -529,19 +704,16 @@ func (m *Model) openEditForm() tea.Cmd {
 		return nil
 	}
 
-	if len(l.Todos) == 0 {
+	index := m.currentIndex()
+	if index < 0 {
 		return nil
 	}
 
-	if m.cursor < 0 || m.cursor >= len(l.Todos) {
-		return nil
-	}
-
-	todo := l.Todos[m.cursor]
+	todo := l.Todos[index]
 	m.formMode = FormModeEdit
 	m.formField = FormFieldTitle
 	m.formTargetList = m.activeTab
-	m.editingIndex = m.cursor
+	m.editingIndex = index
 
 	m.titleInput.SetValue(todo.Title)
 	m.descriptionInput.SetValue(todo.Description)
@@ -584,15 +756,13 @@ func (m *Model) submitForm() {
 					targetList.Todos = append(targetList.Todos, todo)
 				}
 
-				if m.cursor >= len(currentList.Todos) && m.cursor > 0 {
-					m.cursor--
-				}
 			} else {
 				currentList.Todos[m.editingIndex] = todo
 			}
+			m.clampCursor()
 		}
 	} else {
-		newTodo := model.NewTodo(title, description, m.dueDateForTab(m.formTargetList))
+		newTodo := model.NewTodo(title, description, m.dueDateForTab(m.formTargetList), m.clock.Now())
 		targetList := m.getListByTab(m.formTargetList)
 		if targetList != nil {
 			targetList.Todos = append(targetList.Todos, newTodo)
@@ -602,6 +772,68 @@ func (m *Model) submitForm() {
 	m.closeForm()
 }
 
+// openFilter opens the filter input, starting from the active filter.
+func (m *Model) openFilter() tea.Cmd {
+	m.filtering = true
+	m.filterErr = nil
+	m.filterInput.SetValue(m.filter.String())
+	m.filterInput.CursorEnd()
+
+	return m.filterInput.Focus()
+}
+
+// closeFilter closes the filter input, keeping the active filter.
+func (m *Model) closeFilter() {
+	m.filtering = false
+	m.filterErr = nil
+	m.filterInput.Blur()
+}
+
+// applyFilter filters the lists by the query in the filter input. Queries
+// that cannot be parsed leave the input open with the error shown.
+func (m *Model) applyFilter() {
+	q, err := query.Parse(m.filterInput.Value(), m.calendar, m.clock.Now())
+	if err != nil {
+		m.filterErr = err
+		return
+	}
+
+	m.filter = q
+	if q.Empty() {
+		m.filter = nil
+	}
+
+	m.cursor = 0
+	m.closeFilter()
+}
+
+// clearFilter removes the active filter.
+func (m *Model) clearFilter() {
+	m.filter = nil
+	m.clampCursor()
+}
+
+// Filter returns the active filter, or nil if every todo is shown.
+func (m *Model) Filter() *query.Query {
+	return m.filter
+}
+
+// renderFilter renders the filter input or the active filter.
+func (m *Model) renderFilter() string {
+	switch {
+	case m.filtering:
+		view := m.filterInput.View()
+		if m.filterErr != nil {
+			view += "\n" + m.theme.WorryStyle().Render(m.filterErr.Error())
+		}
+		return view
+	case m.filter != nil:
+		return m.theme.HelpStyle().Render("Filter: " + m.filter.String())
+	default:
+		return ""
+	}
+}
+
 // nextFormField moves to the next form field.
 func (m *Model) nextFormField() tea.Cmd {
 	m.formField = (m.formField + 1) % formFieldCount
@@ -656,15 +888,27 @@ func (m *Model) getListByTab(tab Tab) *model.TodoList {
 	}
 }
 
+// listIDForTab returns the ID of the list shown on the given tab.
+func listIDForTab(tab Tab) list.ID {
+	switch tab {
+	case TabTomorrow:
+		return list.TomorrowID
+	case TabTodo:
+		return list.TodosID
+	default:
+		return list.TodayID
+	}
+}
+
 func (m *Model) dueDateForTab(tab Tab) *time.Time {
-	now := time.Now()
+	now := m.clock.Now()
 	switch tab {
 	case TabToday:
-		return list.DefaultDueDate(list.TodayID, now)
+		return list.DefaultDueDate(m.calendar, list.TodayID, now)
 	case TabTomorrow:
-		return list.DefaultDueDate(list.TomorrowID, now)
+		return list.DefaultDueDate(m.calendar, list.TomorrowID, now)
 	case TabTodo:
-		return list.DefaultDueDate(list.TodosID, now)
+		return list.DefaultDueDate(m.calendar, list.TodosID, now)
 	default:
 		return nil
 	}
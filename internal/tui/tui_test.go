@@ -10,6 +10,9 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/unfunco/t/internal/calendar"
+	"github.com/unfunco/t/internal/clock"
+	"github.com/unfunco/t/internal/list"
 	"github.com/unfunco/t/internal/model"
 	"github.com/unfunco/t/internal/theme"
 )
@@ -252,6 +255,64 @@ func TestEditFormMoveKeepsCorrectTodo(t *testing.T) {
 	}
 }
 
+func TestFilterShowsMatchingTodos(t *testing.T) {
+	m := newTestModel()
+	ptr := &m
+
+	typeKeys := func(text string) {
+		for _, r := range text {
+			updated, _ := ptr.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			ptr = updated.(*Model)
+		}
+	}
+
+	updated, _ := ptr.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	ptr = updated.(*Model)
+	if !ptr.filtering {
+		t.Fatal("Expected / to open the filter input")
+	}
+
+	typeKeys("due <")
+	updated, _ = ptr.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	ptr = updated.(*Model)
+	if !ptr.filtering || ptr.filterErr == nil {
+		t.Fatal("Expected an invalid query to keep the filter input open with an error")
+	}
+	if view := stripANSI(ptr.View()); !contains(view, "expected a value") {
+		t.Errorf("Expected the query error to be shown, got %q", view)
+	}
+
+	ptr.filterInput.SetValue("")
+	typeKeys("description~3 or title:\"todo 2\"")
+	updated, _ = ptr.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	ptr = updated.(*Model)
+	if ptr.filtering || ptr.Filter() == nil {
+		t.Fatal("Expected a valid query to be applied")
+	}
+
+	view := stripANSI(ptr.View())
+	if contains(view, "Test todo 1") || !contains(view, "Test todo 2") || !contains(view, "Test todo 3") {
+		t.Errorf("Expected only matching todos to be shown, got %q", view)
+	}
+
+	updated, _ = ptr.Update(tea.KeyMsg{Type: tea.KeyDown})
+	ptr = updated.(*Model)
+	updated, _ = ptr.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	ptr = updated.(*Model)
+	if !ptr.todayList.Todos[2].Completed || ptr.todayList.Todos[1].Completed {
+		t.Error("Expected the toggle to apply to the todo under the cursor")
+	}
+
+	updated, _ = ptr.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	ptr = updated.(*Model)
+	if ptr.Filter() != nil || ptr.exited {
+		t.Fatal("Expected Esc to clear the filter without exiting")
+	}
+	if view := stripANSI(ptr.View()); !contains(view, "Test todo 1") {
+		t.Errorf("Expected every todo to be shown after clearing the filter, got %q", view)
+	}
+}
+
 func TestView(t *testing.T) {
 	m := newTestModel()
 	view := m.View()
@@ -265,6 +326,58 @@ func TestView(t *testing.T) {
 	}
 }
 
+func TestViewShowsStore(t *testing.T) {
+	m := newTestModel()
+	if view := stripANSI(m.View()); contains(view, "~/code/t/.t") {
+		t.Errorf("Expected no store to be shown by default, got %q", view)
+	}
+
+	WithStore("~/code/t/.t")(&m)
+	if view := stripANSI(m.View()); !contains(view, "~/code/t/.t") {
+		t.Errorf("Expected the store to be shown in the header, got %q", view)
+	}
+}
+
+func TestRenderListUsesInjectedClock(t *testing.T) {
+	now := time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC)
+	cal, err := calendar.New(0, time.UTC)
+	if err != nil {
+		t.Fatalf("calendar.New() error = %v", err)
+	}
+
+	yesterday := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	todayList := &model.TodoList{
+		Name: "Today",
+		Todos: []model.Todo{
+			{ID: "late", Title: "Late", DueDate: &yesterday},
+		},
+	}
+
+	m := New(theme.Default(), todayList, &model.TodoList{}, &model.TodoList{},
+		WithCalendar(cal),
+		WithClock(clock.Fixed(now)),
+	)
+
+	if view := stripANSI(m.renderList()); !contains(view, "Overdue") {
+		t.Fatalf("expected overdue label at fixed time, got %q", view)
+	}
+
+	m.toggleCurrent()
+	completedAt := m.todayList.Todos[0].CompletedAt
+	if completedAt == nil || !completedAt.Equal(now) {
+		t.Fatalf("expected completion time %v, got %v", now, completedAt)
+	}
+
+	m.openForm()
+	m.titleInput.SetValue("Pinned")
+	m.submitForm()
+
+	added := m.todayList.Todos[len(m.todayList.Todos)-1]
+	if !added.CreatedAt.Equal(now) {
+		t.Fatalf("expected creation time %v, got %v", now, added.CreatedAt)
+	}
+}
+
 func TestRenderHelpShowsEditWhenTodosPresent(t *testing.T) {
 	m := newTestModel()
 	help := stripANSI(m.renderHelp())
@@ -304,3 +417,160 @@ func stripANSI(s string) string {
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && (s[:len(substr)] == substr || contains(s[1:], substr)))
 }
+
+// storedLists copies the lists shown by m, as if they had been saved.
+func storedLists(m *Model) map[list.ID]*model.TodoList {
+	return cloneLists(map[list.ID]*model.TodoList{
+		list.TodayID:    m.todayList,
+		list.TomorrowID: m.tomorrowList,
+		list.TodosID:    m.todoList,
+	})
+}
+
+func TestListsChangedKeepsLocalChanges(t *testing.T) {
+	m := newTestModel()
+	stored := storedLists(&m)
+
+	m.toggleCurrent()
+
+	added := newTestTodo("Added elsewhere", "")
+	stored[list.TodayID].Todos = append(stored[list.TodayID].Todos, added)
+	stored[list.TodayID].Todos[1].Title = "Renamed elsewhere"
+
+	updated, cmd := m.Update(ListsChangedMsg{Lists: stored})
+	ptr := updated.(*Model)
+	if cmd == nil {
+		t.Error("Expected a command to clear the notice")
+	}
+
+	today := ptr.todayList.Todos
+	if len(today) != 4 || today[3].Title != "Added elsewhere" {
+		t.Fatalf("Expected the todo added elsewhere to be merged, got %+v", today)
+	}
+	if !today[0].Completed {
+		t.Error("Expected the unsaved toggle to be kept")
+	}
+	if today[1].Title != "Renamed elsewhere" {
+		t.Errorf("Expected the rename made elsewhere to be merged, got %q", today[1].Title)
+	}
+	if view := stripANSI(ptr.View()); !contains(view, "Merged changes saved elsewhere") {
+		t.Errorf("Expected a notice in the status line, got %q", view)
+	}
+
+	updated, _ = ptr.Update(clearNoticeMsg{id: ptr.noticeID})
+	ptr = updated.(*Model)
+	if view := stripANSI(ptr.View()); contains(view, "Merged changes saved elsewhere") {
+		t.Errorf("Expected the notice to be cleared, got %q", view)
+	}
+}
+
+func TestListsChangedWaitsForForm(t *testing.T) {
+	m := newTestModel()
+	stored := storedLists(&m)
+	stored[list.TomorrowID].Todos = append(stored[list.TomorrowID].Todos, newTestTodo("Added elsewhere", ""))
+
+	m.openEditForm()
+	updated, _ := m.Update(ListsChangedMsg{Lists: stored})
+	ptr := updated.(*Model)
+	if len(ptr.tomorrowList.Todos) != 1 {
+		t.Fatal("Expected changes to wait while the form is open")
+	}
+
+	updated, _ = ptr.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	ptr = updated.(*Model)
+	if len(ptr.tomorrowList.Todos) != 2 {
+		t.Errorf("Expected changes to be merged once the form closed, got %+v", ptr.tomorrowList.Todos)
+	}
+}
+
+func TestListsChangedIgnoresOwnLists(t *testing.T) {
+	m := newTestModel()
+
+	updated, _ := m.Update(ListsChangedMsg{Lists: storedLists(&m)})
+	if ptr := updated.(*Model); ptr.notice != "" {
+		t.Errorf("Expected no notice when nothing changed, got %q", ptr.notice)
+	}
+}
+
+func TestRolloverMovesTomorrowTodos(t *testing.T) {
+	now := time.Date(2025, time.January, 2, 23, 0, 0, 0, time.UTC)
+	cal, err := calendar.New(0, time.UTC)
+	if err != nil {
+		t.Fatalf("calendar.New() error = %v", err)
+	}
+
+	due := time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC)
+	todo := newTestTodo("Due tomorrow", "")
+	todo.DueDate = &due
+
+	m := New(
+		theme.Default(),
+		&model.TodoList{Name: "Today"},
+		&model.TodoList{Name: "Tomorrow", Todos: []model.Todo{todo}},
+		&model.TodoList{Name: "Todos"},
+		WithCalendar(cal),
+		WithClock(clock.Func(func() time.Time { return now })),
+	)
+
+	updated, _ := m.Update(rolloverMsg{})
+	ptr := updated.(*Model)
+	if len(ptr.tomorrowList.Todos) != 1 {
+		t.Fatal("Expected todos to stay put before the day changes")
+	}
+
+	now = now.Add(2 * time.Hour)
+	updated, cmd := ptr.Update(rolloverMsg{})
+	ptr = updated.(*Model)
+	if cmd == nil {
+		t.Error("Expected the next rollover to be scheduled")
+	}
+	if len(ptr.tomorrowList.Todos) != 0 || len(ptr.todayList.Todos) != 1 {
+		t.Fatalf("Expected the todo to move to Today, got today=%+v tomorrow=%+v", ptr.todayList.Todos, ptr.tomorrowList.Todos)
+	}
+}
+
+func TestRolloverMergesSavedAutomations(t *testing.T) {
+	now := time.Date(2025, time.January, 3, 1, 0, 0, 0, time.UTC)
+	cal, err := calendar.New(0, time.UTC)
+	if err != nil {
+		t.Fatalf("calendar.New() error = %v", err)
+	}
+
+	due := time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC)
+	saved := newTestTodo("Saved", "")
+	saved.DueDate = &due
+
+	m := New(
+		theme.Default(),
+		&model.TodoList{Name: "Today"},
+		&model.TodoList{Name: "Tomorrow", Todos: []model.Todo{saved}},
+		&model.TodoList{Name: "Todos"},
+		WithCalendar(cal),
+		WithClock(clock.Func(func() time.Time { return now })),
+		WithRollover(func() tea.Msg { return nil }),
+	)
+	m.today = time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)
+
+	unsaved := newTestTodo("Unsaved", "")
+	unsaved.DueDate = &due
+	m.tomorrowList.Todos = append(m.tomorrowList.Todos, unsaved)
+
+	updated, cmd := m.Update(rolloverMsg{})
+	ptr := updated.(*Model)
+	if cmd == nil {
+		t.Fatal("Expected the automations to be saved")
+	}
+	if len(ptr.tomorrowList.Todos) != 2 {
+		t.Fatal("Expected todos to stay put until the automations are saved")
+	}
+
+	stored := cloneLists(ptr.stored)
+	stored[list.TodayID].Todos = []model.Todo{saved}
+	stored[list.TomorrowID].Todos = nil
+
+	updated, _ = ptr.Update(RolloverMsg{Lists: stored})
+	ptr = updated.(*Model)
+	if len(ptr.tomorrowList.Todos) != 0 || len(ptr.todayList.Todos) != 2 {
+		t.Fatalf("Expected both todos to move to Today, got today=%+v tomorrow=%+v", ptr.todayList.Todos, ptr.tomorrowList.Todos)
+	}
+}
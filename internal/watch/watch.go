@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+// Package watch reports changes made to the list files in a data directory,
+// such as todos added by a script or another terminal.
+package watch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/unfunco/t/internal/list"
+)
+
+// debounce is how long to wait for further changes before reporting them, as
+// a single save changes several files, each with more than one operation.
+const debounce = 100 * time.Millisecond
+
+// Watcher reports changes to the list files in a data directory.
+type Watcher struct {
+	fs      *fsnotify.Watcher
+	changes chan struct{}
+}
+
+// New starts watching the list files in dataDir, creating it if needed.
+func New(dataDir string) (*Watcher, error) {
+	if err := os.MkdirAll(dataDir, 0o700); err != nil {
+		return nil, fmt.Errorf("create data directory: %w", err)
+	}
+
+	fs, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("create watcher: %w", err)
+	}
+
+	// Lists are replaced by renaming a new file over them, which only the
+	// directory sees, so the directory is watched rather than the files.
+	if err := fs.Add(dataDir); err != nil {
+		_ = fs.Close()
+		return nil, fmt.Errorf("watch %s: %w", dataDir, err)
+	}
+
+	w := &Watcher{fs: fs, changes: make(chan struct{}, 1)}
+	go w.run()
+
+	return w, nil
+}
+
+// Changes returns a channel that receives a value after the list files change,
+// once they have settled. Changes made before the value is received are
+// reported together. The channel is closed when the watcher is.
+func (w *Watcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+// Close stops watching.
+func (w *Watcher) Close() error {
+	return w.fs.Close()
+}
+
+func (w *Watcher) run() {
+	defer close(w.changes)
+
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			if isListFile(event.Name) && event.Op != fsnotify.Chmod {
+				timer.Reset(debounce)
+			}
+		case _, ok := <-w.fs.Errors:
+			// Errors such as an overflowing event queue may lose changes,
+			// which are picked up by the next change, so are not reported.
+			if !ok {
+				return
+			}
+		case <-timer.C:
+			select {
+			case w.changes <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// isListFile reports whether path is one of the default list files.
+func isListFile(path string) bool {
+	name := filepath.Base(path)
+	for _, def := range list.Default() {
+		if name == def.Filename {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2025 Daniel Morris <daniel@honestempire.com>
+// SPDX-License-Identifier: MIT
+
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestChanges(t *testing.T) {
+	dataDir := filepath.Join(t.TempDir(), "t")
+
+	w, err := New(dataDir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dataDir, "notes.txt"), []byte("notes"), 0o600); err != nil {
+		t.Fatalf("write notes: %v", err)
+	}
+	select {
+	case <-w.Changes():
+		t.Fatal("Expected changes to other files to be ignored")
+	case <-time.After(3 * debounce):
+	}
+
+	tmp := filepath.Join(dataDir, "today.json.tmp-1")
+	if err := os.WriteFile(tmp, []byte(`{"todos":[]}`), 0o600); err != nil {
+		t.Fatalf("write list: %v", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dataDir, "today.json")); err != nil {
+		t.Fatalf("rename list: %v", err)
+	}
+	select {
+	case <-w.Changes():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a change to be reported after a list was saved")
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, ok := <-w.Changes(); ok {
+		t.Error("Expected the changes channel to be closed")
+	}
+}
@@ -8,8 +8,13 @@ import (
 
 	"charm.land/lipgloss/v2"
 	"github.com/charmbracelet/fang"
+	"github.com/unfunco/t/internal/backup"
+	"github.com/unfunco/t/internal/calendar"
 	"github.com/unfunco/t/internal/cmd"
 	"github.com/unfunco/t/internal/config"
+	"github.com/unfunco/t/internal/crypt"
+	"github.com/unfunco/t/internal/gitstore"
+	"github.com/unfunco/t/internal/hook"
 	"github.com/unfunco/t/internal/theme"
 )
 
@@ -20,7 +25,11 @@ func main() {
 	}
 
 	cfg := config.Config{
-		Theme: theme.DefaultConfig(),
+		Theme:      theme.DefaultConfig(),
+		Backup:     backup.DefaultConfig(),
+		Git:        gitstore.DefaultConfig(),
+		Encryption: crypt.DefaultConfig(),
+		Hooks:      hook.DefaultConfig(),
 	}
 	if loadedCfg, err := config.Load(); err != nil {
 		logConfigWarning(configPath, err)
@@ -32,13 +41,49 @@ func main() {
 
 	th, err := theme.FromConfig(cfg.Theme, hasDarkBackground)
 	if err != nil {
-		logThemeWarning(configPath, err)
+		logWarning(configPath, "invalid theme configuration", err, "using default theme")
 		th = theme.MustFromConfig(theme.DefaultConfig(), hasDarkBackground)
 	}
 
+	cal, err := calendar.FromConfig(cfg.DayStartsAt, cfg.TimeZone)
+	if err != nil {
+		logWarning(configPath, "invalid calendar configuration", err, "using midnight in the local time zone")
+		cal = calendar.Calendar{}
+	}
+
+	if err := cfg.Backup.Validate(); err != nil {
+		logWarning(configPath, "invalid backup configuration", err, "using daily backups")
+		cfg.Backup = backup.DefaultConfig()
+	}
+
+	if err := cfg.Git.Validate(); err != nil {
+		logWarning(configPath, "invalid git configuration", err, "using file storage")
+		cfg.Git = gitstore.DefaultConfig()
+	}
+
+	if err := cfg.Encryption.Validate(); err != nil {
+		logWarning(configPath, "invalid encryption configuration", err, "using the default key file")
+		cfg.Encryption = crypt.DefaultConfig()
+	}
+
+	if err := cfg.Hooks.Validate(); err != nil {
+		logWarning(configPath, "invalid hooks configuration", err, "running no hooks")
+		cfg.Hooks = hook.DefaultConfig()
+	}
+
 	if err := fang.Execute(
 		context.Background(),
-		cmd.NewDefaultTCommandWithTheme(th),
+		cmd.NewDefaultTCommandWithOptions(cmd.Options{
+			Theme:          th,
+			Calendar:       cal,
+			Backup:         cfg.Backup,
+			Git:            cfg.Git,
+			Encryption:     cfg.Encryption,
+			Profile:        cfg.Profile,
+			Profiles:       cfg.Profiles,
+			Hooks:          cfg.Hooks,
+			DarkBackground: hasDarkBackground,
+		}),
 		fang.WithColorSchemeFunc(func(c lipgloss.LightDarkFunc) fang.ColorScheme {
 			return customColorScheme(c, th)
 		}),
@@ -57,13 +102,14 @@ func logConfigWarning(configPath string, err error) {
 	_, _ = fmt.Fprintf(os.Stderr, "warning: failed to load config at %s: %v; using defaults\n", configPath, err)
 }
 
-func logThemeWarning(configPath string, err error) {
-	if configPath == "" {
-		_, _ = fmt.Fprintf(os.Stderr, "warning: invalid theme configuration: %v; using default theme\n", err)
-		return
+// logWarning reports an invalid setting in the configuration at configPath,
+// saying what went wrong and what is used instead.
+func logWarning(configPath, what string, err error, fallback string) {
+	if configPath != "" {
+		what += " in " + configPath
 	}
 
-	_, _ = fmt.Fprintf(os.Stderr, "warning: invalid theme configuration in %s: %v; using default theme\n", configPath, err)
+	_, _ = fmt.Fprintf(os.Stderr, "warning: %s: %v; %s\n", what, err, fallback)
 }
 
 func customColorScheme(c lipgloss.LightDarkFunc, th theme.Theme) fang.ColorScheme {